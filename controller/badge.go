@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/router/middleware/context"
 	"github.com/CiscoCloud/drone/store"
 )
 
@@ -19,6 +20,7 @@ func GetBadge(c *gin.Context) {
 	repo, err := store.GetRepoOwnerName(c,
 		c.Param("owner"),
 		c.Param("name"),
+		context.RemoteID(c),
 	)
 	if err != nil {
 		c.AbortWithStatus(404)
@@ -61,6 +63,7 @@ func GetCC(c *gin.Context) {
 	repo, err := store.GetRepoOwnerName(c,
 		c.Param("owner"),
 		c.Param("name"),
+		context.RemoteID(c),
 	)
 	if err != nil {
 		c.AbortWithStatus(404)
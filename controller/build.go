@@ -135,10 +135,16 @@ func DeleteBuild(c *gin.Context) {
 		c.AbortWithError(404, err)
 		return
 	}
-	node, err := store.GetNode(c, job.NodeID)
-	if err != nil {
-		c.AbortWithError(404, err)
-		return
+
+	// jobs executed by engines that do not use registered
+	// nodes are not assigned to a node.
+	node := new(model.Node)
+	if job.NodeID != 0 {
+		node, err = store.GetNode(c, job.NodeID)
+		if err != nil {
+			c.AbortWithError(404, err)
+			return
+		}
 	}
 	engine_.Cancel(build.ID, job.ID, node)
 }
@@ -146,8 +152,11 @@ func DeleteBuild(c *gin.Context) {
 func PostBuild(c *gin.Context) {
 
 	remote_ := remote.FromContext(c)
+	engine_ := context.Engine(c)
 	repo := session.Repo(c)
 	fork := c.DefaultQuery("fork", "false")
+	failed := c.DefaultQuery("failed", "false")
+	snapshot := c.DefaultQuery("snapshot", "false")
 
 	num, err := strconv.Atoi(c.Param("number"))
 	if err != nil {
@@ -179,12 +188,31 @@ func PostBuild(c *gin.Context) {
 		}
 	}
 
-	// fetch the .drone.yml file from the database
-	raw, sec, err := remote_.Script(user, repo, build)
+	// get the configuration snapshot of the build, which
+	// is missing for builds created before snapshots.
+	config, err := store.GetConfig(c, build)
 	if err != nil {
-		log.Errorf("failure to get .drone.yml for %s. %s", repo.FullName, err)
-		c.AbortWithError(404, err)
-		return
+		config = &model.Config{BuildID: build.ID}
+	}
+
+	// restarting from the snapshot uses the same .drone.yml
+	// file the build originally ran with. Otherwise fetch
+	// the .drone.yml file from the remote.
+	var raw, sec []byte
+	usesnap, _ := strconv.ParseBool(snapshot)
+	if usesnap {
+		if config.ID == 0 {
+			c.String(404, "Cannot re-start a build without a configuration snapshot")
+			return
+		}
+		raw, sec = []byte(config.Data), []byte(config.Secret)
+	} else {
+		raw, sec, err = remote_.Script(user, repo, build)
+		if err != nil {
+			log.Errorf("failure to get .drone.yml for %s. %s", repo.FullName, err)
+			c.AbortWithError(404, err)
+			return
+		}
 	}
 
 	key, _ := store.GetKey(c, repo)
@@ -208,9 +236,28 @@ func PostBuild(c *gin.Context) {
 		return
 	}
 
+	// must not restart a build while the server is shutting down
+	if engine_.Draining() {
+		c.String(503, "Server is shutting down")
+		return
+	}
+
+	// restarting only the failed jobs keeps the results
+	// and logs of the jobs that completed successfully.
+	partial, _ := strconv.ParseBool(failed)
+	if partial && !hasFailed(jobs) {
+		c.String(409, "Cannot re-start a build without failed jobs")
+		return
+	}
+
 	// forking the build creates a duplicate of the build
 	// and then executes. This retains prior build history.
-	if forkit, _ := strconv.ParseBool(fork); forkit {
+	forkit, _ := strconv.ParseBool(fork)
+	if forkit && partial {
+		c.String(400, "Cannot fork a build when re-starting failed jobs")
+		return
+	}
+	if forkit {
 		build.ID = 0
 		build.Number = 0
 		for _, job := range jobs {
@@ -222,6 +269,7 @@ func PostBuild(c *gin.Context) {
 			c.String(500, err.Error())
 			return
 		}
+		config.ID = 0
 	}
 
 	// todo move this to database tier
@@ -231,6 +279,9 @@ func PostBuild(c *gin.Context) {
 	build.Finished = 0
 	build.Enqueued = time.Now().UTC().Unix()
 	for _, job := range jobs {
+		if partial && !isFailed(job) {
+			continue
+		}
 		job.Status = model.StatusPending
 		job.Started = 0
 		job.Finished = 0
@@ -246,13 +297,19 @@ func PostBuild(c *gin.Context) {
 		return
 	}
 
+	// update the snapshot with the configuration the build is
+	// re-started with, unless the build is re-started from its
+	// own snapshot. A forked build copies the snapshot.
+	if !usesnap || config.ID == 0 {
+		saveConfig(c, repo, build, config, raw, sec)
+	}
+
 	c.JSON(202, build)
 
 	// get the previous build so taht we can send
 	// on status change notifications
 	last, _ := store.GetBuildLastBefore(c, repo, build.Branch, build.ID)
 
-	engine_ := context.Engine(c)
 	go engine_.Schedule(c.Copy(), &engine.Task{
 		User:      user,
 		Repo:      repo,
@@ -271,3 +328,53 @@ func PostBuild(c *gin.Context) {
 	})
 
 }
+
+// helper function returns true if the job did not complete
+// successfully, and is re-started when re-starting failed jobs.
+// Skipped jobs are re-started since the jobs they depend on
+// are re-started.
+func isFailed(job *model.Job) bool {
+	switch job.Status {
+	case model.StatusFailure, model.StatusError, model.StatusKilled, model.StatusSkipped:
+		return true
+	}
+	return false
+}
+
+// helper function returns true if any of the jobs
+// did not complete successfully.
+func hasFailed(jobs []*model.Job) bool {
+	for _, job := range jobs {
+		if isFailed(job) {
+			return true
+		}
+	}
+	return false
+}
+
+// helper function to save the configuration snapshot of the re-started
+// build, with the matrix axes calculated from the configuration. The
+// snapshot is not changed if the axes cannot be calculated.
+func saveConfig(c *gin.Context, repo *model.Repo, build *model.Build, config *model.Config, raw, sec []byte) {
+	axes, _, err := parseJobs(string(raw), context.Matrix(c))
+	if err != nil {
+		log.Errorf("failure to calculate configuration snapshot for %s/%d. %s", repo.FullName, build.Number, err)
+		return
+	}
+
+	config.BuildID = build.ID
+	config.Data = string(raw)
+	config.Secret = string(sec)
+	config.Axes = nil
+	for _, axis := range axes {
+		config.Axes = append(config.Axes, axis)
+	}
+	if config.ID == 0 {
+		err = store.CreateConfig(c, config)
+	} else {
+		err = store.UpdateConfig(c, config)
+	}
+	if err != nil {
+		log.Errorf("failure to save configuration snapshot for %s/%d. %s", repo.FullName, build.Number, err)
+	}
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/router/middleware/session"
+	"github.com/CiscoCloud/drone/shared/diff"
+	"github.com/CiscoCloud/drone/store"
+)
+
+// GetBuildConfig returns the configuration snapshot of the build,
+// which is the .drone.yml file and the calculated matrix axes.
+func GetBuildConfig(c *gin.Context) {
+	config, ok := getConfig(c, c.Param("number"))
+	if !ok {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, config)
+}
+
+// GetBuildConfigDiff returns a line by line diff of the configuration
+// snapshot of the build and the configuration snapshot of another
+// build of the repository.
+func GetBuildConfigDiff(c *gin.Context) {
+	from, ok := getConfig(c, c.Param("number"))
+	if !ok {
+		return
+	}
+	to, ok := getConfig(c, c.Param("other"))
+	if !ok {
+		return
+	}
+
+	out := struct {
+		Changed bool   `json:"changed"`
+		Config  string `json:"config"`
+		Axes    string `json:"axes"`
+	}{
+		Changed: from.Data != to.Data || axesText(from.Axes) != axesText(to.Axes),
+		Config:  diff.Lines(from.Data, to.Data),
+		Axes:    diff.Lines(axesText(from.Axes), axesText(to.Axes)),
+	}
+	c.IndentedJSON(http.StatusOK, &out)
+}
+
+// helper function to get the configuration snapshot of the
+// build number. It writes the error response and returns
+// false if the snapshot cannot be found.
+func getConfig(c *gin.Context, number string) (*model.Config, bool) {
+	repo := session.Repo(c)
+	num, err := strconv.Atoi(number)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return nil, false
+	}
+	build, err := store.GetBuildNumber(c, repo, num)
+	if err != nil {
+		c.AbortWithError(http.StatusNotFound, err)
+		return nil, false
+	}
+	config, err := store.GetConfig(c, build)
+	if err != nil {
+		c.String(http.StatusNotFound, "Build %d has no configuration snapshot", num)
+		return nil, false
+	}
+	return config, true
+}
+
+// helper function to format the matrix axes as text, one
+// axis per line, with the variables of each axis sorted.
+func axesText(axes []map[string]string) string {
+	var lines []string
+	for _, axis := range axes {
+		var envs []string
+		for k, v := range axis {
+			envs = append(envs, k+"="+v)
+		}
+		sort.Strings(envs)
+		lines = append(lines, strings.Join(envs, " "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,272 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/router/middleware/context"
+	"github.com/CiscoCloud/drone/router/middleware/session"
+	"github.com/CiscoCloud/drone/store"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// redacted replaces secrets in stored hook deliveries.
+const redacted = "[redacted]"
+
+// maxPayload is the maximum size of the stored hook payload.
+// Larger payloads are not stored, and cannot be replayed.
+const maxPayload = 1 << 20
+
+// secretHeaders are the request headers that contain secrets.
+var secretHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Gitlab-Token",
+}
+
+// secretKeys are the url parameters and payload fields that
+// contain secrets. Keys are matched case insensitively.
+var secretKeys = map[string]bool{
+	"access_token":  true,
+	"private_token": true,
+	"token":         true,
+	"secret":        true,
+	"password":      true,
+}
+
+// hookDelivery records the hook delivery while the
+// hook is processed.
+type hookDelivery struct {
+	*model.Delivery
+}
+
+// fail records the error that failed the hook.
+func (d *hookDelivery) fail(decision string, err error) {
+	d.Decision = decision
+	d.Error = err.Error()
+}
+
+// GetDeliveries returns the most recent hook deliveries for
+// the repository, or for all repositories if no repository
+// is in the request.
+func GetDeliveries(c *gin.Context) {
+	var deliveries []*model.Delivery
+	var err error
+	if repo := session.Repo(c); repo != nil {
+		deliveries, err = store.GetDeliveryRepoList(c, repo)
+	} else {
+		deliveries, err = store.GetDeliveryList(c)
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, deliveries)
+}
+
+// GetDelivery returns the hook delivery, including
+// the redacted header and payload.
+func GetDelivery(c *gin.Context) {
+	delivery, ok := getDelivery(c)
+	if !ok {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, delivery)
+}
+
+// PostDelivery re-delivers the stored hook through the same
+// processing path as the original hook. The hook token was
+// redacted when the hook was stored, so only hooks that passed
+// authentication when delivered can be replayed.
+func PostDelivery(c *gin.Context) {
+	delivery, ok := getDelivery(c)
+	if !ok {
+		return
+	}
+	if delivery.RepoID == 0 {
+		c.String(http.StatusForbidden, "Hook delivery %d was not authorized and cannot be replayed.", delivery.ID)
+		return
+	}
+
+	req, err := http.NewRequest(delivery.Method, delivery.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	for k, v := range delivery.Header {
+		req.Header[k] = v
+	}
+	req.Host = c.Request.Host
+	req.RemoteAddr = c.Request.RemoteAddr
+
+	// the hook is parsed by the remote that
+	// received the original hook.
+	if !context.SelectRemote(c, delivery.Remote) {
+		c.String(http.StatusBadRequest, "Unknown remote %s.", delivery.Remote)
+		return
+	}
+
+	log.Infof("replaying hook delivery %d", delivery.ID)
+	c.Request = req
+	c.Set("replay", delivery)
+	PostHook(c)
+}
+
+// helper function to get the hook delivery in the request. It
+// writes the error response and returns false if the delivery
+// cannot be found, or belongs to another repository.
+func getDelivery(c *gin.Context) (*model.Delivery, bool) {
+	id, err := strconv.ParseInt(c.Param("delivery"), 10, 64)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return nil, false
+	}
+	delivery, err := store.GetDelivery(c, id)
+	if err != nil {
+		c.AbortWithError(http.StatusNotFound, err)
+		return nil, false
+	}
+	if repo := session.Repo(c); repo != nil && repo.ID != delivery.RepoID {
+		c.AbortWithStatus(http.StatusNotFound)
+		return nil, false
+	}
+	return delivery, true
+}
+
+// helper function returns true if the hook in the
+// request is a replay of a stored hook delivery.
+func isReplay(c *gin.Context) bool {
+	_, ok := c.Get("replay")
+	return ok
+}
+
+// helper function returns the stored hook delivery
+// replayed by the request.
+func replayOf(c *gin.Context) *model.Delivery {
+	v, _ := c.Get("replay")
+	return v.(*model.Delivery)
+}
+
+// helper function to start recording the hook delivery in the
+// request. The request body is read and replaced so that the
+// remote can still parse the hook. Payloads larger than the
+// maximum size are not recorded.
+func newDelivery(c *gin.Context) *hookDelivery {
+	body, _ := ioutil.ReadAll(io.LimitReader(c.Request.Body, maxPayload+1))
+	c.Request.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	if len(body) > maxPayload {
+		body = nil
+	}
+
+	delivery := &model.Delivery{
+		Remote:  context.RemoteID(c),
+		Method:  c.Request.Method,
+		URL:     c.Request.URL.RequestURI(),
+		Header:  map[string][]string{},
+		Payload: string(body),
+	}
+	for k, v := range c.Request.Header {
+		delivery.Header[k] = v
+	}
+	if isReplay(c) {
+		delivery.Replay = replayOf(c).ID
+	}
+	return &hookDelivery{delivery}
+}
+
+// helper function to store the hook delivery with the secrets
+// redacted, and remove the deliveries older than the retention.
+func saveDelivery(c *gin.Context, delivery *hookDelivery) {
+	retention := context.HookRetention(c)
+	if retention == 0 {
+		return
+	}
+
+	redactDelivery(delivery.Delivery)
+	delivery.Status = c.Writer.Status()
+	delivery.Created = time.Now().UTC().Unix()
+	err := store.CreateDelivery(c, delivery.Delivery)
+	if err != nil {
+		log.Errorf("failure to save hook delivery. %s", err)
+	}
+
+	before := time.Now().UTC().Add(-retention).Unix()
+	err = store.DeleteDeliveryBefore(c, before)
+	if err != nil {
+		log.Errorf("failure to remove expired hook deliveries. %s", err)
+	}
+}
+
+// helper function to redact the secrets from the header,
+// url parameters and payload of the hook delivery.
+func redactDelivery(delivery *model.Delivery) {
+	for _, key := range secretHeaders {
+		key = http.CanonicalHeaderKey(key)
+		if _, ok := delivery.Header[key]; ok {
+			delivery.Header[key] = []string{redacted}
+		}
+	}
+
+	if u, err := url.Parse(delivery.URL); err == nil {
+		u.RawQuery = redactValues(u.RawQuery)
+		delivery.URL = u.String()
+	}
+
+	// payloads are either json or form encoded.
+	var payload interface{}
+	if err := json.Unmarshal([]byte(delivery.Payload), &payload); err == nil {
+		out, err := json.Marshal(redactJSON(payload))
+		if err == nil {
+			delivery.Payload = string(out)
+		}
+	} else if strings.HasPrefix(http.Header(delivery.Header).Get("Content-Type"), "application/x-www-form-urlencoded") {
+		delivery.Payload = redactValues(delivery.Payload)
+	}
+}
+
+// helper function to redact the secrets from
+// url encoded parameters.
+func redactValues(query string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return query
+	}
+	for key := range values {
+		if secretKeys[strings.ToLower(key)] {
+			values.Set(key, redacted)
+		}
+	}
+	return values.Encode()
+}
+
+// helper function to redact the secrets from
+// the decoded json payload.
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if secretKeys[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(val)
+			}
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redactJSON(val)
+		}
+	}
+	return v
+}
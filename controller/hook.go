@@ -3,12 +3,9 @@ package controller
 import (
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"os"
 	"path/filepath"
 	"strings"
 
-	log "github.com/Sirupsen/logrus"
-	"github.com/CiscoCloud/drone/engine"
 	"github.com/CiscoCloud/drone/model"
 	"github.com/CiscoCloud/drone/remote"
 	"github.com/CiscoCloud/drone/router/middleware/context"
@@ -17,72 +14,132 @@ import (
 	"github.com/CiscoCloud/drone/store"
 	"github.com/CiscoCloud/drone/yaml"
 	"github.com/CiscoCloud/drone/yaml/matrix"
+	log "github.com/Sirupsen/logrus"
 )
 
 func PostHook(c *gin.Context) {
 	remote_ := remote.FromContext(c)
+	engine_ := context.Engine(c)
+	var err error
+
+	// record the hook delivery and the decision taken
+	// once the hook is processed.
+	delivery := newDelivery(c)
+	defer saveDelivery(c, delivery)
+
+	// the server is shutting down and is draining
+	// running builds. The remote should re-deliver.
+	if engine_.Draining() {
+		log.Infof("ignoring hook. server is shutting down.")
+		delivery.Decision = "ignored. server is shutting down"
+		c.String(503, "Server is shutting down")
+		return
+	}
+
+	// verify the hook signature before the hook is parsed. Replayed
+	// hooks were verified when the hook was delivered.
+	var verified *model.Repo
+	if !isReplay(c) {
+		verified, err = verifyHook(c)
+		if err != nil {
+			log.Errorf("failure to verify hook signature. %s", err)
+			delivery.fail("failure to verify hook signature", err)
+			c.AbortWithError(403, err)
+			return
+		}
+	}
 
 	tmprepo, build, err := remote_.Hook(c.Request)
 	if err != nil {
 		log.Errorf("failure to parse hook. %s", err)
+		delivery.fail("failure to parse hook", err)
 		c.AbortWithError(400, err)
 		return
 	}
 	if build == nil {
+		delivery.Decision = "ignored. the remote ignores this hook event"
 		c.Writer.WriteHeader(200)
 		return
 	}
 	if tmprepo == nil {
 		log.Errorf("failure to ascertain repo from hook.")
+		delivery.Decision = "failure to ascertain repo from hook"
 		c.Writer.WriteHeader(400)
 		return
 	}
 
 	// a build may be skipped if the text [CI SKIP]
 	// is found inside the commit message
-	if strings.Contains(build.Message, "[CI SKIP]") {
+	if skipMessage(build.Message) {
 		log.Infof("ignoring hook. [ci skip] found for %s")
+		delivery.Decision = "ignored. [CI SKIP] found in the commit message"
 		c.Writer.WriteHeader(204)
 		return
 	}
 
-	repo, err := store.GetRepoOwnerName(c, tmprepo.Owner, tmprepo.Name)
+	repo, err := store.GetRepoOwnerName(c, tmprepo.Owner, tmprepo.Name, context.RemoteID(c))
 	if err != nil {
 		log.Errorf("failure to find repo %s/%s from hook. %s", tmprepo.Owner, tmprepo.Name, err)
+		delivery.fail("failure to find repo "+tmprepo.Owner+"/"+tmprepo.Name, err)
 		c.AbortWithError(404, err)
 		return
 	}
 
-	// get the token and verify the hook is authorized
-	parsed, err := token.ParseRequest(c.Request, func(t *token.Token) (string, error) {
-		return repo.Hash, nil
-	})
-	if err != nil {
-		log.Errorf("failure to parse token from hook for %s. %s", repo.FullName, err)
-		c.AbortWithError(400, err)
-		return
-	}
-	if parsed.Text != repo.FullName {
-		log.Errorf("failure to verify token from hook. Expected %s, got %s", repo.FullName, parsed.Text)
+	// get the token and verify the hook is authorized, unless the
+	// hook was verified with the hook secret. Replayed hooks were
+	// authorized when the hook was delivered, since the token is
+	// redacted when the hook is stored.
+	switch {
+	case isReplay(c):
+		if replayOf(c).RepoID != repo.ID {
+			log.Errorf("failure to verify repo of replayed hook for %s.", repo.FullName)
+			delivery.Decision = "failure to verify repo of replayed hook"
+			c.AbortWithStatus(403)
+			return
+		}
+	case verified != nil:
+		if verified.ID != repo.ID {
+			log.Errorf("failure to verify repo of hook. Expected %s, got %s", verified.FullName, repo.FullName)
+			delivery.Decision = "failure to verify repo of hook"
+			c.AbortWithStatus(400)
+			return
+		}
+	case len(repo.HookSecret) != 0:
+		log.Errorf("failure to verify hook for %s. The hook is not signed.", repo.FullName)
+		delivery.Decision = "failure to verify hook. the hook is not signed"
 		c.AbortWithStatus(403)
 		return
+	default:
+		parsed, err := token.ParseRequest(c.Request, func(t *token.Token) (string, error) {
+			return repo.Hash, nil
+		})
+		if err != nil {
+			log.Errorf("failure to parse token from hook for %s. %s", repo.FullName, err)
+			delivery.fail("failure to parse token from hook", err)
+			c.AbortWithError(400, err)
+			return
+		}
+		if parsed.Text != repo.FullName {
+			log.Errorf("failure to verify token from hook. Expected %s, got %s", repo.FullName, parsed.Text)
+			delivery.Decision = "failure to verify token from hook"
+			c.AbortWithStatus(403)
+			return
+		}
 	}
 
+	// only hooks that passed authentication are recorded for
+	// the repository, and can be replayed.
+	delivery.RepoID = repo.ID
+
 	if repo.UserID == 0 {
 		log.Warnf("ignoring hook. repo %s has no owner.", repo.FullName)
+		delivery.Decision = "ignored. the repository has no owner"
 		c.Writer.WriteHeader(204)
 		return
 	}
-	var skipped = true
-	if (build.Event == model.EventPush && repo.AllowPush) ||
-		(build.Event == model.EventPull && repo.AllowPull) ||
-		(build.Event == model.EventDeploy && repo.AllowDeploy) ||
-		(build.Event == model.EventTag && repo.AllowTag) {
-		skipped = false
-	}
-
-	if skipped {
+	if !allowEvent(repo, build.Event) {
 		log.Infof("ignoring hook. repo %s is disabled for %s events.", repo.FullName, build.Event)
+		delivery.Decision = "ignored. the repository is disabled for " + build.Event + " events"
 		c.Writer.WriteHeader(204)
 		return
 	}
@@ -90,6 +147,7 @@ func PostHook(c *gin.Context) {
 	user, err := store.GetUser(c, repo.UserID)
 	if err != nil {
 		log.Errorf("failure to find repo owner %s. %s", repo.FullName, err)
+		delivery.fail("failure to find repo owner", err)
 		c.AbortWithError(500, err)
 		return
 	}
@@ -103,117 +161,157 @@ func PostHook(c *gin.Context) {
 	// a small number of people will probably be upset by this, I'm not sure
 	// it is actually that big of a deal.
 	if len(build.Email) == 0 {
-		author, err := store.GetUserLogin(c, build.Author)
+		author, err := store.GetUserLogin(c, build.Author, repo.Remote)
 		if err == nil {
 			build.Email = author.Email
 		}
 	}
 
-	// if the remote has a refresh token, the current access token
-	// may be stale. Therefore, we should refresh prior to dispatching
-	// the job.
-	if refresher, ok := remote_.(remote.Refresher); ok {
-		ok, _ := refresher.Refresh(user)
-		if ok {
-			store.UpdateUser(c, user)
-		}
+	trigger := &Trigger{
+		Engine: engine_,
+		Limits: context.Matrix(c),
+		Link:   httputil.GetURL(c.Request),
 	}
-
-	// fetch the .drone.yml file from the database
-	raw, sec, err := remote_.Script(user, repo, build)
+	code, decision, err := trigger.Build(c.Copy(), user, repo, build)
 	if err != nil {
-		log.Errorf("failure to get .drone.yml for %s. %s", repo.FullName, err)
-		c.AbortWithError(404, err)
+		delivery.fail(decision, err)
+		c.String(code, err.Error())
 		return
 	}
-
-	axes, err := matrix.Parse(string(raw))
-	if err != nil {
-		log.Errorf("failure to calculate matrix for %s. %s", repo.FullName, err)
-		c.AbortWithError(400, err)
+	delivery.Decision = decision
+	if build.ID == 0 {
+		c.AbortWithStatus(code)
 		return
 	}
-	if len(axes) == 0 {
-		axes = append(axes, matrix.Axis{})
-	}
+	delivery.Build = build.Number
+	c.JSON(code, build)
+}
 
-	netrc, err := remote_.Netrc(user, repo)
+// helper function to verify the hook with the secret of the
+// repository named in the hook url, before the hook is parsed. It
+// returns the repository, or nil if the repository has no hook
+// secret, in which case the hook is authorized with the token.
+func verifyHook(c *gin.Context) (*model.Repo, error) {
+	owner := c.Query("owner")
+	name := c.Query("name")
+	if len(owner) == 0 || len(name) == 0 {
+		return nil, nil
+	}
+	repo, err := store.GetRepoOwnerName(c, owner, name, context.RemoteID(c))
+	if err != nil || len(repo.HookSecret) == 0 {
+		return nil, nil
+	}
+	verifier, ok := remote.FromContext(c).(remote.HookVerifier)
+	if !ok {
+		return nil, fmt.Errorf("The remote cannot verify the hook of %s", repo.FullName)
+	}
+	err = verifier.VerifyHook(c.Request, repo.HookSecret)
 	if err != nil {
-		log.Errorf("failure to generate netrc for %s. %s", repo.FullName, err)
-		c.AbortWithError(500, err)
-		return
+		return nil, err
 	}
+	return repo, nil
+}
+
+// helper function returns true if the commit message
+// requests the build to be skipped.
+func skipMessage(message string) bool {
+	return strings.Contains(message, "[CI SKIP]")
+}
 
-	key, _ := store.GetKey(c, repo)
+// helper function returns true if the repository
+// allows builds for the hook event.
+func allowEvent(repo *model.Repo, event string) bool {
+	return (event == model.EventPush && repo.AllowPush) ||
+		(event == model.EventPull && repo.AllowPull) ||
+		(event == model.EventDeploy && repo.AllowDeploy) ||
+		(event == model.EventTag && repo.AllowTag)
+}
 
-	// verify the branches can be built vs skipped
-	yconfig, _ := yaml.Parse(string(raw))
-	var match = false
-	for _, branch := range yconfig.Branches {
-		if branch == build.Branch {
-			match = true
-			break
+// helper function returns true if the branch matches the
+// branch filters of the yaml file, or there are no filters.
+func matchBranch(conf *yaml.Config, branch string) bool {
+	if len(conf.Branches) == 0 {
+		return true
+	}
+	for _, pattern := range conf.Branches {
+		if pattern == branch {
+			return true
 		}
-		match, _ = filepath.Match(branch, build.Branch)
-		if match {
-			break
+		if match, _ := filepath.Match(pattern, branch); match {
+			return true
 		}
 	}
-	if !match && len(yconfig.Branches) != 0 {
-		log.Infof("ignoring hook. yaml file excludes repo and branch %s %s", repo.FullName, build.Branch)
-		c.AbortWithStatus(200)
-		return
-	}
-
-	// update some build fields
-	build.Status = model.StatusPending
-	build.RepoID = repo.ID
+	return false
+}
 
-	// and use a transaction
-	var jobs []*model.Job
-	for num, axis := range axes {
-		jobs = append(jobs, &model.Job{
-			BuildID:     build.ID,
-			Number:      num + 1,
-			Status:      model.StatusPending,
-			Environment: axis,
-		})
+// helper function to calculate the matrix axes of the yaml
+// file and create the build jobs. It returns an error if the
+// matrix or the named jobs are invalid.
+func parseJobs(raw string, limits matrix.Limits) ([]matrix.Axis, []*model.Job, error) {
+	axes, err := matrix.Parse(raw, limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(axes) == 0 {
+		axes = append(axes, matrix.Axis{})
 	}
-	err = store.CreateBuild(c, build, jobs...)
+	allowed, err := matrix.ParseAllowFailures(raw)
 	if err != nil {
-		log.Errorf("failure to save commit for %s. %s", repo.FullName, err)
-		c.AbortWithError(500, err)
-		return
+		return nil, nil, err
 	}
-
-	c.JSON(200, build)
-
-	url := fmt.Sprintf("%s/%s/%d", httputil.GetURL(c.Request), repo.FullName, build.Number)
-	err = remote_.Status(user, repo, build, url)
+	named, err := yaml.ParseJobs(raw)
 	if err != nil {
-		log.Errorf("error setting commit status for %s/%d", repo.FullName, build.Number)
+		return nil, nil, err
 	}
+	return axes, createJobs(axes, named, allowed), nil
+}
 
-	// get the previous build so taht we can send
-	// on status change notifications
-	last, _ := store.GetBuildLastBefore(c, repo, build.Branch, build.ID)
+// helper function to create the build jobs for each matrix axis. If
+// the yaml file declares named jobs, each named job is created for
+// each axis, and its dependencies are resolved to the numbers of the
+// jobs in the same axis. Jobs for axes matching the allowed failures
+// do not affect the overall build status.
+func createJobs(axes []matrix.Axis, named []*yaml.Job, allowed []matrix.Axis) []*model.Job {
+	var jobs []*model.Job
+	for _, axis := range axes {
+		allowFailure := axis.Match(allowed)
+		if len(named) == 0 {
+			jobs = append(jobs, &model.Job{
+				Number:       len(jobs) + 1,
+				Status:       model.StatusPending,
+				Environment:  axis,
+				AllowFailure: allowFailure,
+			})
+			continue
+		}
 
-	engine_ := context.Engine(c)
-	go engine_.Schedule(c.Copy(), &engine.Task{
-		User:      user,
-		Repo:      repo,
-		Build:     build,
-		BuildPrev: last,
-		Jobs:      jobs,
-		Keys:      key,
-		Netrc:     netrc,
-		Config:    string(raw),
-		Secret:    string(sec),
-		System: &model.System{
-			Link:    httputil.GetURL(c.Request),
-			Plugins: strings.Split(os.Getenv("PLUGIN_FILTER"), " "),
-			Globals: strings.Split(os.Getenv("PLUGIN_PARAMS"), " "),
-		},
-	})
+		numbers := map[string]int{}
+		for i, job := range named {
+			numbers[job.Name] = len(jobs) + i + 1
+		}
+		for _, job := range named {
+			env := map[string]string{}
+			for k, v := range axis {
+				env[k] = v
+			}
+			for k, v := range job.Environment {
+				env[k] = v
+			}
+			env["DRONE_JOB_NAME"] = job.Name
 
+			var deps []int
+			for _, dep := range job.DependsOn {
+				deps = append(deps, numbers[dep])
+			}
+			jobs = append(jobs, &model.Job{
+				Number:       len(jobs) + 1,
+				Status:       model.StatusPending,
+				Environment:  env,
+				Name:         job.Name,
+				DependsOn:    deps,
+				AllowFailure: allowFailure,
+			})
+		}
+	}
+	return jobs
 }
@@ -9,6 +9,7 @@ import (
 	log "github.com/Sirupsen/logrus"
 	"github.com/CiscoCloud/drone/model"
 	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/router/middleware/context"
 	"github.com/CiscoCloud/drone/shared/crypto"
 	"github.com/CiscoCloud/drone/shared/httputil"
 	"github.com/CiscoCloud/drone/shared/token"
@@ -23,6 +24,12 @@ func GetLogin(c *gin.Context) {
 	// rememver why, so need to revisit this line.
 	c.Writer.Header().Del("Content-Type")
 
+	// remember the remote selected on the login page, since
+	// the remote redirects back without the remote parameter.
+	if len(c.Query("remote")) != 0 {
+		httputil.SetCookie(c.Writer, c.Request, "user_remote", context.RemoteID(c))
+	}
+
 	tmpuser, open, err := remote.Login(c.Writer, c.Request)
 	if err != nil {
 		log.Errorf("cannot authenticate user. %s", err)
@@ -36,7 +43,7 @@ func GetLogin(c *gin.Context) {
 	}
 
 	// get the user from the database
-	u, err := store.GetUserLogin(c, tmpuser.Login)
+	u, err := store.GetUserLogin(c, tmpuser.Login, context.RemoteID(c))
 	if err != nil {
 		count, err := store.CountUsers(c)
 		if err != nil {
@@ -62,6 +69,7 @@ func GetLogin(c *gin.Context) {
 		u.Email = tmpuser.Email
 		u.Avatar = tmpuser.Avatar
 		u.Hash = crypto.Rand()
+		u.Remote = context.RemoteID(c)
 
 		// insert the user into the database
 		if err := store.CreateUser(c, u); err != nil {
@@ -112,6 +120,7 @@ func GetLogout(c *gin.Context) {
 
 	httputil.DelCookie(c.Writer, c.Request, "user_sess")
 	httputil.DelCookie(c.Writer, c.Request, "user_last")
+	httputil.DelCookie(c.Writer, c.Request, "user_remote")
 	c.Redirect(303, "/login")
 }
 
@@ -131,7 +140,7 @@ func GetLoginToken(c *gin.Context) {
 		return
 	}
 
-	user, err := store.GetUserLogin(c, login)
+	user, err := store.GetUserLogin(c, login, context.RemoteID(c))
 	if err != nil {
 		c.AbortWithError(http.StatusNotFound, err)
 		return
@@ -22,6 +22,13 @@ func GetNodes(c *gin.Context) {
 	}
 }
 
+// GetNodeStats returns the scheduler statistics, including the rate
+// at which builds run on the node that last ran the repository.
+func GetNodeStats(c *gin.Context) {
+	engine := context.Engine(c)
+	c.JSON(200, engine.Stats())
+}
+
 func ShowNodes(c *gin.Context) {
 	user := session.User(c)
 	nodes, _ := store.GetNodeList(c)
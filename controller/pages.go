@@ -10,6 +10,7 @@ import (
 
 	"github.com/CiscoCloud/drone/model"
 	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/router/middleware/context"
 	"github.com/CiscoCloud/drone/router/middleware/session"
 	"github.com/CiscoCloud/drone/shared/httputil"
 	"github.com/CiscoCloud/drone/shared/token"
@@ -56,7 +57,14 @@ func ShowIndex(c *gin.Context) {
 }
 
 func ShowLogin(c *gin.Context) {
-	c.HTML(200, "login.html", gin.H{"Error": c.Query("error")})
+	data := gin.H{"Error": c.Query("error")}
+
+	// display a login option for each remote when
+	// the server hosts several remotes.
+	if ids := context.Remotes(c).IDs(); len(ids) > 1 {
+		data["Remotes"] = ids
+	}
+	c.HTML(200, "login.html", data)
 }
 
 func ShowLoginForm(c *gin.Context) {
@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/router/middleware/context"
+	"github.com/CiscoCloud/drone/router/middleware/session"
+	"github.com/CiscoCloud/drone/shared/token"
+	"github.com/CiscoCloud/drone/store"
+	"github.com/CiscoCloud/drone/yaml"
+)
+
+// hookPreview explains what would happen if a hook was
+// received for the build, without creating the build.
+type hookPreview struct {
+	Build   *model.Build `json:"build"`
+	Trigger bool         `json:"trigger"`
+	Checks  []*hookCheck `json:"checks"`
+	Jobs    []*model.Job `json:"jobs"`
+}
+
+// hookCheck is a single decision made when processing
+// the hook, and the reason the check passed or failed.
+type hookCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Reason string `json:"reason"`
+}
+
+func (p *hookPreview) check(name string, passed bool, format string, args ...interface{}) {
+	p.Checks = append(p.Checks, &hookCheck{
+		Name:   name,
+		Passed: passed,
+		Reason: fmt.Sprintf(format, args...),
+	})
+	if !passed {
+		p.Trigger = false
+	}
+}
+
+// PostHookPreview explains what would happen if a hook was received
+// for the commit, branch or tag of the repository in the request
+// body, without creating a build.
+func PostHookPreview(c *gin.Context) {
+	repo := session.Repo(c)
+
+	in := struct {
+		Event   string `json:"event"`
+		Branch  string `json:"branch"`
+		Ref     string `json:"ref"`
+		Commit  string `json:"commit"`
+		Message string `json:"message"`
+	}{}
+	err := c.Bind(&in)
+	if err != nil {
+		c.String(400, err.Error())
+		return
+	}
+
+	build := &model.Build{
+		Event:   in.Event,
+		Branch:  in.Branch,
+		Ref:     in.Ref,
+		Commit:  in.Commit,
+		Message: in.Message,
+	}
+	if len(build.Event) == 0 {
+		build.Event = model.EventPush
+	}
+	if len(build.Branch) == 0 && strings.HasPrefix(build.Ref, "refs/heads/") {
+		build.Branch = strings.TrimPrefix(build.Ref, "refs/heads/")
+	}
+	if len(build.Ref) == 0 && len(build.Branch) != 0 {
+		build.Ref = "refs/heads/" + build.Branch
+	}
+	if len(build.Commit) == 0 && len(build.Branch) == 0 {
+		c.String(400, "A commit, branch or ref is required")
+		return
+	}
+
+	c.IndentedJSON(200, previewHook(c, repo, build))
+}
+
+// PostHookPayloadPreview explains what would happen if the hook
+// payload in the request was received, without creating a build.
+// The request is authorized with the hook token, like the hook.
+func PostHookPayloadPreview(c *gin.Context) {
+	remote_ := remote.FromContext(c)
+
+	tmprepo, build, err := remote_.Hook(c.Request)
+	if err != nil {
+		c.String(400, "Failure to parse hook. %s", err)
+		return
+	}
+	if tmprepo == nil {
+		c.String(400, "Failure to ascertain repo from hook")
+		return
+	}
+
+	repo, err := store.GetRepoOwnerName(c, tmprepo.Owner, tmprepo.Name, context.RemoteID(c))
+	if err != nil {
+		c.String(404, "Failure to find repo %s/%s from hook", tmprepo.Owner, tmprepo.Name)
+		return
+	}
+
+	parsed, err := token.ParseRequest(c.Request, func(t *token.Token) (string, error) {
+		return repo.Hash, nil
+	})
+	if err != nil || parsed.Text != repo.FullName {
+		c.AbortWithStatus(403)
+		return
+	}
+
+	if build == nil {
+		preview := &hookPreview{}
+		preview.check("hook", false, "the remote ignores this hook event")
+		c.IndentedJSON(200, preview)
+		return
+	}
+	c.IndentedJSON(200, previewHook(c, repo, build))
+}
+
+// helper function to run the hook decision pipeline for
+// the build and explain each decision, without creating
+// the build or sending the commit status.
+func previewHook(c *gin.Context, repo *model.Repo, build *model.Build) *hookPreview {
+	remote_ := remote.FromContext(c)
+	engine_ := context.Engine(c)
+
+	preview := &hookPreview{Build: build, Trigger: true}
+
+	if engine_.Draining() {
+		preview.check("server", false, "the server is shutting down")
+	} else {
+		preview.check("server", true, "the server is accepting hooks")
+	}
+
+	if skipMessage(build.Message) {
+		preview.check("skip", false, "the commit message contains [CI SKIP]")
+	} else {
+		preview.check("skip", true, "the commit message does not contain [CI SKIP]")
+	}
+
+	if repo.UserID == 0 {
+		preview.check("owner", false, "the repository has no owner")
+		return preview
+	}
+	preview.check("owner", true, "the repository has an owner")
+
+	if allowEvent(repo, build.Event) {
+		preview.check("event", true, "the repository allows %s events", build.Event)
+	} else {
+		preview.check("event", false, "the repository does not allow %s events", build.Event)
+	}
+
+	user, err := store.GetUser(c, repo.UserID)
+	if err != nil {
+		preview.check("owner", false, "failure to find the repository owner. %s", err)
+		return preview
+	}
+
+	// the .drone.yml file is fetched for the commit, or
+	// the head of the branch if no commit is provided.
+	fetch := *build
+	if len(fetch.Commit) == 0 {
+		fetch.Commit = fetch.Branch
+	}
+	raw, _, err := remote_.Script(user, repo, &fetch)
+	if err != nil {
+		preview.check("config", false, "failure to get the .drone.yml file. %s", err)
+		return preview
+	}
+	preview.check("config", true, "found the .drone.yml file")
+
+	conf, _ := yaml.Parse(string(raw))
+	switch {
+	case len(conf.Branches) == 0:
+		preview.check("branch", true, "the .drone.yml file has no branch filters")
+	case matchBranch(conf, build.Branch):
+		preview.check("branch", true, "branch %s matches the branch filters %s", build.Branch, strings.Join(conf.Branches, ", "))
+	default:
+		preview.check("branch", false, "branch %s does not match the branch filters %s", build.Branch, strings.Join(conf.Branches, ", "))
+	}
+
+	_, jobs, err := parseJobs(string(raw), context.Matrix(c))
+	if err != nil {
+		preview.check("matrix", false, "invalid build matrix or jobs. %s", err)
+		return preview
+	}
+	preview.check("matrix", true, "the build has %d jobs", len(jobs))
+	preview.Jobs = jobs
+	return preview
+}
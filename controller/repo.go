@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	log "github.com/Sirupsen/logrus"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/CiscoCloud/drone/model"
 	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/router/middleware/context"
 	"github.com/CiscoCloud/drone/router/middleware/session"
 	"github.com/CiscoCloud/drone/shared/crypto"
 	"github.com/CiscoCloud/drone/shared/httputil"
@@ -21,7 +23,7 @@ import (
 )
 
 func PostRepo(c *gin.Context) {
-	remote := remote.FromContext(c)
+	remote_ := remote.FromContext(c)
 	user := session.User(c)
 	owner := c.Param("owner")
 	name := c.Param("name")
@@ -32,12 +34,12 @@ func PostRepo(c *gin.Context) {
 		return
 	}
 
-	r, err := remote.Repo(user, owner, name)
+	r, err := remote_.Repo(user, owner, name)
 	if err != nil {
 		c.String(404, err.Error())
 		return
 	}
-	m, err := remote.Perm(user, owner, name)
+	m, err := remote_.Perm(user, owner, name)
 	if err != nil {
 		c.String(404, err.Error())
 		return
@@ -48,7 +50,7 @@ func PostRepo(c *gin.Context) {
 	}
 
 	// error if the repository already exists
-	_, err = store.GetRepoOwnerName(c, owner, name)
+	_, err = store.GetRepoOwnerName(c, owner, name, context.RemoteID(c))
 	if err == nil {
 		c.String(409, "Repository already exists.")
 		return
@@ -57,17 +59,16 @@ func PostRepo(c *gin.Context) {
 	// set the repository owner to the
 	// currently authenticated user.
 	r.UserID = user.ID
+	r.Remote = context.RemoteID(c)
 	r.AllowPush = true
 	r.AllowPull = true
 	r.Timeout = 60 // 1 hour default build time
 	r.Hash = crypto.Rand()
 
-	// crates the jwt token used to verify the repository
-	t := token.New(token.HookToken, r.FullName)
-	sig, err := t.Sign(r.Hash)
-	if err != nil {
-		c.String(500, err.Error())
-		return
+	// the hooks of remotes that can verify hooks are
+	// signed with a secret, instead of the jwt token.
+	if _, ok := remote_.(remote.HookVerifier); ok {
+		r.HookSecret = crypto.Rand()
 	}
 
 	// generate an RSA key and add to the repo
@@ -86,20 +87,20 @@ func PostRepo(c *gin.Context) {
 		activate = true
 	}
 	if activate {
-		link := fmt.Sprintf(
-			"%s/hook?access_token=%s",
-			httputil.GetURL(c.Request),
-			sig,
-		)
+		link, err := hookLink(c, r)
+		if err != nil {
+			c.String(500, err.Error())
+			return
+		}
 
 		// activate the repository before we make any
 		// local changes to the database.
-		err = remote.Activate(user, r, keys, link)
+		err = remote_.Activate(user, r, keys, link)
 		if err != nil {
 			c.String(500, err.Error())
 			return
 		}
-  }
+	}
 
 	// persist the repository
 	err = store.CreateRepo(c, r)
@@ -128,6 +129,10 @@ func PatchRepo(c *gin.Context) {
 		AllowPush   *bool  `json:"allow_push,omitempty"`
 		AllowDeploy *bool  `json:"allow_deploy,omitempty"`
 		AllowTag    *bool  `json:"allow_tag,omitempty"`
+		Poll        *bool  `json:"poll,omitempty"`
+		JobStatus   *bool  `json:"job_status,omitempty"`
+		Comment     *bool  `json:"comment,omitempty"`
+		CommentLogs *int   `json:"comment_log_lines,omitempty"`
 	}{}
 	if err := c.Bind(in); err != nil {
 		c.AbortWithError(http.StatusBadRequest, err)
@@ -146,6 +151,18 @@ func PatchRepo(c *gin.Context) {
 	if in.AllowTag != nil {
 		repo.AllowTag = *in.AllowTag
 	}
+	if in.Poll != nil {
+		repo.Poll = *in.Poll
+	}
+	if in.JobStatus != nil {
+		repo.JobStatus = *in.JobStatus
+	}
+	if in.Comment != nil {
+		repo.Comment = *in.Comment
+	}
+	if in.CommentLogs != nil {
+		repo.CommentLogs = *in.CommentLogs
+	}
 	if in.IsTrusted != nil && user.Admin {
 		repo.IsTrusted = *in.IsTrusted
 	}
@@ -252,3 +269,83 @@ func PostSecure(c *gin.Context) {
 func PostReactivate(c *gin.Context) {
 
 }
+
+// PostRepoHook registers the hook of the repository again with a new
+// hook secret. It migrates repositories activated before hook secrets
+// were introduced, whose hooks are only authorized with the token.
+func PostRepoHook(c *gin.Context) {
+	remote_ := remote.FromContext(c)
+	repo := session.Repo(c)
+	user := session.User(c)
+
+	if _, ok := remote_.(remote.HookVerifier); !ok {
+		c.String(400, "The remote does not support hook secrets.")
+		return
+	}
+
+	key, err := store.GetKey(c, repo)
+	if err != nil {
+		c.String(500, err.Error())
+		return
+	}
+
+	// remove the existing hooks, which are authorized with
+	// the token or registered with a previous secret.
+	err = remote_.Deactivate(user, repo, httputil.GetURL(c.Request))
+	if err != nil {
+		log.Errorf("failure to remove the hooks of %s. %s", repo.FullName, err)
+	}
+
+	repo.HookSecret = crypto.Rand()
+	link, err := hookLink(c, repo)
+	if err != nil {
+		c.String(500, err.Error())
+		return
+	}
+	err = remote_.Activate(user, repo, key, link)
+	if err != nil {
+		c.String(500, err.Error())
+		return
+	}
+
+	err = store.UpdateRepo(c, repo)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, repo)
+}
+
+// helper function to create the link of the repository hook. Hooks
+// of repositories with a hook secret identify the repository in the
+// link and are verified with the secret, otherwise the hooks are
+// authorized with a jwt token signed with the repository hash.
+func hookLink(c *gin.Context, repo *model.Repo) (string, error) {
+	var link string
+	if len(repo.HookSecret) != 0 {
+		link = fmt.Sprintf(
+			"%s/hook?owner=%s&name=%s",
+			httputil.GetURL(c.Request),
+			url.QueryEscape(repo.Owner),
+			url.QueryEscape(repo.Name),
+		)
+	} else {
+		t := token.New(token.HookToken, repo.FullName)
+		sig, err := t.Sign(repo.Hash)
+		if err != nil {
+			return "", err
+		}
+		link = fmt.Sprintf(
+			"%s/hook?access_token=%s",
+			httputil.GetURL(c.Request),
+			sig,
+		)
+	}
+
+	// the hook selects the remote that parses the hook
+	// when the server hosts several remotes.
+	if len(context.Remotes(c).IDs()) > 1 {
+		link += "&remote=" + url.QueryEscape(repo.Remote)
+	}
+	return link, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/CiscoCloud/drone/engine"
+	"github.com/CiscoCloud/drone/model"
 	"github.com/CiscoCloud/drone/router/middleware/context"
 	"github.com/CiscoCloud/drone/router/middleware/session"
 	"github.com/CiscoCloud/drone/store"
@@ -74,11 +75,17 @@ func GetStream(c *gin.Context) {
 		c.AbortWithError(404, err)
 		return
 	}
-	node, err := store.GetNode(c, job.NodeID)
-	if err != nil {
-		log.Debugln("stream cannot get node.", err)
-		c.AbortWithError(404, err)
-		return
+
+	// jobs executed by engines that do not use registered
+	// nodes are not assigned to a node.
+	node := new(model.Node)
+	if job.NodeID != 0 {
+		node, err = store.GetNode(c, job.NodeID)
+		if err != nil {
+			log.Debugln("stream cannot get node.", err)
+			c.AbortWithError(404, err)
+			return
+		}
 	}
 
 	rc, err := engine_.Stream(build.ID, job.ID, node)
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/CiscoCloud/drone/engine"
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/store"
+	"github.com/CiscoCloud/drone/yaml"
+	"github.com/CiscoCloud/drone/yaml/matrix"
+	"golang.org/x/net/context"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Trigger creates builds and schedules them with the engine. It is
+// the processing shared by hooks and the repository poller, once the
+// repository and its owner are known.
+type Trigger struct {
+	// Engine schedules the builds.
+	Engine engine.Engine
+
+	// Limits is the maximum size of the build matrix.
+	Limits matrix.Limits
+
+	// Link is the url of the server, used to link the
+	// commit status to the build.
+	Link string
+}
+
+// Build creates the build from the .drone.yml file at the commit and
+// schedules it. The context must outlive the request, since it is
+// passed to the engine. It returns the status code and the decision
+// recorded for the hook, and an error if the build cannot be created.
+func (t *Trigger) Build(c context.Context, user *model.User, repo *model.Repo, build *model.Build) (int, string, error) {
+	remote_ := remote.FromContext(c)
+
+	// if the remote has a refresh token, the current access token
+	// may be stale. Therefore, we should refresh prior to dispatching
+	// the job.
+	if refresher, ok := remote_.(remote.Refresher); ok {
+		ok, _ := refresher.Refresh(user)
+		if ok {
+			store.UpdateUser(c, user)
+		}
+	}
+
+	// fetch the .drone.yml file from the database
+	raw, sec, err := remote_.Script(user, repo, build)
+	if err != nil {
+		log.Errorf("failure to get .drone.yml for %s. %s", repo.FullName, err)
+		return 404, "failure to get .drone.yml", err
+	}
+
+	// verify the branches can be built vs skipped, before the
+	// jobs are calculated, so that an invalid configuration is
+	// not reported in the commit status of excluded branches.
+	yconfig, _ := yaml.Parse(string(raw))
+	if !matchBranch(yconfig, build.Branch) {
+		log.Infof("ignoring hook. yaml file excludes repo and branch %s %s", repo.FullName, build.Branch)
+		return 200, "ignored. the .drone.yml file excludes branch " + build.Branch, nil
+	}
+
+	axes, jobs, err := parseJobs(string(raw), t.Limits)
+	if err != nil {
+		log.Errorf("failure to calculate jobs for %s. %s", repo.FullName, err)
+
+		// report the invalid configuration in the commit
+		// status since the build is never created.
+		build.Status = model.StatusError
+		url := fmt.Sprintf("%s/%s", t.Link, repo.FullName)
+		if err := remote_.Status(user, repo, build, url); err != nil {
+			log.Errorf("error setting commit status for %s. %s", repo.FullName, err)
+		}
+		return 400, "failure to calculate jobs", err
+	}
+	netrc, err := remote_.Netrc(user, repo)
+	if err != nil {
+		log.Errorf("failure to generate netrc for %s. %s", repo.FullName, err)
+		return 500, "failure to generate netrc", err
+	}
+
+	key, _ := store.GetKey(c, repo)
+
+	// update some build fields
+	build.Status = model.StatusPending
+	build.RepoID = repo.ID
+
+	// and use a transaction
+	err = store.CreateBuild(c, build, jobs...)
+	if err != nil {
+		log.Errorf("failure to save commit for %s. %s", repo.FullName, err)
+		return 500, "failure to save commit", err
+	}
+
+	// snapshot the configuration so that the build can
+	// be re-started with the same configuration.
+	config := &model.Config{
+		BuildID: build.ID,
+		Data:    string(raw),
+		Secret:  string(sec),
+	}
+	for _, axis := range axes {
+		config.Axes = append(config.Axes, axis)
+	}
+	err = store.CreateConfig(c, config)
+	if err != nil {
+		log.Errorf("failure to save configuration snapshot for %s/%d. %s", repo.FullName, build.Number, err)
+	}
+
+	url := fmt.Sprintf("%s/%s/%d", t.Link, repo.FullName, build.Number)
+	err = remote_.Status(user, repo, build, url)
+	if err != nil {
+		log.Errorf("error setting commit status for %s/%d", repo.FullName, build.Number)
+	}
+
+	// get the previous build so taht we can send
+	// on status change notifications
+	last, _ := store.GetBuildLastBefore(c, repo, build.Branch, build.ID)
+
+	go t.Engine.Schedule(c, &engine.Task{
+		User:      user,
+		Repo:      repo,
+		Build:     build,
+		BuildPrev: last,
+		Jobs:      jobs,
+		Keys:      key,
+		Netrc:     netrc,
+		Config:    string(raw),
+		Secret:    string(sec),
+		System: &model.System{
+			Link:    t.Link,
+			Plugins: strings.Split(os.Getenv("PLUGIN_FILTER"), " "),
+			Globals: strings.Split(os.Getenv("PLUGIN_PARAMS"), " "),
+		},
+	})
+
+	return 200, fmt.Sprintf("created build %d", build.Number), nil
+}
+
+// Poll creates and schedules the build for a commit found by
+// the repository poller.
+func (t *Trigger) Poll(c context.Context, user *model.User, repo *model.Repo, build *model.Build) error {
+	_, _, err := t.Build(c, user, repo, build)
+	return err
+}
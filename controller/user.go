@@ -7,6 +7,7 @@ import (
 
 	"github.com/CiscoCloud/drone/model"
 	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/router/middleware/context"
 	"github.com/CiscoCloud/drone/router/middleware/session"
 	"github.com/CiscoCloud/drone/shared/token"
 	"github.com/CiscoCloud/drone/store"
@@ -34,7 +35,9 @@ func GetFeed(c *gin.Context) {
 		}
 	}
 
-	feed, err := store.GetUserFeed(c, repos)
+	// the feed only includes the repositories that were
+	// activated from the remote of the user.
+	feed, err := store.GetUserFeed(c, repos, context.RemoteID(c))
 	if err != nil {
 		c.String(400, err.Error())
 		return
@@ -62,7 +65,7 @@ func GetRepos(c *gin.Context) {
 
 	// for each repository in the remote system we get
 	// the intersection of those repostiories in Drone
-	repos_, err := store.GetRepoListOf(c, repos)
+	repos_, err := store.GetRepoListOf(c, repos, context.RemoteID(c))
 	if err != nil {
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/router/middleware/context"
 	"github.com/CiscoCloud/drone/router/middleware/session"
 	"github.com/CiscoCloud/drone/shared/crypto"
 	"github.com/CiscoCloud/drone/shared/token"
@@ -23,7 +24,7 @@ func GetUsers(c *gin.Context) {
 }
 
 func GetUser(c *gin.Context) {
-	user, err := store.GetUserLogin(c, c.Param("login"))
+	user, err := store.GetUserLogin(c, c.Param("login"), userRemote(c))
 	if err != nil {
 		c.AbortWithStatus(http.StatusNotFound)
 		return
@@ -51,7 +52,7 @@ func PatchUser(c *gin.Context) {
 		return
 	}
 
-	user, err := store.GetUserLogin(c, c.Param("login"))
+	user, err := store.GetUserLogin(c, c.Param("login"), userRemote(c))
 	if err != nil {
 		c.AbortWithStatus(http.StatusNotFound)
 		return
@@ -93,6 +94,12 @@ func PostUser(c *gin.Context) {
 	user.Avatar = in.Avatar
 	user.Active = true
 	user.Hash = crypto.Rand()
+	user.Remote = context.Remotes(c).ID(in.Remote)
+
+	if context.Remotes(c).Get(user.Remote) == nil {
+		c.String(http.StatusBadRequest, "Unknown remote %s.", user.Remote)
+		return
+	}
 
 	err = store.CreateUser(c, user)
 	if err != nil {
@@ -116,7 +123,7 @@ func PostUser(c *gin.Context) {
 func DeleteUser(c *gin.Context) {
 	me := session.User(c)
 
-	user, err := store.GetUserLogin(c, c.Param("login"))
+	user, err := store.GetUserLogin(c, c.Param("login"), userRemote(c))
 	if err != nil {
 		c.AbortWithStatus(http.StatusNotFound)
 		return
@@ -136,3 +143,9 @@ func DeleteUser(c *gin.Context) {
 
 	c.Writer.WriteHeader(http.StatusNoContent)
 }
+
+// helper function returns the remote of the user in the request,
+// selected with the remote url parameter, or the default remote.
+func userRemote(c *gin.Context) string {
+	return context.Remotes(c).ID(c.Query("remote"))
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"flag"
+	"time"
 
+	"github.com/CiscoCloud/drone/controller"
 	"github.com/CiscoCloud/drone/engine"
+	"github.com/CiscoCloud/drone/poller"
 	"github.com/CiscoCloud/drone/remote"
 	"github.com/CiscoCloud/drone/router"
 	"github.com/CiscoCloud/drone/router/middleware/cache"
@@ -12,6 +15,7 @@ import (
 	"github.com/CiscoCloud/drone/shared/envconfig"
 	"github.com/CiscoCloud/drone/shared/server"
 	"github.com/CiscoCloud/drone/store/datastore"
+	"github.com/CiscoCloud/drone/yaml/matrix"
 
 	"github.com/Sirupsen/logrus"
 )
@@ -39,21 +43,52 @@ func main() {
 	// Setup the database driver
 	store_ := datastore.Load(env)
 
-	// setup the remote driver
-	remote_ := remote.Load(env)
+	// setup the remote drivers
+	remotes_ := remote.LoadRemotes(env)
+
+	// users and repositories registered before the server hosted
+	// several remotes belong to the default remote.
+	if err := store_.Users().SetDefaultRemote(remotes_.ID("")); err != nil {
+		logrus.Fatalf("cannot set the remote of users. %s", err)
+	}
+	if err := store_.Repos().SetDefaultRemote(remotes_.ID("")); err != nil {
+		logrus.Fatalf("cannot set the remote of repositories. %s", err)
+	}
 
 	// setup the runner
 	engine_ := engine.Load(env, store_)
 
-	// setup the server and start the listener
+	// setup the poller, which triggers builds for repositories
+	// that are polled for changes instead of receiving hooks.
+	trigger := &controller.Trigger{
+		Engine: engine_,
+		Limits: matrix.Load(env),
+		Link:   env.String("SERVER_URL", ""),
+	}
+	poller_ := poller.Load(env, store_, remotes_, trigger.Poll)
+	poller_.Start()
+
+	// setup the server and start the listener. The server runs
+	// until the process receives an interrupt or termination signal.
 	server_ := server.Load(env)
 	server_.Run(
 		router.Load(
 			header.Version(build),
 			cache.Default(),
 			context.SetStore(store_),
-			context.SetRemote(remote_),
+			context.SetRemotes(remotes_),
 			context.SetEngine(engine_),
+			context.SetMatrix(matrix.Load(env)),
+			context.SetHookRetention(env.Duration("HOOK_LOG_RETENTION", 168*time.Hour)),
 		),
 	)
+
+	// stop polling before draining so that no new
+	// builds are triggered.
+	poller_.Stop()
+
+	// drain running builds before closing the listener so
+	// that clients continue to receive build events.
+	engine_.Drain(server_.Timeout)
+	server_.Shutdown()
 }
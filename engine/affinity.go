@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"sync"
+
+	"github.com/CiscoCloud/drone/model"
+)
+
+// Stats reports how often the scheduler was able to run a
+// build on the node that last ran the repository successfully.
+type Stats struct {
+	// Scheduled is the number of builds scheduled.
+	Scheduled int64 `json:"scheduled"`
+
+	// Preferred is the number of builds that had
+	// a preferred node.
+	Preferred int64 `json:"preferred"`
+
+	// Hits is the number of builds that ran on
+	// their preferred node.
+	Hits int64 `json:"hits"`
+
+	// HitRate is the ratio of hits to builds that
+	// had a preferred node.
+	HitRate float64 `json:"hit_rate"`
+}
+
+// affinity records the node that last ran each repository
+// successfully, so that builds can be sent to the node that
+// already has the build cache and images.
+type affinity struct {
+	sync.Mutex
+	nodes map[int64]*model.Node
+	stats Stats
+}
+
+func newAffinity() *affinity {
+	return &affinity{nodes: make(map[int64]*model.Node)}
+}
+
+// Get returns the preferred node for the repository, or
+// nil if the repository has no preferred node.
+func (a *affinity) get(repo int64) *model.Node {
+	a.Lock()
+	defer a.Unlock()
+	return a.nodes[repo]
+}
+
+// Set sets the preferred node for the repository.
+func (a *affinity) set(repo int64, node *model.Node) {
+	a.Lock()
+	defer a.Unlock()
+	a.nodes[repo] = node
+}
+
+// Record records the scheduling decision for a build.
+func (a *affinity) record(preferred, hit bool) {
+	a.Lock()
+	defer a.Unlock()
+	a.stats.Scheduled++
+	if preferred {
+		a.stats.Preferred++
+	}
+	if hit {
+		a.stats.Hits++
+	}
+}
+
+// Report returns the scheduler statistics.
+func (a *affinity) report() *Stats {
+	a.Lock()
+	defer a.Unlock()
+
+	stats := a.stats
+	if stats.Preferred != 0 {
+		stats.HitRate = float64(stats.Hits) / float64(stats.Preferred)
+	}
+	return &stats
+}
@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/franela/goblin"
+)
+
+func TestAffinity(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Affinity", func() {
+
+		g.It("Should set the preferred node", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			a := newAffinity()
+			g.Assert(a.get(1) == nil).IsTrue()
+			a.set(1, n)
+			g.Assert(a.get(1)).Equal(n)
+			g.Assert(a.get(2) == nil).IsTrue()
+		})
+
+		g.It("Should report the hit rate", func() {
+			a := newAffinity()
+			g.Assert(a.report().HitRate).Equal(0.0)
+			a.record(false, false)
+			a.record(true, true)
+			a.record(true, false)
+			a.record(true, true)
+			a.record(true, true)
+
+			stats := a.report()
+			g.Assert(stats.Scheduled).Equal(int64(5))
+			g.Assert(stats.Preferred).Equal(int64(4))
+			g.Assert(stats.Hits).Equal(int64(3))
+			g.Assert(stats.HitRate).Equal(0.75)
+		})
+	})
+}
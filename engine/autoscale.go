@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/CiscoCloud/drone/engine/provision"
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/shared/envconfig"
+	"github.com/CiscoCloud/drone/store"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// autoscaler grows the pool of nodes when builds are waiting for
+// a node, and destroys provisioned nodes that remain idle.
+type autoscaler struct {
+	pool     *pool
+	tracker  *tracker
+	nodes    store.NodeStore
+	provider provision.Provisioner
+
+	// allocate allocates a provisioned node to the pool.
+	allocate func(*model.Node)
+
+	// min and max are the lower and upper limit
+	// of provisioned nodes in the pool.
+	min int
+	max int
+
+	// idle is the time a provisioned node must remain
+	// idle before it is destroyed.
+	idle time.Duration
+
+	// interval is the time between scaling decisions.
+	interval time.Duration
+}
+
+func newAutoscaler(p *pool, t *tracker, nodes store.NodeStore, provider provision.Provisioner, allocate func(*model.Node), env envconfig.Env) *autoscaler {
+	return &autoscaler{
+		pool:     p,
+		tracker:  t,
+		nodes:    nodes,
+		provider: provider,
+		allocate: allocate,
+		min:      env.Int("PROVISION_MIN", 0),
+		max:      env.Int("PROVISION_MAX", 5),
+		idle:     env.Duration("PROVISION_IDLE_TIMEOUT", 15*time.Minute),
+		interval: 30 * time.Second,
+	}
+}
+
+// Start starts scaling the pool in the background until
+// the engine starts draining.
+func (a *autoscaler) start() {
+	log.Infof("autoscaling between %d and %d provisioned nodes", a.min, a.max)
+
+	go func() {
+		for {
+			a.scale()
+
+			select {
+			case <-time.After(a.interval):
+			case <-a.tracker.done:
+				return
+			}
+		}
+	}()
+}
+
+// Scale creates nodes for builds that are waiting for a node, up to
+// the maximum, and destroys provisioned nodes that have been idle
+// longer than the idle timeout, down to the minimum.
+func (a *autoscaler) scale() {
+	if a.tracker.isDraining() {
+		return
+	}
+
+	nodes := a.provisioned()
+	pending := a.tracker.pending()
+
+	// nodes that are still pulling the warm-up images count as
+	// capacity, so that nodes are not provisioned on every tick
+	// while the first node is warming up.
+	count := pending - a.pool.idle() - a.pool.warming()
+	if count < a.min-len(nodes) {
+		count = a.min - len(nodes)
+	}
+	if count > a.max-len(nodes) {
+		count = a.max - len(nodes)
+	}
+
+	if count > 0 {
+		log.Infof("provisioning %d nodes for %d pending builds", count, pending)
+
+		var wg sync.WaitGroup
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func() {
+				a.create()
+				wg.Done()
+			}()
+		}
+		wg.Wait()
+		return
+	}
+
+	if pending != 0 {
+		return
+	}
+
+	total := len(nodes)
+	for _, node := range nodes {
+		if total <= a.min {
+			break
+		}
+		if a.pool.reclaim(node, a.idle) {
+			total--
+			a.destroy(node)
+		}
+	}
+}
+
+// Provisioned returns the list of provisioned nodes
+// currently allocated to the pool.
+func (a *autoscaler) provisioned() []*model.Node {
+	var nodes []*model.Node
+	for _, node := range a.pool.list() {
+		if len(node.Machine) != 0 {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Create provisions a new node, registers the node in the
+// database and allocates the node to the pool.
+func (a *autoscaler) create() {
+	node, err := a.provider.Create()
+	if err != nil {
+		log.Errorf("error provisioning node. %s", err)
+		return
+	}
+
+	err = a.nodes.Create(node)
+	if err != nil {
+		log.Errorf("error registering provisioned node %s. %s", node.Machine, err)
+		a.destroy(node)
+		return
+	}
+
+	log.Infof("provisioned node %s at %s", node.Machine, node.Addr)
+	a.allocate(node)
+}
+
+// Destroy destroys the provisioned node and removes the node
+// from the database. The node must no longer be in the pool.
+func (a *autoscaler) destroy(node *model.Node) {
+	err := a.provider.Destroy(node)
+	if err != nil {
+		log.Errorf("error destroying node %s. %s", node.Machine, err)
+	} else {
+		log.Infof("destroyed idle node %s at %s", node.Machine, node.Addr)
+	}
+
+	if node.ID == 0 {
+		return
+	}
+	err = a.nodes.Delete(node)
+	if err != nil {
+		log.Errorf("error removing node %s from the database. %s", node.Machine, err)
+	}
+}
@@ -0,0 +1,210 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/store"
+	"github.com/franela/goblin"
+)
+
+func TestAutoscaler(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Autoscaler", func() {
+
+		var nodes *fakeNodes
+		var provider *fakeProvider
+		var scaler *autoscaler
+
+		// queue schedules a build that is waiting for a node.
+		queue := func() {
+			task := &Task{}
+			scaler.tracker.add(task)
+			scaler.tracker.queue(task)
+		}
+
+		g.BeforeEach(func() {
+			nodes = &fakeNodes{nodes: map[int64]*model.Node{}}
+			provider = &fakeProvider{machines: map[string]*model.Node{}}
+			p := newPool()
+			scaler = &autoscaler{
+				pool:     p,
+				allocate: func(n *model.Node) { p.allocate(n) },
+				tracker:  newTracker(),
+				nodes:    nodes,
+				provider: provider,
+				min:      0,
+				max:      2,
+				idle:     time.Hour,
+			}
+		})
+
+		g.It("Should not provision nodes for builds waiting for a concurrency group", func() {
+			scaler.tracker.add(&Task{})
+			scaler.scale()
+			g.Assert(provider.len()).Equal(0)
+		})
+
+		g.It("Should provision nodes for pending builds", func() {
+			queue()
+			scaler.scale()
+			g.Assert(provider.len()).Equal(1)
+			g.Assert(nodes.len()).Equal(1)
+			g.Assert(len(scaler.pool.list())).Equal(1)
+		})
+
+		g.It("Should not provision nodes when idle nodes are available", func() {
+			scaler.pool.allocate(&model.Node{Addr: "unix:///var/run/docker.sock"})
+			queue()
+			scaler.scale()
+			g.Assert(provider.len()).Equal(0)
+		})
+
+		g.It("Should not provision nodes while nodes are warming up", func() {
+			scaler.allocate = func(n *model.Node) { scaler.pool.allocateParked(n, parkWarmup) }
+			queue()
+			scaler.scale()
+			scaler.scale()
+			g.Assert(provider.len()).Equal(1)
+		})
+
+		g.It("Should provision nodes if warming nodes are low on disk", func() {
+			scaler.allocate = func(n *model.Node) {
+				scaler.pool.allocateParked(n, parkWarmup)
+				scaler.pool.park(n, parkDisk)
+			}
+			queue()
+			scaler.scale()
+			scaler.scale()
+			g.Assert(provider.len()).Equal(2)
+		})
+
+		g.It("Should not provision more than the maximum nodes", func() {
+			for i := 0; i < 5; i++ {
+				queue()
+			}
+			scaler.scale()
+			scaler.scale()
+			g.Assert(provider.len()).Equal(2)
+			g.Assert(len(scaler.pool.list())).Equal(2)
+		})
+
+		g.It("Should provision the minimum nodes", func() {
+			scaler.min = 1
+			scaler.scale()
+			g.Assert(provider.len()).Equal(1)
+		})
+
+		g.It("Should not provision nodes when draining", func() {
+			queue()
+			scaler.tracker.drain()
+			scaler.scale()
+			g.Assert(provider.len()).Equal(0)
+		})
+
+		g.It("Should destroy idle nodes", func() {
+			queue()
+			scaler.scale()
+			scaler.tracker = newTracker()
+			scaler.idle = 0
+			scaler.scale()
+			g.Assert(provider.len()).Equal(0)
+			g.Assert(nodes.len()).Equal(0)
+			g.Assert(len(scaler.pool.list())).Equal(0)
+		})
+
+		g.It("Should not destroy busy nodes", func() {
+			queue()
+			scaler.scale()
+			scaler.pool.claim(<-scaler.pool.reserve())
+			scaler.tracker = newTracker()
+			scaler.idle = 0
+			scaler.scale()
+			g.Assert(provider.len()).Equal(1)
+		})
+
+		g.It("Should not destroy the minimum nodes", func() {
+			scaler.min = 1
+			scaler.scale()
+			scaler.idle = 0
+			scaler.scale()
+			g.Assert(provider.len()).Equal(1)
+		})
+
+		g.It("Should not destroy registered nodes", func() {
+			scaler.pool.allocate(&model.Node{Addr: "unix:///var/run/docker.sock"})
+			scaler.idle = 0
+			scaler.scale()
+			g.Assert(len(scaler.pool.list())).Equal(1)
+		})
+	})
+}
+
+type fakeNodes struct {
+	store.NodeStore
+	sync.Mutex
+	count int64
+	nodes map[int64]*model.Node
+}
+
+func (s *fakeNodes) Create(node *model.Node) error {
+	s.Lock()
+	defer s.Unlock()
+	s.count++
+	node.ID = s.count
+	s.nodes[node.ID] = node
+	return nil
+}
+
+func (s *fakeNodes) Delete(node *model.Node) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.nodes, node.ID)
+	return nil
+}
+
+func (s *fakeNodes) len() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.nodes)
+}
+
+// fakeProvider is an in-memory node provisioner.
+type fakeProvider struct {
+	sync.Mutex
+	count    int
+	machines map[string]*model.Node
+}
+
+func (f *fakeProvider) Create() (*model.Node, error) {
+	f.Lock()
+	defer f.Unlock()
+	f.count++
+	node := &model.Node{
+		Machine: fmt.Sprintf("fake-%d", f.count),
+		Addr:    "unix:///var/run/docker.sock",
+		Arch:    "linux_amd64",
+	}
+	f.machines[node.Machine] = node
+	return node, nil
+}
+
+func (f *fakeProvider) Destroy(node *model.Node) error {
+	f.Lock()
+	defer f.Unlock()
+	if _, ok := f.machines[node.Machine]; !ok {
+		return fmt.Errorf("Machine %s not found", node.Machine)
+	}
+	delete(f.machines, node.Machine)
+	return nil
+}
+
+func (f *fakeProvider) len() int {
+	f.Lock()
+	defer f.Unlock()
+	return len(f.machines)
+}
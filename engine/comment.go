@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/store"
+	log "github.com/Sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// maxCommentLogs is the maximum number of log lines of each
+// failing job included in the pull request comment.
+const maxCommentLogs = 200
+
+// SetComment posts the summary of the finished build as a comment on
+// the pull request, if the repository enables it and the remote system
+// supports it.
+func (u *updater) SetComment(c context.Context, r *Task) {
+	if r.Build.Event != model.EventPull || !r.Repo.Comment {
+		return
+	}
+	commenter, ok := remote.FromContext(c).(remote.Commenter)
+	if !ok {
+		return
+	}
+
+	logs := func(job *model.Job) []byte {
+		rc, err := store.ReadLog(c, job)
+		if err != nil {
+			return nil
+		}
+		defer rc.Close()
+		out, _ := ioutil.ReadAll(rc)
+		return out
+	}
+
+	body := commentSummary(r, logs)
+	err := commenter.Comment(r.User, r.Repo, r.Build, body)
+	if err != nil {
+		log.Errorf("error commenting on pull request for %s/%d. %s", r.Repo.FullName, r.Build.Number, err)
+	}
+}
+
+// commentSummary returns the markdown summary of the build, with the
+// status and duration of each job and a link to the build. Failing jobs
+// also report the step that failed and, if the repository enables it,
+// the last lines of the job log.
+func commentSummary(r *Task, logs func(*model.Job) []byte) string {
+	link := fmt.Sprintf("%s/repos/%s/builds/%d", r.System.Link, r.Repo.FullName, r.Build.Number)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "**[Build #%d](%s) %s** in %s\n\n",
+		r.Build.Number,
+		link,
+		r.Build.Status,
+		duration(r.Build.Started, r.Build.Finished),
+	)
+
+	buf.WriteString("| Job | Status | Duration |\n")
+	buf.WriteString("| --- | --- | --- |\n")
+	for _, job := range r.Jobs {
+		fmt.Fprintf(&buf, "| [%s](%s) | %s | %s |\n",
+			escapeCell(job.Label()),
+			jobLink(r, job),
+			job.Status,
+			duration(job.Started, job.Finished),
+		)
+	}
+
+	lines := r.Repo.CommentLogs
+	if lines > maxCommentLogs {
+		lines = maxCommentLogs
+	}
+	for _, job := range r.Jobs {
+		if job.Status != model.StatusFailure && job.Status != model.StatusError {
+			continue
+		}
+		out := logs(job)
+
+		fmt.Fprintf(&buf, "\n**%s** failed", job.Label())
+		if step := failingStep(out); len(step) != 0 {
+			fmt.Fprintf(&buf, " at `%s`", step)
+		}
+		buf.WriteString("\n")
+
+		tail := tailLines(out, lines)
+		if len(tail) != 0 {
+			buf.WriteString("\n")
+			for _, line := range tail {
+				buf.WriteString("    " + line + "\n")
+			}
+		}
+	}
+	return buf.String()
+}
+
+// failingStep returns the last command echoed in the job log, which
+// is the build step that was running when the job failed.
+func failingStep(out []byte) string {
+	var step string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "$ ") {
+			step = strings.TrimPrefix(line, "$ ")
+		}
+	}
+	return strings.Replace(step, "`", "'", -1)
+}
+
+// tailLines returns the last n lines of the job log.
+func tailLines(out []byte, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(lines) == 1 && len(lines[0]) == 0 {
+		return nil
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// duration returns the time between the unix timestamps,
+// or a dash if the job or build did not run.
+func duration(started, finished int64) string {
+	if started == 0 || finished < started {
+		return "-"
+	}
+	return (time.Duration(finished-started) * time.Second).String()
+}
+
+// escapeCell escapes the pipe character, which
+// delimits the cells of a markdown table.
+func escapeCell(s string) string {
+	return strings.Replace(s, "|", "\\|", -1)
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/franela/goblin"
+)
+
+func TestComment(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Pull request comment", func() {
+
+		var task *Task
+		var out = map[int][]byte{
+			2: []byte("$ go build\n$ go test ./...\n--- FAIL: TestFoo\nFAIL\n"),
+		}
+		logs := func(job *model.Job) []byte {
+			return out[job.Number]
+		}
+
+		g.BeforeEach(func() {
+			task = &Task{
+				Repo:   &model.Repo{FullName: "octocat/hello-world", Comment: true},
+				Build:  &model.Build{Number: 3, Event: model.EventPull, Status: model.StatusFailure, Started: 100, Finished: 200},
+				System: &model.System{Link: "https://drone.example.com"},
+				Jobs: []*model.Job{
+					{Number: 1, Status: model.StatusSuccess, Started: 100, Finished: 130, Environment: map[string]string{"GO_VERSION": "1.5"}},
+					{Number: 2, Status: model.StatusFailure, Started: 100, Finished: 200, Environment: map[string]string{"GO_VERSION": "1.6"}},
+					{Number: 3, Status: model.StatusSkipped, Name: "deploy"},
+				},
+			}
+		})
+
+		g.It("Should summarize the build", func() {
+			body := commentSummary(task, logs)
+			g.Assert(strings.HasPrefix(body, "**[Build #3](https://drone.example.com/repos/octocat/hello-world/builds/3) failure** in 1m40s\n")).IsTrue()
+			g.Assert(strings.Contains(body, "| [GO_VERSION=1.5](https://drone.example.com/repos/octocat/hello-world/builds/3/1) | success | 30s |\n")).IsTrue()
+			g.Assert(strings.Contains(body, "| [GO_VERSION=1.6](https://drone.example.com/repos/octocat/hello-world/builds/3/2) | failure | 1m40s |\n")).IsTrue()
+			g.Assert(strings.Contains(body, "| [deploy](https://drone.example.com/repos/octocat/hello-world/builds/3/3) | skipped | - |\n")).IsTrue()
+		})
+
+		g.It("Should report the failing step", func() {
+			body := commentSummary(task, logs)
+			g.Assert(strings.Contains(body, "**GO_VERSION=1.6** failed at `go test ./...`\n")).IsTrue()
+			g.Assert(strings.Contains(body, "    FAIL")).IsFalse()
+		})
+
+		g.It("Should include the last lines of the failing log", func() {
+			task.Repo.CommentLogs = 2
+			body := commentSummary(task, logs)
+			g.Assert(strings.HasSuffix(body, "failed at `go test ./...`\n\n    --- FAIL: TestFoo\n    FAIL\n")).IsTrue()
+		})
+
+		g.It("Should find the failing step", func() {
+			g.Assert(failingStep(out[2])).Equal("go test ./...")
+			g.Assert(failingStep([]byte("Error launching build"))).Equal("")
+		})
+
+		g.It("Should tail the log", func() {
+			g.Assert(tailLines([]byte("a\nb\nc\n"), 2)).Equal([]string{"b", "c"})
+			g.Assert(tailLines([]byte("a\nb\nc\n"), 5)).Equal([]string{"a", "b", "c"})
+			g.Assert(tailLines([]byte("a\nb\nc\n"), 0) == nil).IsTrue()
+			g.Assert(tailLines(nil, 5) == nil).IsTrue()
+		})
+	})
+}
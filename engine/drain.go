@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	log "github.com/Sirupsen/logrus"
+)
+
+// tracker keeps a record of the builds that are currently scheduled
+// so that the engine can drain them before the process exits.
+type tracker struct {
+	sync.Mutex
+	wg    sync.WaitGroup
+	tasks map[*Task]*model.Node
+
+	// queued records the tasks that hold their concurrency
+	// group, if any, and are waiting for a node.
+	queued map[*Task]bool
+
+	// jobs records the nodes of the jobs that run in
+	// parallel on a node other than the node of the task.
+	jobs map[int64]*model.Node
+
+	// done is closed when the engine starts draining
+	// and stops accepting new work.
+	done chan struct{}
+
+	// abort is closed when the drain timeout expires
+	// and running jobs must be killed.
+	abort chan struct{}
+}
+
+func newTracker() *tracker {
+	return &tracker{
+		tasks:  make(map[*Task]*model.Node),
+		queued: make(map[*Task]bool),
+		jobs:   make(map[int64]*model.Node),
+		done:   make(chan struct{}),
+		abort:  make(chan struct{}),
+	}
+}
+
+// Add adds the task to the list of scheduled tasks. It returns
+// false if the engine is draining and the task was not added.
+func (t *tracker) add(task *Task) bool {
+	t.Lock()
+	defer t.Unlock()
+	if t.isDraining() {
+		return false
+	}
+	t.tasks[task] = nil
+	t.wg.Add(1)
+	return true
+}
+
+// Queue records that the task is waiting for a node. Tasks that
+// wait for their concurrency group are not yet waiting for a node.
+func (t *tracker) queue(task *Task) {
+	t.Lock()
+	t.queued[task] = true
+	t.Unlock()
+}
+
+// Assign records the node that was reserved to run the task.
+func (t *tracker) assign(task *Task, node *model.Node) {
+	t.Lock()
+	t.tasks[task] = node
+	t.Unlock()
+}
+
+// AssignJob records the node that was acquired to run the job.
+func (t *tracker) assignJob(job *model.Job, node *model.Node) {
+	t.Lock()
+	t.jobs[job.ID] = node
+	t.Unlock()
+}
+
+// RemoveJob removes the node that was acquired to run the job.
+func (t *tracker) removeJob(job *model.Job) {
+	t.Lock()
+	delete(t.jobs, job.ID)
+	t.Unlock()
+}
+
+// JobNode returns the node the job is running on, which is the
+// acquired node of the job, if any, or else the node of the task.
+func (t *tracker) jobNode(job *model.Job, node *model.Node) *model.Node {
+	t.Lock()
+	defer t.Unlock()
+	if n, ok := t.jobs[job.ID]; ok {
+		return n
+	}
+	return node
+}
+
+// Remove removes the task from the list of scheduled tasks.
+func (t *tracker) remove(task *Task) {
+	t.Lock()
+	delete(t.tasks, task)
+	delete(t.queued, task)
+	t.Unlock()
+	t.wg.Done()
+}
+
+// List returns the list of scheduled tasks and the node
+// each task is running on, if any.
+func (t *tracker) list() map[*Task]*model.Node {
+	t.Lock()
+	defer t.Unlock()
+
+	tasks := make(map[*Task]*model.Node, len(t.tasks))
+	for task, node := range t.tasks {
+		tasks[task] = node
+	}
+	return tasks
+}
+
+// Pending returns the number of scheduled tasks that are
+// waiting for a node.
+func (t *tracker) pending() int {
+	t.Lock()
+	defer t.Unlock()
+
+	var count int
+	for task, node := range t.tasks {
+		if node == nil && t.queued[task] {
+			count++
+		}
+	}
+	return count
+}
+
+// Shutdown stops accepting new tasks and waits for the scheduled
+// tasks to complete. If the tasks do not complete before the timeout
+// expires, running tasks are signaled to stop and the kill function
+// is invoked for each task and the node it is running on, if any.
+func (t *tracker) shutdown(timeout time.Duration, kill func(*Task, *model.Node)) {
+	t.drain()
+
+	tasks := t.list()
+	if len(tasks) == 0 {
+		return
+	}
+	log.Infof("draining %d scheduled builds", len(tasks))
+
+	if t.wait(timeout) {
+		log.Infof("drained all scheduled builds")
+		return
+	}
+
+	tasks = t.list()
+	log.Warnf("timeout draining builds. killing %d running builds", len(tasks))
+	t.kill()
+
+	for task, node := range tasks {
+		go kill(task, node)
+	}
+
+	if !t.wait(killTimeout) {
+		log.Errorf("timeout killing running builds")
+	}
+}
+
+// Drain stops accepting new tasks.
+func (t *tracker) drain() {
+	t.Lock()
+	defer t.Unlock()
+	if !t.isDraining() {
+		close(t.done)
+	}
+}
+
+// Kill signals running tasks to stop.
+func (t *tracker) kill() {
+	t.Lock()
+	defer t.Unlock()
+	if !t.isAborted() {
+		close(t.abort)
+	}
+}
+
+// Wait blocks until all scheduled tasks complete or the timeout
+// expires. It returns false if the timeout expired.
+func (t *tracker) wait(timeout time.Duration) bool {
+	waitc := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(waitc)
+	}()
+
+	select {
+	case <-waitc:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (t *tracker) isDraining() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *tracker) isAborted() bool {
+	select {
+	case <-t.abort:
+		return true
+	default:
+		return false
+	}
+}
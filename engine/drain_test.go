@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/franela/goblin"
+)
+
+func TestTracker(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Tracker", func() {
+
+		g.It("Should add and remove tasks", func() {
+			task := &Task{}
+			tracker := newTracker()
+			g.Assert(tracker.add(task)).Equal(true)
+			g.Assert(len(tracker.list())).Equal(1)
+			tracker.remove(task)
+			g.Assert(len(tracker.list())).Equal(0)
+		})
+
+		g.It("Should assign a node to a task", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			task := &Task{}
+			tracker := newTracker()
+			tracker.add(task)
+			tracker.queue(task)
+			g.Assert(tracker.pending()).Equal(1)
+			tracker.assign(task, n)
+			g.Assert(tracker.list()[task]).Equal(n)
+			g.Assert(tracker.pending()).Equal(0)
+		})
+
+		g.It("Should not count tasks waiting for a concurrency group", func() {
+			task := &Task{}
+			tracker := newTracker()
+			tracker.add(task)
+			g.Assert(tracker.pending()).Equal(0)
+			tracker.queue(task)
+			g.Assert(tracker.pending()).Equal(1)
+			tracker.remove(task)
+			g.Assert(tracker.pending()).Equal(0)
+		})
+
+		g.It("Should assign a node to a job", func() {
+			n1 := &model.Node{Addr: "tcp://node1:2376"}
+			n2 := &model.Node{Addr: "tcp://node2:2376"}
+			job := &model.Job{ID: 1}
+			tracker := newTracker()
+			g.Assert(tracker.jobNode(job, n1)).Equal(n1)
+			tracker.assignJob(job, n2)
+			g.Assert(tracker.jobNode(job, n1)).Equal(n2)
+			tracker.removeJob(job)
+			g.Assert(tracker.jobNode(job, n1)).Equal(n1)
+		})
+
+		g.It("Should not add tasks when draining", func() {
+			tracker := newTracker()
+			tracker.drain()
+			g.Assert(tracker.isDraining()).IsTrue()
+			g.Assert(tracker.add(&Task{})).Equal(false)
+			g.Assert(len(tracker.list())).Equal(0)
+		})
+
+		g.It("Should drain and kill more than once", func() {
+			tracker := newTracker()
+			tracker.drain()
+			tracker.drain()
+			tracker.kill()
+			tracker.kill()
+			g.Assert(tracker.isAborted()).IsTrue()
+		})
+
+		g.It("Should wait for running tasks", func() {
+			task := &Task{}
+			tracker := newTracker()
+			tracker.add(task)
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				tracker.remove(task)
+			}()
+			g.Assert(tracker.wait(time.Second)).IsTrue()
+		})
+
+		g.It("Should timeout waiting for running tasks", func() {
+			tracker := newTracker()
+			tracker.add(&Task{})
+			g.Assert(tracker.wait(10 * time.Millisecond)).IsFalse()
+		})
+	})
+}
@@ -11,12 +11,13 @@ import (
 	"runtime"
 	"time"
 
-	log "github.com/Sirupsen/logrus"
-	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/CiscoCloud/drone/engine/provision"
 	"github.com/CiscoCloud/drone/model"
 	"github.com/CiscoCloud/drone/shared/docker"
 	"github.com/CiscoCloud/drone/shared/envconfig"
 	"github.com/CiscoCloud/drone/store"
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/samalba/dockerclient"
 	"golang.org/x/net/context"
 )
@@ -29,6 +30,9 @@ type Engine interface {
 	Allocate(*model.Node) error
 	Subscribe(chan *Event)
 	Unsubscribe(chan *Event)
+	Drain(time.Duration)
+	Draining() bool
+	Stats() *Stats
 }
 
 var (
@@ -48,42 +52,93 @@ var (
 
 	// error when the system cannot find logs
 	errLogging = errors.New("Logs not available")
+
+	// maximum time to wait for running builds to stop
+	// after the drain timeout expires.
+	killTimeout = time.Minute
 )
 
 type engine struct {
-	bus     *eventbus
-	updater *updater
-	pool    *pool
-	envs    []string
+	bus      *eventbus
+	updater  *updater
+	pool     *pool
+	tracker  *tracker
+	affinity *affinity
+	gc       *collector
+	warm     *warmer
+	locks    *locker
+	envs     []string
+
+	// minDisk is the minimum free disk space in bytes
+	// a node must have to be given new work.
+	minDisk int64
+
+	// grace is the time to wait for the node that last ran
+	// the repository successfully before using any node.
+	grace time.Duration
 }
 
-// Load creates a new build engine, loaded with registered nodes from the
-// database. The registered nodes are added to the pool of nodes to immediately
-// start accepting workloads.
+// Load creates a new build engine using the engine driver specified in
+// the environment variables. The default docker engine is loaded with
+// registered nodes from the database.
 func Load(env envconfig.Env, s store.Store) Engine {
+	driver := env.String("ENGINE_DRIVER", "docker")
+
+	switch driver {
+	case "docker":
+		return loadDocker(env, s)
+	case "kubernetes":
+		return loadKubernetes(env)
+	default:
+		log.Fatalf("unknown engine driver %s", driver)
+	}
+	return nil
+}
+
+// loadDocker creates a new build engine, loaded with registered nodes from
+// the database. The registered nodes are added to the pool of nodes to
+// immediately start accepting workloads.
+func loadDocker(env envconfig.Env, s store.Store) Engine {
 	engine := &engine{}
 	engine.bus = newEventbus()
 	engine.pool = newPool()
-	engine.updater = &updater{engine.bus}
-
-	// quick fix to propogate HTTP_PROXY variables
-	// throughout the build environment.
-	var proxyVars = []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy"}
-	for _, proxyVar := range proxyVars {
-		proxyVal := env.Get(proxyVar)
-		if len(proxyVal) != 0 {
-			engine.envs = append(engine.envs, proxyVar+"="+proxyVal)
-		}
+	engine.tracker = newTracker()
+	engine.locks = newLocker()
+	engine.updater = &updater{bus: engine.bus}
+	engine.envs = proxyEnvs(env)
+	engine.minDisk = int64(env.Int("NODE_MIN_DISK_FREE", 0)) << 20
+	engine.affinity = newAffinity()
+	engine.gc = loadCollector(env)
+	engine.warm = loadWarmer(env)
+
+	// pull the image that measures free disk space with
+	// the warm-up images, if free disk space is checked.
+	if engine.minDisk != 0 {
+		engine.warm.images = append(engine.warm.images, diskImage)
 	}
 
+	// never remove the warm-up images from the nodes.
+	engine.gc.keep = append(engine.gc.keep, engine.warm.images...)
+	engine.grace = env.Duration("NODE_AFFINITY_TIMEOUT", 15*time.Second)
+
 	nodes, err := s.Nodes().GetList()
 	if err != nil {
 		log.Fatalf("failed to get nodes from database. %s", err)
 	}
 	for _, node := range nodes {
-		engine.pool.allocate(node)
+		engine.allocate(node)
 		log.Infof("registered docker daemon %s", node.Addr)
 	}
+	engine.warm.last = time.Now()
+
+	// refresh the node resources in the background.
+	go engine.monitor(s.Nodes(), env.Duration("NODE_REFRESH_INTERVAL", 5*time.Minute))
+
+	// provision nodes on demand if a provisioner is configured.
+	provider := provision.Load(env)
+	if provider != nil {
+		newAutoscaler(engine.pool, engine.tracker, s.Nodes(), provider, engine.allocate, env).start()
+	}
 
 	return engine
 }
@@ -136,8 +191,16 @@ func (e *engine) Allocate(node *model.Node) error {
 		return err
 	}
 
+	info, err := inspectNode(client)
+	if err != nil {
+		log.Warnf("error inspecting docker daemon %s. %s.", node.Addr, err)
+	} else {
+		e.measureDisk(client, node, info)
+		info.apply(node)
+	}
+
 	log.Infof("registered docker daemon %s running version %s", node.Addr, version.Version)
-	e.pool.allocate(node)
+	e.allocate(node)
 	return nil
 }
 
@@ -152,8 +215,90 @@ func (e *engine) Deallocate(n *model.Node) {
 	}
 }
 
+// Drain stops the engine from accepting new builds and waits for the
+// running builds to complete. Builds that are still waiting for a node
+// are marked as killed. If the running builds do not complete before
+// the timeout expires their containers are stopped and the unfinished
+// jobs are marked as killed.
+func (e *engine) Drain(timeout time.Duration) {
+	e.tracker.shutdown(timeout, func(task *Task, node *model.Node) {
+		for _, job := range task.Jobs {
+			n := e.tracker.jobNode(job, node)
+			if n != nil {
+				e.Cancel(task.Build.ID, job.ID, n)
+			}
+		}
+	})
+}
+
+// Draining returns true if the engine is draining and no
+// longer accepts new builds.
+func (e *engine) Draining() bool {
+	return e.tracker.isDraining()
+}
+
+// Stats returns the scheduler statistics.
+func (e *engine) Stats() *Stats {
+	return e.affinity.report()
+}
+
 func (e *engine) Schedule(c context.Context, req *Task) {
-	node := <-e.pool.reserve()
+	if !e.tracker.add(req) {
+		log.Infof("engine is draining. killing build %s/%d", req.Repo.FullName, req.Build.Number)
+		e.updater.KillBuild(c, req)
+		return
+	}
+	defer e.tracker.remove(req)
+
+	// wait for the concurrency group of the build, if any.
+	release, ok := e.locks.wait(c, req, e.updater, e.tracker.done)
+	if !ok {
+		return
+	}
+	defer release()
+	e.tracker.queue(req)
+
+	// prefer the node that last ran the repository successfully
+	// since it already has the build cache and images.
+	var node *model.Node
+	preferred := e.affinity.get(req.Repo.ID)
+	if preferred != nil && e.grace > 0 && e.pool.acquire(preferred, e.grace, e.tracker.done) {
+		if e.pool.hasDisk(preferred, e.minDisk) {
+			node = preferred
+		} else {
+			log.Warnf("docker daemon %s is low on disk space. skipping", preferred.Addr)
+			e.pool.park(preferred, parkDisk)
+		}
+	}
+	e.affinity.record(preferred != nil, node != nil)
+
+	// reserve the next available node, skipping nodes that
+	// were removed from the pool while waiting or that are
+	// low on disk space.
+	for node == nil && !e.tracker.isDraining() {
+		select {
+		case n := <-e.pool.reserve():
+			if !e.pool.claim(n) {
+				continue
+			}
+			if !e.pool.hasDisk(n, e.minDisk) {
+				log.Warnf("docker daemon %s is low on disk space. skipping", n.Addr)
+				e.pool.park(n, parkDisk)
+				continue
+			}
+			node = n
+		case <-e.tracker.done:
+		}
+	}
+	if e.tracker.isDraining() {
+		if node != nil {
+			e.pool.release(node)
+		}
+		log.Infof("engine is draining. killing queued build %s/%d", req.Repo.FullName, req.Build.Number)
+		e.updater.KillBuild(c, req)
+		return
+	}
+	e.tracker.assign(req, node)
 
 	// since we are probably running in a go-routine
 	// make sure we recover from any panics so that
@@ -172,6 +317,9 @@ func (e *engine) Schedule(c context.Context, req *Task) {
 	// update the node that was allocated to each job
 	func(id int64) {
 		for _, job := range req.Jobs {
+			if job.Status != model.StatusPending {
+				continue
+			}
 			job.NodeID = id
 			store.UpdateJob(c, job)
 		}
@@ -189,26 +337,50 @@ func (e *engine) Schedule(c context.Context, req *Task) {
 	req.Build.Status = model.StatusRunning
 	e.updater.SetBuild(c, req)
 
-	// run all bulid jobs
-	for _, job := range req.Jobs {
-		req.Job = job
-		e.runJob(c, req, e.updater, client)
+	// run all build jobs in dependency order. Named jobs that
+	// do not depend on each other run in parallel on any idle
+	// node in the pool.
+	run := func(client dockerclient.Client) runner {
+		return func(task *Task) {
+			if e.tracker.isAborted() {
+				e.updater.KillJob(c, task)
+				return
+			}
+			e.runJob(c, task, e.updater, client)
+		}
 	}
-
-	// update overall status based on each job
-	req.Build.Status = model.StatusSuccess
-	for _, job := range req.Jobs {
-		if job.Status != model.StatusSuccess {
-			req.Build.Status = job.Status
-			break
+	var acquire func() (runner, func())
+	if hasGraph(req.Jobs) {
+		acquire = func() (runner, func()) {
+			return e.acquireRunner(c, run)
 		}
 	}
+	runGraph(c, req, e.updater, run(client), acquire)
+	if len(req.Jobs) != 0 {
+		req.Job = req.Jobs[len(req.Jobs)-1]
+	}
+
+	// update overall status based on each job
+	req.Build.Status = buildStatus(req.Jobs)
 	req.Build.Finished = time.Now().UTC().Unix()
 	err = e.updater.SetBuild(c, req)
 	if err != nil {
 		log.Errorf("error updating build completion status. %s", err)
 	}
 
+	if req.Build.Status == model.StatusSuccess {
+		e.affinity.set(req.Repo.ID, node)
+	}
+
+	// skip notifications if the build was killed
+	// because the server is shutting down.
+	if e.tracker.isAborted() {
+		return
+	}
+
+	// summarize the build on the pull request.
+	e.updater.SetComment(c, req)
+
 	// run notifications
 	err = e.runJobNotify(req, client)
 	if err != nil {
@@ -216,6 +388,41 @@ func (e *engine) Schedule(c context.Context, req *Task) {
 	}
 }
 
+// acquireRunner claims an idle node from the pool, if one is
+// available, and returns a runner that runs jobs on the node and
+// a function to release the node.
+func (e *engine) acquireRunner(c context.Context, run func(dockerclient.Client) runner) (runner, func()) {
+	for {
+		select {
+		case n := <-e.pool.reserve():
+			if !e.pool.claim(n) {
+				continue
+			}
+			if !e.pool.hasDisk(n, e.minDisk) {
+				log.Warnf("docker daemon %s is low on disk space. skipping", n.Addr)
+				e.pool.park(n, parkDisk)
+				continue
+			}
+			client, err := newDockerClient(n.Addr, n.Cert, n.Key, n.CA)
+			if err != nil {
+				log.Errorln("error creating docker client", err)
+				e.pool.release(n)
+				return nil, nil
+			}
+			w := run(client)
+			return func(task *Task) {
+				e.tracker.assignJob(task.Job, n)
+				defer e.tracker.removeJob(task.Job)
+				task.Job.NodeID = n.ID
+				store.UpdateJob(c, task.Job)
+				w(task)
+			}, func() { e.pool.release(n) }
+		default:
+			return nil, nil
+		}
+	}
+}
+
 func newDockerClient(addr, cert, key, ca string) (dockerclient.Client, error) {
 	var tlc *tls.Config
 
@@ -329,6 +536,9 @@ func (e *engine) runJob(c context.Context, r *Task, updater *updater, client doc
 	info, builderr := docker.Wait(client, name)
 
 	switch {
+	case e.tracker.isAborted():
+		r.Job.ExitCode = 130
+		r.Job.Status = model.StatusKilled
 	case info.State.ExitCode == 128:
 		r.Job.ExitCode = info.State.ExitCode
 		r.Job.Status = model.StatusKilled
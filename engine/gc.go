@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/shared/docker"
+	"github.com/CiscoCloud/drone/shared/envconfig"
+	"github.com/samalba/dockerclient"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// collector removes unused images, stopped build containers and
+// dangling volumes from the nodes so that they do not run out of
+// disk space.
+type collector struct {
+	// interval is the time between collections. Garbage
+	// collection is disabled if the interval is zero.
+	interval time.Duration
+
+	// retention is the minimum age of images that
+	// can be removed.
+	retention time.Duration
+
+	// keep is a list of image name patterns that are
+	// never removed (ie golang, golang:1.5, plugins/*).
+	keep []string
+
+	// last is the time of the last collection.
+	last time.Time
+}
+
+// collection reports the resources removed from a node.
+type collection struct {
+	images     int
+	containers int
+	volumes    int
+	reclaimed  int64
+}
+
+func loadCollector(env envconfig.Env) *collector {
+	return &collector{
+		interval:  env.Duration("NODE_GC_INTERVAL", 0),
+		retention: env.Duration("NODE_GC_RETENTION", 7*24*time.Hour),
+		keep:      splitList(env.String("NODE_GC_KEEP", "")),
+	}
+}
+
+// Due returns true if the next collection is due.
+func (c *collector) due() bool {
+	return c.interval > 0 && time.Since(c.last) >= c.interval
+}
+
+// Collect removes stopped build containers, unused images older
+// than the retention period that are not in the keep-list, and
+// dangling volumes from the node.
+func (c *collector) collect(client dockerclient.Client) (*collection, error) {
+	out := new(collection)
+
+	// remove stopped build containers first so that
+	// their images and volumes can be removed.
+	filters := url.QueryEscape(`{"status":["exited","created"]}`)
+	containers, err := client.ListContainers(true, true, filters)
+	if err != nil {
+		return nil, err
+	}
+	for _, container := range containers {
+		if !isBuildContainer(container) || strings.HasPrefix(container.Status, "Up") {
+			continue
+		}
+		err = client.RemoveContainer(container.Id, false, true)
+		if err != nil {
+			log.Warnf("error removing container %s. %s", container.Id, err)
+			continue
+		}
+		out.containers++
+		out.reclaimed += container.SizeRw
+	}
+
+	// images used by the remaining containers,
+	// by name and by identifier.
+	containers, err = client.ListContainers(true, false, "")
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for _, container := range containers {
+		used[container.Image] = true
+	}
+
+	images, err := client.ListImages(false)
+	if err != nil {
+		return nil, err
+	}
+	created := time.Now().Add(-c.retention).Unix()
+	for _, image := range images {
+		if image.Created > created || c.isUsed(image, used) || c.isKept(image) {
+			continue
+		}
+		if c.removeImage(client, image) {
+			out.images++
+			out.reclaimed += image.Size
+		}
+	}
+
+	volumes, err := docker.DanglingVolumes(client)
+	if err != nil {
+		// older docker daemons do not implement
+		// the volumes API.
+		log.Debugf("error listing dangling volumes. %s", err)
+		return out, nil
+	}
+	for _, volume := range volumes {
+		err = docker.RemoveVolume(client, volume.Name)
+		if err != nil {
+			log.Warnf("error removing volume %s. %s", volume.Name, err)
+			continue
+		}
+		out.volumes++
+	}
+	return out, nil
+}
+
+// removeImage removes each tag of the image, or the image by
+// identifier if untagged. It returns true if the image was removed.
+func (c *collector) removeImage(client dockerclient.Client, image *dockerclient.Image) bool {
+	names := tags(image)
+	if len(names) == 0 {
+		names = []string{image.Id}
+	}
+	for _, name := range names {
+		_, err := client.RemoveImage(name)
+		if err != nil {
+			log.Warnf("error removing image %s. %s", name, err)
+			return false
+		}
+	}
+	return true
+}
+
+// isUsed returns true if a container uses the image.
+func (c *collector) isUsed(image *dockerclient.Image, used map[string]bool) bool {
+	if used[image.Id] {
+		return true
+	}
+	for _, name := range tags(image) {
+		if used[name] || used[strings.TrimSuffix(name, ":latest")] {
+			return true
+		}
+	}
+	return false
+}
+
+// isKept returns true if the image is the build agent or
+// matches a pattern in the keep-list. A pattern without a
+// tag matches all tags of the image.
+func (c *collector) isKept(image *dockerclient.Image) bool {
+	for _, name := range tags(image) {
+		if name == DefaultAgent {
+			return true
+		}
+		repo := name
+		if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+			repo = name[:i]
+		}
+		for _, pattern := range c.keep {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, repo); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tags returns the image tags, excluding the <none>:<none>
+// tag of dangling images.
+func tags(image *dockerclient.Image) []string {
+	var names []string
+	for _, name := range image.RepoTags {
+		if name != "<none>:<none>" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// isBuildContainer returns true if the container was
+// created by the engine to run a build.
+func isBuildContainer(container dockerclient.Container) bool {
+	for _, name := range container.Names {
+		if strings.HasPrefix(strings.TrimPrefix(name, "/"), "drone_") {
+			return true
+		}
+	}
+	return false
+}
+
+// collect removes unused resources from the node if the node
+// is idle or parked because it is low on disk space. An idle node
+// is held out of the pool while collecting.
+func (e *engine) collect(node *model.Node) {
+	if !e.pool.isParked(node) {
+		if !e.pool.claim(node) {
+			log.Debugf("docker daemon %s is busy. skipping garbage collection", node.Addr)
+			return
+		}
+		defer e.pool.release(node)
+	}
+
+	client, err := newDockerClient(node.Addr, node.Cert, node.Key, node.CA)
+	if err != nil {
+		log.Errorf("error creating docker client %s. %s.", node.Addr, err)
+		return
+	}
+	out, err := e.gc.collect(client)
+	if err != nil {
+		log.Warnf("error collecting garbage on docker daemon %s. %s.", node.Addr, err)
+		return
+	}
+	e.pool.collected(node, out.reclaimed)
+
+	log.Infof("reclaimed %d bytes on docker daemon %s. removed %d images, %d containers and %d volumes",
+		out.reclaimed, node.Addr, out.images, out.containers, out.volumes)
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+	"github.com/samalba/dockerclient"
+)
+
+func TestCollector(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Garbage collector", func() {
+
+		var client *fakeDocker
+		var gc *collector
+
+		old := time.Now().Add(-30 * 24 * time.Hour).Unix()
+		recent := time.Now().Unix()
+
+		g.BeforeEach(func() {
+			client = &fakeDocker{}
+			gc = &collector{
+				retention: 7 * 24 * time.Hour,
+				keep:      []string{"golang", "plugins/*"},
+			}
+		})
+
+		g.It("Should remove unused images older than the retention", func() {
+			client.images = []*dockerclient.Image{
+				{Id: "1", Created: old, Size: 100, RepoTags: []string{"node:0.12"}},
+				{Id: "2", Created: old, Size: 200, RepoTags: []string{"<none>:<none>"}},
+				{Id: "3", Created: recent, Size: 300, RepoTags: []string{"node:5"}},
+			}
+			out, err := gc.collect(client)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(out.images).Equal(2)
+			g.Assert(out.reclaimed).Equal(int64(300))
+			g.Assert(client.removed).Equal([]string{"node:0.12", "2"})
+		})
+
+		g.It("Should not remove images in the keep-list", func() {
+			client.images = []*dockerclient.Image{
+				{Id: "1", Created: old, RepoTags: []string{"golang:1.5"}},
+				{Id: "2", Created: old, RepoTags: []string{"plugins/drone-slack:latest"}},
+				{Id: "3", Created: old, RepoTags: []string{DefaultAgent}},
+			}
+			out, _ := gc.collect(client)
+			g.Assert(out.images).Equal(0)
+			g.Assert(len(client.removed)).Equal(0)
+		})
+
+		g.It("Should not remove images used by containers", func() {
+			client.images = []*dockerclient.Image{
+				{Id: "1", Created: old, RepoTags: []string{"redis:latest"}},
+				{Id: "2", Created: old, RepoTags: []string{"postgres:9.4"}},
+			}
+			client.containers = []dockerclient.Container{
+				{Id: "a", Names: []string{"/redis"}, Image: "redis", Status: "Up 2 hours"},
+				{Id: "b", Names: []string{"/postgres"}, Image: "2", Status: "Exited (0) 2 hours ago"},
+			}
+			out, _ := gc.collect(client)
+			g.Assert(out.images).Equal(0)
+		})
+
+		g.It("Should remove stopped build containers", func() {
+			client.containers = []dockerclient.Container{
+				{Id: "a", Names: []string{"/drone_build_1_job_2"}, Status: "Exited (1) 2 days ago", SizeRw: 50},
+				{Id: "b", Names: []string{"/drone_build_3_job_4"}, Status: "Up 2 hours"},
+				{Id: "c", Names: []string{"/redis"}, Status: "Exited (0) 2 days ago"},
+			}
+			out, _ := gc.collect(client)
+			g.Assert(out.containers).Equal(1)
+			g.Assert(out.reclaimed).Equal(int64(50))
+			g.Assert(client.removed).Equal([]string{"a"})
+		})
+
+		g.It("Should be due after the interval", func() {
+			g.Assert(gc.due()).IsFalse()
+			gc.interval = time.Hour
+			g.Assert(gc.due()).IsTrue()
+			gc.last = time.Now()
+			g.Assert(gc.due()).IsFalse()
+		})
+	})
+}
+
+// fakeDocker is a fake docker client that records
+// the removed images and containers.
+type fakeDocker struct {
+	dockerclient.Client
+	images     []*dockerclient.Image
+	containers []dockerclient.Container
+	removed    []string
+}
+
+func (f *fakeDocker) ListImages(all bool) ([]*dockerclient.Image, error) {
+	return f.images, nil
+}
+
+func (f *fakeDocker) ListContainers(all, size bool, filters string) ([]dockerclient.Container, error) {
+	var containers []dockerclient.Container
+	for _, container := range f.containers {
+		if !f.isRemoved(container.Id) {
+			containers = append(containers, container)
+		}
+	}
+	return containers, nil
+}
+
+func (f *fakeDocker) RemoveImage(name string) ([]*dockerclient.ImageDelete, error) {
+	f.removed = append(f.removed, name)
+	return nil, nil
+}
+
+func (f *fakeDocker) RemoveContainer(id string, force, volumes bool) error {
+	f.removed = append(f.removed, id)
+	return nil
+}
+
+func (f *fakeDocker) isRemoved(id string) bool {
+	for _, removed := range f.removed {
+		if removed == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	"golang.org/x/net/context"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// runner runs the job of the task to completion.
+type runner func(*Task)
+
+// graph tracks the progress of the build jobs so that each job
+// starts once the jobs it depends on completed successfully.
+type graph struct {
+	jobs    map[int]*model.Job
+	started map[int]bool
+	done    map[int]bool
+}
+
+func newGraph(jobs []*model.Job) *graph {
+	g := &graph{
+		jobs:    map[int]*model.Job{},
+		started: map[int]bool{},
+		done:    map[int]bool{},
+	}
+	for _, job := range jobs {
+		g.jobs[job.Number] = job
+
+		// jobs that completed in a previous run of a
+		// partially restarted build are not run again.
+		if job.Status != model.StatusPending {
+			g.started[job.Number] = true
+			g.done[job.Number] = true
+		}
+	}
+	return g
+}
+
+// Ready returns true if the job has not started and the jobs
+// it depends on completed successfully.
+func (g *graph) ready(job *model.Job) bool {
+	if g.started[job.Number] {
+		return false
+	}
+	for _, dep := range job.DependsOn {
+		if !g.done[dep] || g.jobs[dep].Status != model.StatusSuccess {
+			return false
+		}
+	}
+	return true
+}
+
+// Blocked returns true if the job has not started and a job
+// it depends on, or a missing job, did not complete successfully.
+func (g *graph) blocked(job *model.Job) bool {
+	if g.started[job.Number] {
+		return false
+	}
+	for _, dep := range job.DependsOn {
+		upstream, ok := g.jobs[dep]
+		if !ok || (g.done[dep] && upstream.Status != model.StatusSuccess) {
+			return true
+		}
+	}
+	return false
+}
+
+// runGraph runs the jobs of the build in dependency order. Jobs that
+// are ready start as soon as a runner is available. The first runner
+// is always available, and acquire returns an additional runner and a
+// function to release it, or nil if no additional runner is available.
+// Jobs that depend on a job that did not complete successfully are
+// skipped. Each runner updates a copy of its job, which is copied to
+// the build jobs by the updater, since jobs may run in parallel.
+func runGraph(c context.Context, req *Task, u *updater, first runner, acquire func() (runner, func())) {
+	type result struct {
+		job     *model.Job
+		release func()
+	}
+
+	g := newGraph(req.Jobs)
+	results := make(chan *result)
+	firstFree := true
+	running := 0
+
+	for {
+		for _, job := range req.Jobs {
+			if g.blocked(job) {
+				g.started[job.Number] = true
+				g.done[job.Number] = true
+				skipJob(c, req, u, job)
+			}
+		}
+
+		for _, job := range req.Jobs {
+			if !g.ready(job) {
+				continue
+			}
+
+			var w runner
+			var release func()
+			switch {
+			case firstFree:
+				w, firstFree = first, false
+			case acquire != nil:
+				w, release = acquire()
+			}
+			if w == nil {
+				break
+			}
+
+			g.started[job.Number] = true
+			running++
+
+			task := *req
+			task.Job = new(model.Job)
+			*task.Job = *job
+			go func(w runner, release func()) {
+				defer func() {
+					if err := recover(); err != nil {
+						const size = 64 << 10
+						buf := make([]byte, size)
+						buf = buf[:runtime.Stack(buf, false)]
+						log.Errorf("panic running job: %v\n%s", err, string(buf))
+					}
+					results <- &result{task.Job, release}
+				}()
+				w(&task)
+			}(w, release)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		res := <-results
+		running--
+		u.syncJob(req, res.job)
+		g.done[res.job.Number] = true
+		if res.release != nil {
+			res.release()
+		} else {
+			firstFree = true
+		}
+	}
+
+	// jobs that were never started because of missing
+	// or circular dependencies are skipped.
+	for _, job := range req.Jobs {
+		if !g.started[job.Number] {
+			skipJob(c, req, u, job)
+		}
+	}
+}
+
+// hasGraph returns true if the build declares named jobs, in
+// which case independent jobs may run in parallel.
+func hasGraph(jobs []*model.Job) bool {
+	for _, job := range jobs {
+		if len(job.Name) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// skipJob marks the job as skipped.
+func skipJob(c context.Context, req *Task, u *updater, job *model.Job) {
+	task := *req
+	task.Job = new(model.Job)
+	*task.Job = *job
+	task.Job.Status = model.StatusSkipped
+	task.Job.Started = time.Now().UTC().Unix()
+	task.Job.Finished = time.Now().UTC().Unix()
+	u.SetJob(c, &task)
+	u.syncJob(req, task.Job)
+}
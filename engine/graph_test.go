@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/franela/goblin"
+)
+
+func TestGraph(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Job graph", func() {
+
+		var task *Task
+		var u *updater
+		var order []int
+		var mu sync.Mutex
+
+		// run returns a runner that completes the job
+		// with the status, recording the job order.
+		run := func(status string) runner {
+			return func(t *Task) {
+				mu.Lock()
+				order = append(order, t.Job.Number)
+				mu.Unlock()
+				t.Job.Status = status
+			}
+		}
+
+		g.BeforeEach(func() {
+			order = nil
+			u = &updater{bus: newEventbus()}
+			task = fakeTask()
+			task.Jobs = []*model.Job{
+				{ID: 1, Number: 1, Name: "build", Status: model.StatusPending},
+				{ID: 2, Number: 2, Name: "test", DependsOn: []int{1}, Status: model.StatusPending},
+				{ID: 3, Number: 3, Name: "deploy", DependsOn: []int{2}, Status: model.StatusPending},
+			}
+		})
+
+		g.It("Should run jobs in dependency order", func() {
+			task.Jobs[0].DependsOn = []int{3}
+			task.Jobs[2].DependsOn = nil
+			c, _ := fakeContext()
+			runGraph(c, task, u, run(model.StatusSuccess), nil)
+			g.Assert(order).Equal([]int{3, 1, 2})
+		})
+
+		g.It("Should skip jobs that depend on a failed job", func() {
+			c, _ := fakeContext()
+			runGraph(c, task, u, run(model.StatusFailure), nil)
+			g.Assert(order).Equal([]int{1})
+			g.Assert(task.Jobs[1].Status).Equal(model.StatusSkipped)
+			g.Assert(task.Jobs[2].Status).Equal(model.StatusSkipped)
+			g.Assert(buildStatus(task.Jobs)).Equal(model.StatusFailure)
+		})
+
+		g.It("Should not run jobs that completed in a previous run", func() {
+			task.Jobs[0].Status = model.StatusSuccess
+			c, _ := fakeContext()
+			runGraph(c, task, u, run(model.StatusSuccess), nil)
+			g.Assert(order).Equal([]int{2, 3})
+			g.Assert(buildStatus(task.Jobs)).Equal(model.StatusSuccess)
+		})
+
+		g.It("Should ignore jobs that are allowed to fail", func() {
+			task.Jobs[0].Status = model.StatusSuccess
+			task.Jobs[1].Status = model.StatusFailure
+			task.Jobs[1].AllowFailure = true
+			task.Jobs[2].Status = model.StatusSkipped
+			g.Assert(buildStatus(task.Jobs)).Equal(model.StatusSuccess)
+		})
+
+		g.It("Should skip jobs with missing dependencies", func() {
+			task.Jobs[1].DependsOn = []int{4}
+			c, _ := fakeContext()
+			runGraph(c, task, u, run(model.StatusSuccess), nil)
+			g.Assert(order).Equal([]int{1})
+			g.Assert(task.Jobs[2].Status).Equal(model.StatusSkipped)
+		})
+
+		g.It("Should run independent jobs in parallel", func() {
+			task.Jobs[2].DependsOn = []int{1}
+
+			// the dependent jobs block until both are
+			// running, which requires an acquired runner.
+			c, _ := fakeContext()
+			var wg sync.WaitGroup
+			wg.Add(2)
+			parallel := func(t *Task) {
+				if t.Job.Number != 1 {
+					wg.Done()
+					wg.Wait()
+				}
+				t.Job.Status = model.StatusSuccess
+				u.SetJob(c, t)
+			}
+			acquired := false
+			acquire := func() (runner, func()) {
+				if acquired {
+					return nil, nil
+				}
+				acquired = true
+				return parallel, func() { acquired = false }
+			}
+			runGraph(c, task, u, parallel, acquire)
+			g.Assert(buildStatus(task.Jobs)).Equal(model.StatusSuccess)
+		})
+	})
+}
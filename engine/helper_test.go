@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/store"
+	"golang.org/x/net/context"
+)
+
+// fakeContext returns a context with an in-memory store and a
+// remote that records the build status updates.
+func fakeContext() (context.Context, *fakeStore) {
+	s := &fakeStore{logs: map[int64]string{}}
+	c := context.WithValue(context.Background(), "store", store.New(
+		"fake",
+		nil,
+		nil,
+		nil,
+		nil,
+		&fakeBuilds{fakeStore: s},
+		&fakeJobs{fakeStore: s},
+		&fakeLogs{fakeStore: s},
+		nil,
+		nil,
+	))
+	c = context.WithValue(c, "remote", &fakeRemote{fakeStore: s})
+	return c, s
+}
+
+type fakeStore struct {
+	sync.Mutex
+	logs        map[int64]string
+	statuses    []string
+	jobStatuses []string
+	jobLinks    []string
+}
+
+type fakeBuilds struct {
+	store.BuildStore
+	*fakeStore
+}
+
+func (s *fakeBuilds) Update(*model.Build) error { return nil }
+
+type fakeJobs struct {
+	store.JobStore
+	*fakeStore
+}
+
+func (s *fakeJobs) Update(*model.Job) error { return nil }
+
+type fakeLogs struct {
+	store.LogStore
+	*fakeStore
+}
+
+func (s *fakeLogs) Write(job *model.Job, r io.Reader) error {
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	s.Lock()
+	s.logs[job.ID] = buf.String()
+	s.Unlock()
+	return nil
+}
+
+type fakeRemote struct {
+	remote.Remote
+	*fakeStore
+}
+
+func (r *fakeRemote) Status(u *model.User, repo *model.Repo, b *model.Build, link string) error {
+	r.Lock()
+	r.statuses = append(r.statuses, b.Status)
+	r.Unlock()
+	return nil
+}
+
+func (r *fakeRemote) JobStatus(u *model.User, repo *model.Repo, b *model.Build, j *model.Job, link string) error {
+	r.Lock()
+	r.jobStatuses = append(r.jobStatuses, j.Status)
+	r.jobLinks = append(r.jobLinks, link)
+	r.Unlock()
+	return nil
+}
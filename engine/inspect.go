@@ -0,0 +1,240 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/shared/docker"
+	"github.com/CiscoCloud/drone/store"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/samalba/dockerclient"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// diskImage is the image of the helper container that measures
+// the free disk space of nodes with storage drivers that do not
+// report it.
+const diskImage = "busybox:latest"
+
+// nodeInfo stores the resources reported by a docker daemon.
+type nodeInfo struct {
+	version    string
+	driver     string
+	root       string
+	cpus       int64
+	memory     int64
+	containers int64
+	diskFree   int64
+}
+
+// apply copies the resources to the node.
+func (i *nodeInfo) apply(node *model.Node) {
+	node.Version = i.version
+	node.Driver = i.driver
+	node.CPUs = i.cpus
+	node.Memory = i.memory
+	node.Containers = i.containers
+	node.DiskFree = i.diskFree
+	node.Updated = time.Now().UTC().Unix()
+}
+
+// inspectNode returns the resources reported by the docker daemon.
+func inspectNode(client dockerclient.Client) (*nodeInfo, error) {
+	version, err := client.Version()
+	if err != nil {
+		return nil, err
+	}
+	info, err := client.Info()
+	if err != nil {
+		return nil, err
+	}
+	containers, err := client.ListContainers(false, false, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &nodeInfo{
+		version:    version.Version,
+		driver:     info.Driver,
+		root:       info.DockerRootDir,
+		cpus:       info.NCPU,
+		memory:     info.MemTotal,
+		containers: int64(len(containers)),
+		diskFree:   diskFree(info.DriverStatus),
+	}, nil
+}
+
+// diskFree returns the available disk space reported by the storage
+// driver, or zero if the storage driver does not report it.
+func diskFree(status [][]string) int64 {
+	for _, pair := range status {
+		if len(pair) == 2 && pair[0] == "Data Space Available" {
+			return parseSize(pair[1])
+		}
+	}
+	return 0
+}
+
+// parseSize parses a size reported by the docker daemon in decimal
+// units (ie 10.5 GB) and returns the size in bytes, or zero if the
+// size cannot be parsed.
+func parseSize(size string) int64 {
+	parts := strings.Fields(size)
+	if len(parts) != 2 {
+		return 0
+	}
+	value, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0
+	}
+	for i, unit := range []string{"B", "kB", "MB", "GB", "TB", "PB"} {
+		if strings.EqualFold(parts[1], unit) {
+			return int64(value * math.Pow(1000, float64(i)))
+		}
+	}
+	return 0
+}
+
+// measureDisk measures the free disk space of the docker root
+// directory with a helper container, if the node must be checked
+// for free disk space and the storage driver does not report it,
+// ie overlay or aufs. A warning is logged if the free disk space
+// remains unknown, since the node is then never skipped.
+func (e *engine) measureDisk(client dockerclient.Client, node *model.Node, info *nodeInfo) {
+	if e.minDisk == 0 || info.diskFree != 0 {
+		return
+	}
+	free, err := statDisk(client, info.root)
+	if err != nil {
+		log.Warnf("free disk space of docker daemon %s is unknown. %s", node.Addr, err)
+		return
+	}
+	info.diskFree = free
+}
+
+// statDisk runs the helper container with the docker root directory
+// mounted, and returns the free disk space in bytes reported by df.
+func statDisk(client dockerclient.Client, root string) (int64, error) {
+	if len(root) == 0 {
+		return 0, fmt.Errorf("Docker root directory is not reported")
+	}
+	conf := &dockerclient.ContainerConfig{
+		Image: diskImage,
+		Cmd:   []string{"df", "-Pk", "/docker"},
+		HostConfig: dockerclient.HostConfig{
+			Binds: []string{root + ":/docker:ro"},
+		},
+		Volumes: map[string]struct{}{
+			"/docker": struct{}{},
+		},
+	}
+	info, err := docker.RunDaemon(client, conf, "")
+	if err != nil {
+		return 0, err
+	}
+	defer client.RemoveContainer(info.Id, true, true)
+
+	_, err = docker.Wait(client, info.Id)
+	if err != nil {
+		return 0, err
+	}
+	rc, err := client.ContainerLogs(info.Id, docker.LogOpts)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	var stdout bytes.Buffer
+	_, err = stdcopy.StdCopy(&stdout, ioutil.Discard, rc)
+	if err != nil {
+		return 0, err
+	}
+	return parseDf(stdout.Bytes())
+}
+
+// parseDf parses the output of df -Pk and returns the available
+// disk space of the filesystem in bytes.
+func parseDf(out []byte) (int64, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Scan() // skip the header
+	if !scanner.Scan() {
+		return 0, fmt.Errorf("Unable to parse df output")
+	}
+	fields := strings.Fields(scanner.Text())
+	if len(fields) < 4 {
+		return 0, fmt.Errorf("Unable to parse df output")
+	}
+	available, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Unable to parse df output. %s", err)
+	}
+	return available << 10, nil
+}
+
+// monitor refreshes the resources of each node in the pool at the
+// specified interval until the engine starts draining, pulling the
+// warm-up images and collecting garbage on each node when due. Nodes
+// that were parked because they were low on disk are returned to the
+// pool once enough disk space is available.
+func (e *engine) monitor(nodes store.NodeStore, interval time.Duration) {
+	for {
+		if e.warm.due() {
+			for _, node := range e.pool.list() {
+				go e.warmup(node)
+			}
+			e.warm.last = time.Now()
+		}
+
+		collect := e.gc.due()
+		for _, node := range e.pool.list() {
+			if collect {
+				e.collect(node)
+			}
+			e.refresh(nodes, node)
+		}
+		if collect {
+			e.gc.last = time.Now()
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-e.tracker.done:
+			return
+		}
+	}
+}
+
+// refresh refreshes and stores the resources of the node.
+func (e *engine) refresh(nodes store.NodeStore, node *model.Node) {
+	client, err := newDockerClient(node.Addr, node.Cert, node.Key, node.CA)
+	if err != nil {
+		log.Errorf("error creating docker client %s. %s.", node.Addr, err)
+		return
+	}
+	info, err := inspectNode(client)
+	if err != nil {
+		log.Warnf("error inspecting docker daemon %s. %s.", node.Addr, err)
+		return
+	}
+	e.measureDisk(client, node, info)
+	e.pool.update(node, info)
+
+	if node.ID != 0 {
+		err = nodes.Update(node)
+		if err != nil {
+			log.Errorf("error updating node %s. %s.", node.Addr, err)
+		}
+	}
+
+	if e.pool.hasDisk(node, e.minDisk) && e.pool.unpark(node, parkDisk) {
+		log.Infof("docker daemon %s has enough disk space to accept builds", node.Addr)
+	}
+}
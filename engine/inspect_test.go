@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/franela/goblin"
+)
+
+func TestInspect(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Node resources", func() {
+
+		g.It("Should parse free disk space", func() {
+			status := [][]string{
+				{"Pool Name", "docker-202:1-1032-pool"},
+				{"Data Space Used", "1.2 GB"},
+				{"Data Space Available", "10.5 GB"},
+			}
+			g.Assert(diskFree(status)).Equal(int64(10500000000))
+		})
+
+		g.It("Should ignore unreported free disk space", func() {
+			status := [][]string{
+				{"Backing Filesystem", "extfs"},
+			}
+			g.Assert(diskFree(status)).Equal(int64(0))
+			g.Assert(diskFree(nil)).Equal(int64(0))
+		})
+
+		g.It("Should parse the df output", func() {
+			out := []byte("Filesystem           1024-blocks    Used Available Capacity Mounted on\n/dev/sda1             51475068 20000000  31475068  39% /docker\n")
+			free, err := parseDf(out)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(free).Equal(int64(31475068 << 10))
+		})
+
+		g.It("Should fail to parse invalid df output", func() {
+			_, err := parseDf([]byte("df: /docker: No such file or directory\n"))
+			g.Assert(err != nil).IsTrue()
+			_, err = parseDf(nil)
+			g.Assert(err != nil).IsTrue()
+		})
+
+		g.It("Should apply resources to the node", func() {
+			n := &model.Node{}
+			info := &nodeInfo{
+				version:    "1.9.1",
+				driver:     "overlay",
+				cpus:       4,
+				memory:     8000000000,
+				containers: 2,
+				diskFree:   1000,
+			}
+			newPool().update(n, info)
+			g.Assert(n.Version).Equal("1.9.1")
+			g.Assert(n.Driver).Equal("overlay")
+			g.Assert(n.CPUs).Equal(int64(4))
+			g.Assert(n.Memory).Equal(int64(8000000000))
+			g.Assert(n.Containers).Equal(int64(2))
+			g.Assert(n.DiskFree).Equal(int64(1000))
+			g.Assert(n.Updated != 0).IsTrue()
+		})
+	})
+}
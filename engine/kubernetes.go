@@ -0,0 +1,538 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/shared/envconfig"
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/stdcopy"
+	"golang.org/x/net/context"
+)
+
+const (
+	// default location of the service account credentials
+	// when running inside a Kubernetes cluster.
+	kubeTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	kubeCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// error when registering nodes with the kubernetes engine.
+var errKubeNodes = errors.New("Nodes are not supported by the kubernetes engine")
+
+// kubeEngine is a build engine that runs each job as a pod in a
+// Kubernetes cluster instead of a registered docker node.
+type kubeEngine struct {
+	bus     *eventbus
+	updater *updater
+	tracker *tracker
+	locks   *locker
+	client  *kubeClient
+	envs    []string
+
+	// resource limits and node selector applied to
+	// each build pod.
+	limits   map[string]string
+	selector map[string]string
+
+	// interval at which pod status is polled while
+	// waiting for the pod to exit.
+	interval time.Duration
+}
+
+// loadKubernetes creates a new build engine that runs builds in the
+// Kubernetes cluster specified in the ENGINE_CONFIG connection string.
+// If no connection string is provided the in-cluster service account
+// is used.
+func loadKubernetes(env envconfig.Env) Engine {
+	config := env.String("ENGINE_CONFIG", "")
+	if len(config) == 0 {
+		config = fmt.Sprintf("https://%s?token_file=%s&ca_file=%s",
+			net.JoinHostPort(
+				env.Get("KUBERNETES_SERVICE_HOST"),
+				env.Get("KUBERNETES_SERVICE_PORT"),
+			),
+			kubeTokenFile,
+			kubeCAFile,
+		)
+	}
+
+	// parse the engine DSN configuration string
+	url_, err := url.Parse(config)
+	if err != nil {
+		log.Fatalf("unable to parse engine dsn. %s", err)
+	}
+	params := url_.Query()
+	url_.RawQuery = ""
+
+	token := params.Get("token")
+	if file := params.Get("token_file"); len(file) != 0 {
+		raw, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatalf("unable to read kubernetes token file. %s", err)
+		}
+		token = strings.TrimSpace(string(raw))
+	}
+	var ca []byte
+	if file := params.Get("ca_file"); len(file) != 0 {
+		ca, err = ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatalf("unable to read kubernetes ca file. %s", err)
+		}
+	}
+	skipVerify, _ := strconv.ParseBool(params.Get("skip_verify"))
+
+	namespace := params.Get("namespace")
+	if len(namespace) == 0 {
+		namespace = "default"
+	}
+
+	engine := newKubeEngine(newKubeClient(url_.String(), token, namespace, newKubeTLSConfig(ca, skipVerify)))
+	engine.envs = proxyEnvs(env)
+	if cpu := params.Get("cpu"); len(cpu) != 0 {
+		engine.limits["cpu"] = cpu
+	}
+	if memory := params.Get("memory"); len(memory) != 0 {
+		engine.limits["memory"] = memory
+	}
+	for _, label := range params["node_selector"] {
+		parts := strings.SplitN(label, "=", 2)
+		if len(parts) == 2 {
+			engine.selector[parts[0]] = parts[1]
+		}
+	}
+
+	log.Infof("using kubernetes engine %s namespace %s", url_.String(), namespace)
+	return engine
+}
+
+func newKubeEngine(client *kubeClient) *kubeEngine {
+	engine := &kubeEngine{}
+	engine.bus = newEventbus()
+	engine.tracker = newTracker()
+	engine.locks = newLocker()
+	engine.updater = &updater{bus: engine.bus}
+	engine.client = client
+	engine.limits = map[string]string{}
+	engine.selector = map[string]string{}
+	engine.interval = 5 * time.Second
+	return engine
+}
+
+// Cancel cancels the job by deleting the job pod.
+func (e *kubeEngine) Cancel(build, job int64, node *model.Node) error {
+	return e.client.DeletePod(kubePodName(build, job))
+}
+
+// Stream streams the job output from the job pod. The pod logs are
+// multiplexed using the docker log stream format.
+func (e *kubeEngine) Stream(build, job int64, node *model.Node) (io.ReadCloser, error) {
+	name := kubePodName(build, job)
+	log.Debugf("streaming pod logs %s", name)
+
+	rc, err := e.client.PodLogs(name, true, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := io.Copy(stdcopy.NewStdWriter(pw, stdcopy.Stdout), rc)
+		rc.Close()
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// Subscribe subscribes the channel to all build events.
+func (e *kubeEngine) Subscribe(c chan *Event) {
+	e.bus.subscribe(c)
+}
+
+// Unsubscribe unsubscribes the channel from all build events.
+func (e *kubeEngine) Unsubscribe(c chan *Event) {
+	e.bus.unsubscribe(c)
+}
+
+// Allocate returns an error. Pods are scheduled by the Kubernetes
+// cluster and nodes cannot be registered with the engine.
+func (e *kubeEngine) Allocate(node *model.Node) error {
+	return errKubeNodes
+}
+
+func (e *kubeEngine) Deallocate(node *model.Node) {}
+
+// Drain stops the engine from accepting new builds and waits for the
+// running builds to complete. If the running builds do not complete
+// before the timeout expires their pods are deleted and the unfinished
+// jobs are marked as killed.
+func (e *kubeEngine) Drain(timeout time.Duration) {
+	e.tracker.shutdown(timeout, func(task *Task, node *model.Node) {
+		for _, job := range task.Jobs {
+			e.Cancel(task.Build.ID, job.ID, node)
+		}
+	})
+}
+
+// Draining returns true if the engine is draining and no
+// longer accepts new builds.
+func (e *kubeEngine) Draining() bool {
+	return e.tracker.isDraining()
+}
+
+// Stats returns the scheduler statistics. Pods are scheduled by
+// Kubernetes so there are no preferred nodes.
+func (e *kubeEngine) Stats() *Stats {
+	return new(Stats)
+}
+
+func (e *kubeEngine) Schedule(c context.Context, req *Task) {
+	if !e.tracker.add(req) {
+		log.Infof("engine is draining. killing build %s/%d", req.Repo.FullName, req.Build.Number)
+		e.updater.KillBuild(c, req)
+		return
+	}
+	defer e.tracker.remove(req)
+
+	// wait for the concurrency group of the build, if any.
+	release, ok := e.locks.wait(c, req, e.updater, e.tracker.done)
+	if !ok {
+		return
+	}
+	defer release()
+
+	// since we are probably running in a go-routine
+	// make sure we recover from any panics so that
+	// a bug doesn't crash the whole system.
+	defer func() {
+		if err := recover(); err != nil {
+
+			const size = 64 << 10
+			buf := make([]byte, size)
+			buf = buf[:runtime.Stack(buf, false)]
+			log.Errorf("panic running build: %v\n%s", err, string(buf))
+		}
+	}()
+
+	req.Build.Started = time.Now().UTC().Unix()
+	req.Build.Status = model.StatusRunning
+	e.updater.SetBuild(c, req)
+
+	// run all build jobs in dependency order. Named jobs that
+	// do not depend on each other run in parallel.
+	run := func(task *Task) {
+		if e.tracker.isAborted() {
+			e.updater.KillJob(c, task)
+			return
+		}
+		e.runJob(c, task)
+	}
+	var acquire func() (runner, func())
+	if hasGraph(req.Jobs) {
+		acquire = func() (runner, func()) {
+			return run, func() {}
+		}
+	}
+	runGraph(c, req, e.updater, run, acquire)
+	if len(req.Jobs) != 0 {
+		req.Job = req.Jobs[len(req.Jobs)-1]
+	}
+
+	// update overall status based on each job
+	req.Build.Status = buildStatus(req.Jobs)
+	req.Build.Finished = time.Now().UTC().Unix()
+	err := e.updater.SetBuild(c, req)
+	if err != nil {
+		log.Errorf("error updating build completion status. %s", err)
+	}
+
+	// skip notifications if the build was killed
+	// because the server is shutting down.
+	if e.tracker.isAborted() {
+		return
+	}
+
+	// summarize the build on the pull request.
+	e.updater.SetComment(c, req)
+
+	err = e.runJobNotify(req)
+	if err != nil {
+		log.Errorf("error executing notification step. %s", err)
+	}
+}
+
+func (e *kubeEngine) runJob(c context.Context, r *Task) error {
+
+	name := kubePodName(r.Build.ID, r.Job.ID)
+
+	defer func() {
+		if r.Job.Status == model.StatusRunning {
+			r.Job.Status = model.StatusError
+			r.Job.Finished = time.Now().UTC().Unix()
+			r.Job.ExitCode = 255
+		}
+		if r.Job.Status == model.StatusPending {
+			r.Job.Status = model.StatusError
+			r.Job.Started = time.Now().UTC().Unix()
+			r.Job.Finished = time.Now().UTC().Unix()
+			r.Job.ExitCode = 255
+		}
+		e.updater.SetJob(c, r)
+
+		e.client.DeletePod(name)
+	}()
+
+	// marks the task as running
+	r.Job.Status = model.StatusRunning
+	r.Job.Started = time.Now().UTC().Unix()
+
+	// encode the build payload to pass to
+	// the build agent as an argument.
+	in, err := encodeToLegacyFormat(r)
+	if err != nil {
+		log.Errorf("failure to marshal work. %s", err)
+		return err
+	}
+
+	args := DefaultBuildArgs
+	if r.Build.Event == model.EventPull {
+		args = DefaultPullRequestArgs
+	}
+	args = append(args, "--")
+	args = append(args, string(in))
+
+	pod := e.newPod(name, args)
+	pod.Metadata.Labels["drone-build"] = strconv.FormatInt(r.Build.ID, 10)
+	pod.Metadata.Labels["drone-job"] = strconv.FormatInt(r.Job.ID, 10)
+
+	// the repository timeout is enforced by the
+	// kubelet as the pod active deadline.
+	if r.Repo.Timeout > 0 {
+		pod.Spec.ActiveDeadlineSeconds = r.Repo.Timeout * 60
+	}
+
+	log.Infof("preparing pod %s", name)
+	err = e.client.CreatePod(pod)
+	if err != nil {
+		log.Errorf("error creating build pod. %s", err)
+		return err
+	}
+
+	err = e.updater.SetJob(c, r)
+	if err != nil {
+		log.Errorf("error updating job status as running. %s", err)
+		return err
+	}
+
+	// WAIT FOR OUTPUT
+	timeout := time.Duration(r.Repo.Timeout) * time.Minute
+	pod, builderr := e.wait(name, timeout)
+
+	switch {
+	case e.tracker.isAborted():
+		r.Job.ExitCode = 130
+		r.Job.Status = model.StatusKilled
+	case builderr == errPodNotFound:
+		// the pod was deleted because the
+		// job was cancelled.
+		r.Job.ExitCode = 130
+		r.Job.Status = model.StatusKilled
+	case builderr == errPodTimeout:
+		r.Job.ExitCode = 130
+		r.Job.Status = model.StatusKilled
+	case builderr != nil:
+		r.Job.Status = model.StatusError
+	case pod.Status.Reason == "DeadlineExceeded":
+		r.Job.ExitCode = 130
+		r.Job.Status = model.StatusKilled
+	default:
+		r.Job.ExitCode = kubeExitCode(pod)
+		switch r.Job.ExitCode {
+		case 0:
+			r.Job.Status = model.StatusSuccess
+		case 128, 130:
+			r.Job.Status = model.StatusKilled
+		default:
+			r.Job.Status = model.StatusFailure
+		}
+	}
+
+	// send the logs to the datastore
+	var buf bytes.Buffer
+	rc, err := e.client.PodLogs(name, false, 5000000)
+	if err != nil && builderr != nil {
+		buf.WriteString("Error launching build")
+		buf.WriteString(builderr.Error())
+	} else if err != nil {
+		buf.WriteString("Error launching build")
+		buf.WriteString(err.Error())
+		log.Errorf("error opening connection to logs. %s", err)
+	} else {
+		defer rc.Close()
+		io.Copy(&buf, io.LimitReader(rc, 5000000))
+	}
+
+	// update the task in the datastore
+	r.Job.Finished = time.Now().UTC().Unix()
+	err = e.updater.SetJob(c, r)
+	if err != nil {
+		log.Errorf("error updating job after completion. %s", err)
+		return err
+	}
+
+	err = e.updater.SetLogs(c, r, ioutil.NopCloser(&buf))
+	if err != nil {
+		log.Errorf("error updating logs. %s", err)
+		return err
+	}
+
+	log.Debugf("completed job %d with status %s.", r.Job.ID, r.Job.Status)
+	return nil
+}
+
+func (e *kubeEngine) runJobNotify(r *Task) error {
+
+	name := fmt.Sprintf("drone-build-%d-notify", r.Build.ID)
+	defer e.client.DeletePod(name)
+
+	in, err := encodeToLegacyFormat(r)
+	if err != nil {
+		log.Errorf("failure to marshal work. %s", err)
+		return err
+	}
+
+	args := DefaultNotifyArgs
+	args = append(args, "--")
+	args = append(args, string(in))
+
+	pod := e.newPod(name, args)
+	pod.Metadata.Labels["drone-build"] = strconv.FormatInt(r.Build.ID, 10)
+
+	log.Infof("preparing pod %s", name)
+	err = e.client.CreatePod(pod)
+	if err != nil {
+		log.Errorf("Error starting notification pod %s. %s", name, err)
+		return err
+	}
+
+	pod, err = e.wait(name, 0)
+	if err == nil && kubeExitCode(pod) != 0 {
+		log.Infof("Notification pod %s exited with %d", name, kubeExitCode(pod))
+	}
+	return err
+}
+
+// newPod returns the pod definition for the build agent.
+func (e *kubeEngine) newPod(name string, args []string) *kubePod {
+	var envs []kubeEnv
+	for _, env := range e.envs {
+		parts := strings.SplitN(env, "=", 2)
+		envs = append(envs, kubeEnv{Name: parts[0], Value: parts[1]})
+	}
+
+	return &kubePod{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Metadata: kubeMeta{
+			Name:   name,
+			Labels: map[string]string{"drone": "true"},
+		},
+		Spec: kubePodSpec{
+			RestartPolicy: "Never",
+			NodeSelector:  e.selector,
+			Containers: []kubeContainer{{
+				Name:    "build",
+				Image:   DefaultAgent,
+				Command: DefaultEntrypoint,
+				Args:    args,
+				Env:     envs,
+				Resources: kubeResources{
+					Limits: e.limits,
+				},
+				VolumeMounts: []kubeVolumeMount{{
+					Name:      "docker",
+					MountPath: "/var/run/docker.sock",
+				}},
+			}},
+			Volumes: []kubeVolume{{
+				Name:     "docker",
+				HostPath: &kubeHostPath{Path: "/var/run/docker.sock"},
+			}},
+		},
+	}
+}
+
+// wait blocks until the named pod exits, returning the pod. It
+// returns an error if the pending pod cannot start, or if the pod
+// does not exit before the timeout. A zero timeout waits forever.
+func (e *kubeEngine) wait(name string, timeout time.Duration) (*kubePod, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		pod, err := e.client.GetPod(name)
+		if err != nil {
+			return nil, err
+		}
+		switch pod.Status.Phase {
+		case podSucceeded, podFailed:
+			return pod, nil
+		case podPending:
+			err = kubePending(pod)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if timeout > 0 && time.Now().After(deadline) {
+			return nil, errPodTimeout
+		}
+		time.Sleep(e.interval)
+	}
+}
+
+// kubePending returns an error if the pending pod cannot start,
+// because the image cannot be pulled or the pod cannot be scheduled.
+func kubePending(pod *kubePod) error {
+	for _, status := range pod.Status.ContainerStatuses {
+		waiting := status.State.Waiting
+		if waiting == nil {
+			continue
+		}
+		switch waiting.Reason {
+		case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+			return fmt.Errorf("Error pulling image. %s", waiting.Message)
+		}
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == "PodScheduled" && cond.Status == "False" && cond.Reason == "Unschedulable" {
+			return fmt.Errorf("Error scheduling pod. %s", cond.Message)
+		}
+	}
+	return nil
+}
+
+// kubePodName returns the name of the pod for the build job.
+func kubePodName(build, job int64) string {
+	return fmt.Sprintf("drone-build-%d-job-%d", build, job)
+}
+
+// kubeExitCode returns the exit code of the build container.
+func kubeExitCode(pod *kubePod) int {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil {
+			return status.State.Terminated.ExitCode
+		}
+	}
+	if pod.Status.Phase == podFailed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,258 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// error when the requested pod does not exist.
+var errPodNotFound = errors.New("Pod not found")
+
+// error when the pod does not exit before the timeout.
+var errPodTimeout = errors.New("Pod timed out")
+
+const (
+	podPending   = "Pending"
+	podSucceeded = "Succeeded"
+	podFailed    = "Failed"
+)
+
+type kubePod struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   kubeMeta      `json:"metadata"`
+	Spec       kubePodSpec   `json:"spec"`
+	Status     kubePodStatus `json:"status,omitempty"`
+}
+
+type kubeMeta struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+type kubePodSpec struct {
+	Containers            []kubeContainer   `json:"containers"`
+	Volumes               []kubeVolume      `json:"volumes,omitempty"`
+	RestartPolicy         string            `json:"restartPolicy"`
+	ActiveDeadlineSeconds int64             `json:"activeDeadlineSeconds,omitempty"`
+	NodeSelector          map[string]string `json:"nodeSelector,omitempty"`
+}
+
+type kubeContainer struct {
+	Name         string            `json:"name"`
+	Image        string            `json:"image"`
+	Command      []string          `json:"command,omitempty"`
+	Args         []string          `json:"args,omitempty"`
+	Env          []kubeEnv         `json:"env,omitempty"`
+	Resources    kubeResources     `json:"resources,omitempty"`
+	VolumeMounts []kubeVolumeMount `json:"volumeMounts,omitempty"`
+}
+
+type kubeEnv struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type kubeResources struct {
+	Limits   map[string]string `json:"limits,omitempty"`
+	Requests map[string]string `json:"requests,omitempty"`
+}
+
+type kubeVolume struct {
+	Name     string        `json:"name"`
+	HostPath *kubeHostPath `json:"hostPath,omitempty"`
+}
+
+type kubeHostPath struct {
+	Path string `json:"path"`
+}
+
+type kubeVolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+}
+
+type kubePodStatus struct {
+	Phase             string                `json:"phase,omitempty"`
+	Reason            string                `json:"reason,omitempty"`
+	Message           string                `json:"message,omitempty"`
+	Conditions        []kubePodCondition    `json:"conditions,omitempty"`
+	ContainerStatuses []kubeContainerStatus `json:"containerStatuses,omitempty"`
+}
+
+type kubePodCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type kubeContainerStatus struct {
+	Name  string             `json:"name"`
+	State kubeContainerState `json:"state"`
+}
+
+type kubeContainerState struct {
+	Waiting    *kubeWaiting    `json:"waiting,omitempty"`
+	Terminated *kubeTerminated `json:"terminated,omitempty"`
+}
+
+type kubeWaiting struct {
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type kubeTerminated struct {
+	ExitCode int    `json:"exitCode"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// kubeClient is a minimal client for the Kubernetes pods API.
+type kubeClient struct {
+	url       string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+func newKubeClient(rawurl, token, namespace string, config *tls.Config) *kubeClient {
+	return &kubeClient{
+		url:       strings.TrimSuffix(rawurl, "/"),
+		token:     token,
+		namespace: namespace,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: config,
+			},
+		},
+	}
+}
+
+// newKubeTLSConfig returns the TLS configuration used to connect to the
+// Kubernetes API server, trusting the certificate authority if provided.
+func newKubeTLSConfig(ca []byte, skipVerify bool) *tls.Config {
+	config := &tls.Config{InsecureSkipVerify: skipVerify}
+	if len(ca) != 0 {
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(ca)
+		config.RootCAs = pool
+	}
+	return config
+}
+
+// CreatePod creates the pod in the configured namespace.
+func (k *kubeClient) CreatePod(pod *kubePod) error {
+	return k.do("POST", k.podsPath(), nil, pod, nil)
+}
+
+// GetPod gets the named pod.
+func (k *kubeClient) GetPod(name string) (*kubePod, error) {
+	pod := new(kubePod)
+	err := k.do("GET", k.podPath(name), nil, nil, pod)
+	return pod, err
+}
+
+// DeletePod deletes the named pod immediately.
+func (k *kubeClient) DeletePod(name string) error {
+	params := url.Values{}
+	params.Set("gracePeriodSeconds", "0")
+	return k.do("DELETE", k.podPath(name), params, nil, nil)
+}
+
+// PodLogs returns the logs for the named pod. If follow is true the
+// stream remains open until the pod exits. If limit is greater than
+// zero the logs are limited to the number of bytes.
+func (k *kubeClient) PodLogs(name string, follow bool, limit int) (io.ReadCloser, error) {
+	params := url.Values{}
+	if follow {
+		params.Set("follow", "true")
+	}
+	if limit > 0 {
+		params.Set("limitBytes", fmt.Sprint(limit))
+	}
+	res, err := k.request("GET", k.podPath(name)+"/log", params, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (k *kubeClient) podsPath() string {
+	return fmt.Sprintf("/api/v1/namespaces/%s/pods", k.namespace)
+}
+
+func (k *kubeClient) podPath(name string) string {
+	return fmt.Sprintf("/api/v1/namespaces/%s/pods/%s", k.namespace, name)
+}
+
+// helper function to make an http request to the API server and
+// unmarshal the JSON response body to out, if not nil.
+func (k *kubeClient) do(method, path string, params url.Values, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		buf, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	res, err := k.request(method, path, params, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// helper function to make an http request to the API server. It returns
+// an error if the response status code is not 2xx.
+func (k *kubeClient) request(method, path string, params url.Values, body io.Reader) (*http.Response, error) {
+	uri := k.url + path
+	if len(params) != 0 {
+		uri = uri + "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest(method, uri, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if len(k.token) != 0 {
+		req.Header.Set("Authorization", "Bearer "+k.token)
+	}
+
+	res, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		res.Body.Close()
+		return nil, errPodNotFound
+	case res.StatusCode > 299:
+		defer res.Body.Close()
+		out, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("Kubernetes API error %d. %s", res.StatusCode, strings.TrimSpace(string(out)))
+	}
+	return res, nil
+}
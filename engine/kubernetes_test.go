@@ -0,0 +1,252 @@
+package engine
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/franela/goblin"
+)
+
+func TestKubernetes(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Kubernetes engine", func() {
+
+		var api *fakeKubeServer
+		var server *httptest.Server
+		var engine *kubeEngine
+
+		g.BeforeEach(func() {
+			if server != nil {
+				server.Close()
+			}
+			api = newFakeKubeServer()
+			server = httptest.NewServer(api)
+			engine = newKubeEngine(newKubeClient(server.URL, "secret", "drone", nil))
+			engine.interval = time.Millisecond
+			engine.limits["cpu"] = "2"
+			engine.limits["memory"] = "4Gi"
+		})
+
+		g.It("Should run jobs as pods", func() {
+			c, s := fakeContext()
+			task := fakeTask()
+			engine.Schedule(c, task)
+
+			g.Assert(task.Build.Status).Equal(model.StatusSuccess)
+			g.Assert(task.Jobs[0].Status).Equal(model.StatusSuccess)
+			g.Assert(s.logs[task.Jobs[0].ID]).Equal("hello world\n")
+			g.Assert(s.statuses).Equal([]string{model.StatusRunning, model.StatusSuccess})
+
+			pod := api.created["drone-build-1-job-2"]
+			g.Assert(pod == nil).IsFalse()
+			g.Assert(pod.Spec.RestartPolicy).Equal("Never")
+			g.Assert(pod.Spec.ActiveDeadlineSeconds).Equal(int64(3600))
+			g.Assert(pod.Spec.Containers[0].Image).Equal(DefaultAgent)
+			g.Assert(pod.Spec.Containers[0].Resources.Limits["cpu"]).Equal("2")
+			g.Assert(pod.Spec.Containers[0].Resources.Limits["memory"]).Equal("4Gi")
+			g.Assert(pod.Metadata.Labels["drone-job"]).Equal("2")
+			g.Assert(api.token).Equal("Bearer secret")
+
+			// pods are deleted once complete.
+			g.Assert(len(api.pods)).Equal(0)
+		})
+
+		g.It("Should map the exit code to the job status", func() {
+			api.exitCode = 1
+			c, _ := fakeContext()
+			task := fakeTask()
+			engine.Schedule(c, task)
+			g.Assert(task.Jobs[0].ExitCode).Equal(1)
+			g.Assert(task.Jobs[0].Status).Equal(model.StatusFailure)
+			g.Assert(task.Build.Status).Equal(model.StatusFailure)
+		})
+
+		g.It("Should mark timed out jobs as killed", func() {
+			api.reason = "DeadlineExceeded"
+			c, _ := fakeContext()
+			task := fakeTask()
+			engine.Schedule(c, task)
+			g.Assert(task.Jobs[0].Status).Equal(model.StatusKilled)
+		})
+
+		g.It("Should time out pending pods", func() {
+			api.phase = podPending
+			api.pods["drone-build-1-job-2"] = &kubePod{}
+			_, err := engine.wait("drone-build-1-job-2", time.Millisecond)
+			g.Assert(err).Equal(errPodTimeout)
+		})
+
+		g.It("Should fail jobs if the image cannot be pulled", func() {
+			api.phase = podPending
+			api.waiting = "ImagePullBackOff"
+			c, _ := fakeContext()
+			task := fakeTask()
+			engine.Schedule(c, task)
+			g.Assert(task.Jobs[0].Status).Equal(model.StatusError)
+			g.Assert(task.Build.Status).Equal(model.StatusError)
+		})
+
+		g.It("Should fail jobs if the pod cannot be scheduled", func() {
+			api.phase = podPending
+			api.conditions = []kubePodCondition{{
+				Type:    "PodScheduled",
+				Status:  "False",
+				Reason:  "Unschedulable",
+				Message: "0/3 nodes are available: 3 Insufficient cpu.",
+			}}
+			c, _ := fakeContext()
+			task := fakeTask()
+			engine.Schedule(c, task)
+			g.Assert(task.Jobs[0].Status).Equal(model.StatusError)
+			g.Assert(task.Build.Status).Equal(model.StatusError)
+		})
+
+		g.It("Should mark cancelled jobs as killed", func() {
+			api.phase = "Running"
+			c, _ := fakeContext()
+			task := fakeTask()
+			go func() {
+				for !api.exists("drone-build-1-job-2") {
+					time.Sleep(time.Millisecond)
+				}
+				engine.Cancel(1, 2, nil)
+
+				// allow the notification pod to complete
+				api.Lock()
+				api.phase = podSucceeded
+				api.Unlock()
+			}()
+			engine.Schedule(c, task)
+			g.Assert(task.Jobs[0].Status).Equal(model.StatusKilled)
+			g.Assert(task.Build.Status).Equal(model.StatusKilled)
+		})
+
+		g.It("Should stream logs", func() {
+			api.phase = "Running"
+			api.pods["drone-build-1-job-2"] = &kubePod{}
+			rc, err := engine.Stream(1, 2, nil)
+			g.Assert(err == nil).IsTrue()
+			defer rc.Close()
+
+			buf := make([]byte, 8)
+			n, _ := rc.Read(buf)
+			g.Assert(n).Equal(8)
+			g.Assert(buf[0]).Equal(byte(1)) // stdout stream header
+		})
+
+		g.It("Should not allocate nodes", func() {
+			err := engine.Allocate(&model.Node{})
+			g.Assert(err).Equal(errKubeNodes)
+		})
+	})
+}
+
+func fakeTask() *Task {
+	return &Task{
+		User:  &model.User{},
+		Repo:  &model.Repo{FullName: "octocat/hello-world", Timeout: 60},
+		Build: &model.Build{ID: 1, Number: 1, Status: model.StatusPending},
+		Jobs:  []*model.Job{{ID: 2, Number: 1, Status: model.StatusPending}},
+		System: &model.System{
+			Link: "http://drone.local",
+		},
+	}
+}
+
+// fakeKubeServer is a fake implementation of the Kubernetes
+// pods API used for testing.
+type fakeKubeServer struct {
+	sync.Mutex
+	pods     map[string]*kubePod
+	created  map[string]*kubePod
+	token    string
+	phase    string
+	reason   string
+	waiting  string
+	exitCode int
+
+	conditions []kubePodCondition
+}
+
+func newFakeKubeServer() *fakeKubeServer {
+	return &fakeKubeServer{
+		pods:    map[string]*kubePod{},
+		created: map[string]*kubePod{},
+		phase:   podSucceeded,
+	}
+}
+
+func (s *fakeKubeServer) exists(name string) bool {
+	s.Lock()
+	defer s.Unlock()
+	_, ok := s.pods[name]
+	return ok
+}
+
+func (s *fakeKubeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	const prefix = "/api/v1/namespaces/drone/pods"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(404)
+		return
+	}
+	s.token = r.Header.Get("Authorization")
+
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	switch {
+	case r.Method == "POST":
+		pod := new(kubePod)
+		json.NewDecoder(r.Body).Decode(pod)
+		s.pods[pod.Metadata.Name] = pod
+		s.created[pod.Metadata.Name] = pod
+		w.WriteHeader(201)
+		json.NewEncoder(w).Encode(pod)
+
+	case strings.HasSuffix(name, "/log"):
+		if _, ok := s.pods[strings.TrimSuffix(name, "/log")]; !ok {
+			w.WriteHeader(404)
+			return
+		}
+		w.Write([]byte("hello world\n"))
+
+	case r.Method == "GET":
+		pod, ok := s.pods[name]
+		if !ok {
+			w.WriteHeader(404)
+			return
+		}
+		pod.Status.Phase = s.phase
+		pod.Status.Reason = s.reason
+		pod.Status.Conditions = s.conditions
+		pod.Status.ContainerStatuses = []kubeContainerStatus{{
+			Name: "build",
+			State: kubeContainerState{
+				Terminated: &kubeTerminated{ExitCode: s.exitCode},
+			},
+		}}
+		if len(s.waiting) != 0 {
+			pod.Status.ContainerStatuses[0].State = kubeContainerState{
+				Waiting: &kubeWaiting{Reason: s.waiting, Message: "image not found"},
+			}
+		}
+		json.NewEncoder(w).Encode(pod)
+
+	case r.Method == "DELETE":
+		if _, ok := s.pods[name]; !ok {
+			w.WriteHeader(404)
+			return
+		}
+		delete(s.pods, name)
+		w.WriteHeader(200)
+		w.Write([]byte("{}"))
+	}
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"sync"
+
+	"github.com/CiscoCloud/drone/yaml"
+	"golang.org/x/net/context"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// locker grants builds exclusive access to their concurrency
+// group, in the order the builds were scheduled.
+type locker struct {
+	sync.Mutex
+	locks map[string]*lock
+}
+
+// lock is a concurrency group held by a running build,
+// and the queue of builds waiting for the group.
+type lock struct {
+	holder *Task
+	queue  []*waiter
+}
+
+// waiter is a build waiting for a concurrency group. The
+// channel is closed when the group is granted to the build.
+type waiter struct {
+	task *Task
+	c    chan struct{}
+}
+
+func newLocker() *locker {
+	return &locker{locks: make(map[string]*lock)}
+}
+
+// Acquire grants the named concurrency group to the task if it
+// is free. If another build holds the group, the task is added
+// to the queue and the returned channel is closed when the group
+// is granted. The current holder of the group is also returned.
+func (l *locker) acquire(name string, task *Task) (*Task, <-chan struct{}) {
+	l.Lock()
+	defer l.Unlock()
+
+	lk, ok := l.locks[name]
+	if !ok {
+		l.locks[name] = &lock{holder: task}
+		return nil, nil
+	}
+	w := &waiter{task: task, c: make(chan struct{})}
+	lk.queue = append(lk.queue, w)
+	return lk.holder, w.c
+}
+
+// Release releases the named concurrency group held by the task,
+// or removes the task from the queue if the group has not been
+// granted. The group is granted to the next task in the queue.
+func (l *locker) release(name string, task *Task) {
+	l.Lock()
+	defer l.Unlock()
+
+	lk, ok := l.locks[name]
+	if !ok {
+		return
+	}
+	if lk.holder != task {
+		for i, w := range lk.queue {
+			if w.task == task {
+				lk.queue = append(lk.queue[:i], lk.queue[i+1:]...)
+				break
+			}
+		}
+		return
+	}
+	if len(lk.queue) == 0 {
+		delete(l.locks, name)
+		return
+	}
+	next := lk.queue[0]
+	lk.queue = lk.queue[1:]
+	lk.holder = next.task
+	close(next.c)
+}
+
+// concurrencyGroup returns the concurrency group defined in the
+// build configuration. Groups are scoped to the repository, so the
+// same group name in another repository is another group.
+func concurrencyGroup(req *Task) (string, *yaml.Concurrency) {
+	conf, err := yaml.Parse(req.Config)
+	if err != nil || len(conf.Concurrency.Group) == 0 {
+		return "", nil
+	}
+	return req.Repo.FullName + "/" + conf.Concurrency.Group, &conf.Concurrency
+}
+
+// wait acquires the concurrency group of the build, if any. If the
+// group is held by another build, the build waits for the group to
+// be released, or is killed if the concurrency mode is cancel. It
+// returns a function to release the group, and false if the build
+// was killed or the engine started draining while waiting.
+func (l *locker) wait(c context.Context, req *Task, u *updater, done <-chan struct{}) (func(), bool) {
+	name, conf := concurrencyGroup(req)
+	if conf == nil {
+		return func() {}, true
+	}
+	release := func() { l.release(name, req) }
+
+	holder, granted := l.acquire(name, req)
+	if granted == nil {
+		return release, true
+	}
+
+	if conf.Mode == yaml.ConcurrencyCancel {
+		release()
+		log.Infof("concurrency group %s is held by build %s/%d. killing build %s/%d",
+			conf.Group, holder.Repo.FullName, holder.Build.Number, req.Repo.FullName, req.Build.Number)
+		u.KillBuild(c, req)
+		return nil, false
+	}
+
+	log.Infof("build %s/%d waiting on concurrency group %s held by build %s/%d",
+		req.Repo.FullName, req.Build.Number, conf.Group, holder.Repo.FullName, holder.Build.Number)
+	req.Build.Waiting = conf.Group
+	u.SetBuild(c, req)
+
+	select {
+	case <-granted:
+		req.Build.Waiting = ""
+		return release, true
+	case <-done:
+		release()
+		req.Build.Waiting = ""
+		u.KillBuild(c, req)
+		return nil, false
+	}
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/franela/goblin"
+)
+
+func TestLocker(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Concurrency groups", func() {
+
+		g.It("Should grant a free group", func() {
+			l := newLocker()
+			holder, granted := l.acquire("deploy", &Task{})
+			g.Assert(holder == nil).IsTrue()
+			g.Assert(granted == nil).IsTrue()
+		})
+
+		g.It("Should grant the group in order", func() {
+			t1, t2, t3 := &Task{}, &Task{}, &Task{}
+			l := newLocker()
+			l.acquire("deploy", t1)
+			holder, c2 := l.acquire("deploy", t2)
+			g.Assert(holder).Equal(t1)
+			_, c3 := l.acquire("deploy", t3)
+
+			l.release("deploy", t1)
+			<-c2
+			g.Assert(isClosed(c3)).IsFalse()
+			l.release("deploy", t2)
+			<-c3
+			l.release("deploy", t3)
+			g.Assert(len(l.locks)).Equal(0)
+		})
+
+		g.It("Should remove a waiting task from the queue", func() {
+			t1, t2 := &Task{}, &Task{}
+			l := newLocker()
+			l.acquire("deploy", t1)
+			l.acquire("deploy", t2)
+			l.release("deploy", t2)
+			l.release("deploy", t1)
+			g.Assert(len(l.locks)).Equal(0)
+		})
+
+		g.It("Should parse the concurrency group", func() {
+			task := fakeTask()
+			task.Config = "concurrency:\n  group: deploy-production\n  mode: cancel\n"
+			name, conf := concurrencyGroup(task)
+			g.Assert(name).Equal("octocat/hello-world/deploy-production")
+			g.Assert(conf.Mode).Equal("cancel")
+
+			task.Config = "debug: true\n"
+			_, conf = concurrencyGroup(task)
+			g.Assert(conf == nil).IsTrue()
+		})
+
+		g.It("Should kill the build in cancel mode", func() {
+			c, _ := fakeContext()
+			u := &updater{bus: newEventbus()}
+			l := newLocker()
+
+			first := fakeTask()
+			first.Config = "concurrency:\n  group: deploy\n  mode: cancel\n"
+			release, ok := l.wait(c, first, u, nil)
+			g.Assert(ok).IsTrue()
+			defer release()
+
+			second := fakeTask()
+			second.Config = first.Config
+			_, ok = l.wait(c, second, u, nil)
+			g.Assert(ok).IsFalse()
+			g.Assert(second.Build.Status).Equal(model.StatusKilled)
+		})
+
+		g.It("Should wait for the group in wait mode", func() {
+			c, _ := fakeContext()
+			u := &updater{bus: newEventbus()}
+			l := newLocker()
+
+			first := fakeTask()
+			first.Config = "concurrency:\n  group: deploy\n"
+			release, _ := l.wait(c, first, u, nil)
+
+			second := fakeTask()
+			second.Config = first.Config
+			done := make(chan bool)
+			go func() {
+				_, ok := l.wait(c, second, u, nil)
+				done <- ok
+			}()
+
+			release()
+			g.Assert(<-done).IsTrue()
+			g.Assert(second.Build.Waiting).Equal("")
+		})
+
+		g.It("Should kill a waiting build when draining", func() {
+			c, _ := fakeContext()
+			u := &updater{bus: newEventbus()}
+			l := newLocker()
+			drain := make(chan struct{})
+
+			first := fakeTask()
+			first.Config = "concurrency:\n  group: deploy\n"
+			l.wait(c, first, u, drain)
+
+			second := fakeTask()
+			second.Config = first.Config
+			close(drain)
+			_, ok := l.wait(c, second, u, drain)
+			g.Assert(ok).IsFalse()
+			g.Assert(second.Build.Status).Equal(model.StatusKilled)
+		})
+	})
+}
+
+func isClosed(c <-chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
@@ -2,20 +2,43 @@ package engine
 
 import (
 	"sync"
+	"time"
 
 	"github.com/CiscoCloud/drone/model"
 )
 
+// reasons a node is parked and held out of the pool.
+const (
+	parkDisk   = "disk"
+	parkWarmup = "warmup"
+)
+
 type pool struct {
 	sync.Mutex
 	nodes map[*model.Node]bool
 	nodec chan *model.Node
+
+	// since records when each idle node was last
+	// allocated or released to the pool.
+	since map[*model.Node]time.Time
+
+	// parked records nodes that are held out of the
+	// pool and the reasons, ie because they are low
+	// on disk.
+	parked map[*model.Node]map[string]bool
+
+	// waiters records the channels of schedulers
+	// waiting for a specific node to be released.
+	waiters map[*model.Node][]chan struct{}
 }
 
 func newPool() *pool {
 	return &pool{
-		nodes: make(map[*model.Node]bool),
-		nodec: make(chan *model.Node, 999),
+		nodes:   make(map[*model.Node]bool),
+		nodec:   make(chan *model.Node, 999),
+		since:   make(map[*model.Node]time.Time),
+		parked:  make(map[*model.Node]map[string]bool),
+		waiters: make(map[*model.Node][]chan struct{}),
 	}
 }
 
@@ -28,12 +51,27 @@ func (p *pool) allocate(n *model.Node) bool {
 
 	p.Lock()
 	p.nodes[n] = true
+	p.since[n] = time.Now()
 	p.Unlock()
 
 	p.nodec <- n
 	return true
 }
 
+// AllocateParked allocates a node to the pool that is
+// parked for the given reason, and does not accept work
+// until it is unparked.
+func (p *pool) allocateParked(n *model.Node, reason string) bool {
+	p.Lock()
+	defer p.Unlock()
+	if _, ok := p.nodes[n]; ok {
+		return false
+	}
+	p.nodes[n] = true
+	p.parked[n] = map[string]bool{reason: true}
+	return true
+}
+
 // IsAllocated is a helper function that returns
 // true if the node is currently allocated to
 // the pool.
@@ -52,6 +90,12 @@ func (p *pool) deallocate(n *model.Node) {
 	p.Lock()
 	defer p.Unlock()
 	delete(p.nodes, n)
+	delete(p.since, n)
+	delete(p.parked, n)
+	for _, wait := range p.waiters[n] {
+		close(wait)
+	}
+	delete(p.waiters, n)
 }
 
 // List returns a list of all model.Nodes currently
@@ -81,6 +125,165 @@ func (p *pool) release(n *model.Node) bool {
 		return false
 	}
 
+	p.Lock()
+	p.since[n] = time.Now()
+	for _, wait := range p.waiters[n] {
+		close(wait)
+	}
+	delete(p.waiters, n)
+	p.Unlock()
+
 	p.nodec <- n
 	return true
 }
+
+// Claim marks a reserved node as busy. It returns false
+// if the node was deallocated, reclaimed or acquired while
+// waiting in the pool, in which case it must not be given
+// work.
+func (p *pool) claim(n *model.Node) bool {
+	p.Lock()
+	defer p.Unlock()
+	if _, ok := p.since[n]; !ok {
+		return false
+	}
+	delete(p.since, n)
+	return true
+}
+
+// Acquire claims the specified node, waiting for the node to
+// be released if it is busy. It returns false if the node is
+// not claimed before the timeout expires or the cancel channel
+// is closed.
+func (p *pool) acquire(n *model.Node, timeout time.Duration, cancel <-chan struct{}) bool {
+	deadline := time.After(timeout)
+	for {
+		p.Lock()
+		if _, ok := p.since[n]; ok {
+			delete(p.since, n)
+			p.Unlock()
+			return true
+		}
+		if _, ok := p.nodes[n]; !ok {
+			p.Unlock()
+			return false
+		}
+		wait := make(chan struct{})
+		p.waiters[n] = append(p.waiters[n], wait)
+		p.Unlock()
+
+		select {
+		case <-wait:
+		case <-deadline:
+			return false
+		case <-cancel:
+			return false
+		}
+	}
+}
+
+// Reclaim removes the node from the pool if it has been
+// idle for longer than the timeout. It returns false if
+// the node is busy or has not been idle long enough.
+func (p *pool) reclaim(n *model.Node, timeout time.Duration) bool {
+	p.Lock()
+	defer p.Unlock()
+	since, ok := p.since[n]
+	if !ok || time.Since(since) < timeout {
+		return false
+	}
+	delete(p.nodes, n)
+	delete(p.since, n)
+	return true
+}
+
+// Idle returns the number of allocated nodes that are
+// not currently doing work.
+func (p *pool) idle() int {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.since)
+}
+
+// Warming returns the number of allocated nodes that are parked
+// until they finish pulling the warm-up images. These nodes will
+// accept work shortly, and are not parked for any other reason.
+func (p *pool) warming() int {
+	p.Lock()
+	defer p.Unlock()
+	var count int
+	for _, reasons := range p.parked {
+		if len(reasons) == 1 && reasons[parkWarmup] {
+			count++
+		}
+	}
+	return count
+}
+
+// Update updates the resources of the node.
+func (p *pool) update(n *model.Node, info *nodeInfo) {
+	p.Lock()
+	defer p.Unlock()
+	info.apply(n)
+}
+
+// Collected records the disk space in bytes reclaimed
+// by garbage collection on the node.
+func (p *pool) collected(n *model.Node, reclaimed int64) {
+	p.Lock()
+	defer p.Unlock()
+	n.Reclaimed = reclaimed
+	n.Collected = time.Now().UTC().Unix()
+}
+
+// HasDisk returns true if the node has at least the minimum
+// free disk space in bytes. Nodes that do not report free
+// disk space are assumed to have enough.
+func (p *pool) hasDisk(n *model.Node, min int64) bool {
+	p.Lock()
+	defer p.Unlock()
+	return min == 0 || n.DiskFree == 0 || n.DiskFree >= min
+}
+
+// Park holds a claimed node out of the pool for the given
+// reason. The node is not given new work until it is unparked
+// for every reason it was parked.
+func (p *pool) park(n *model.Node, reason string) {
+	p.Lock()
+	defer p.Unlock()
+	if _, ok := p.nodes[n]; !ok {
+		return
+	}
+	if p.parked[n] == nil {
+		p.parked[n] = map[string]bool{}
+	}
+	p.parked[n][reason] = true
+}
+
+// IsParked returns true if the node is parked.
+func (p *pool) isParked(n *model.Node) bool {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.parked[n]) != 0
+}
+
+// Unpark removes the reason the node was parked, releasing
+// the node back to the pool once no reasons remain. It returns
+// true if the node was released.
+func (p *pool) unpark(n *model.Node, reason string) bool {
+	p.Lock()
+	reasons, ok := p.parked[n]
+	if !ok || !reasons[reason] {
+		p.Unlock()
+		return false
+	}
+	delete(reasons, reason)
+	if len(reasons) != 0 {
+		p.Unlock()
+		return false
+	}
+	delete(p.parked, n)
+	p.Unlock()
+
+	return p.release(n)
+}
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"testing"
+	"time"
 
 	"github.com/CiscoCloud/drone/model"
 	"github.com/franela/goblin"
@@ -85,5 +86,114 @@ func TestPool(t *testing.T) {
 			g.Assert(len(pool.list())).Equal(0)
 		})
 
+		g.It("Should claim a reserved node", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			pool := newPool()
+			pool.allocate(n)
+			g.Assert(pool.idle()).Equal(1)
+			g.Assert(pool.claim(<-pool.reserve())).IsTrue()
+			g.Assert(pool.idle()).Equal(0)
+			pool.release(n)
+			g.Assert(pool.idle()).Equal(1)
+		})
+
+		g.It("Should not claim a deallocated node", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			pool := newPool()
+			pool.allocate(n)
+			pool.deallocate(n)
+			g.Assert(pool.claim(<-pool.reserve())).IsFalse()
+		})
+
+		g.It("Should reclaim an idle node", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			pool := newPool()
+			pool.allocate(n)
+			g.Assert(pool.reclaim(n, time.Hour)).IsFalse()
+			g.Assert(pool.reclaim(n, 0)).IsTrue()
+			g.Assert(pool.isAllocated(n)).IsFalse()
+			g.Assert(pool.claim(<-pool.reserve())).IsFalse()
+		})
+
+		g.It("Should not reclaim a busy node", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			pool := newPool()
+			pool.allocate(n)
+			pool.claim(<-pool.reserve())
+			g.Assert(pool.reclaim(n, 0)).IsFalse()
+			g.Assert(pool.isAllocated(n)).IsTrue()
+		})
+
+		g.It("Should check free disk space", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			pool := newPool()
+			g.Assert(pool.hasDisk(n, 100)).IsTrue()
+			n.DiskFree = 50
+			g.Assert(pool.hasDisk(n, 0)).IsTrue()
+			g.Assert(pool.hasDisk(n, 50)).IsTrue()
+			g.Assert(pool.hasDisk(n, 100)).IsFalse()
+		})
+
+		g.It("Should park and unpark a node", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			pool := newPool()
+			pool.allocate(n)
+			pool.claim(<-pool.reserve())
+			pool.park(n, parkDisk)
+			g.Assert(len(pool.nodec)).Equal(0)
+			g.Assert(pool.unpark(n, parkDisk)).IsTrue()
+			g.Assert(len(pool.nodec)).Equal(1)
+			g.Assert(pool.unpark(n, parkDisk)).IsFalse()
+		})
+
+		g.It("Should unpark a node parked for every reason", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			pool := newPool()
+			pool.allocateParked(n, parkWarmup)
+			pool.park(n, parkDisk)
+			g.Assert(pool.isParked(n)).IsTrue()
+			g.Assert(len(pool.nodec)).Equal(0)
+			g.Assert(pool.unpark(n, parkWarmup)).IsFalse()
+			g.Assert(pool.unpark(n, parkDisk)).IsTrue()
+			g.Assert(pool.isParked(n)).IsFalse()
+			g.Assert(pool.claim(<-pool.reserve())).IsTrue()
+		})
+
+		g.It("Should acquire an idle node", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			pool := newPool()
+			pool.allocate(n)
+			g.Assert(pool.acquire(n, time.Second, nil)).IsTrue()
+			g.Assert(pool.idle()).Equal(0)
+
+			// the node is no longer available to other builds.
+			g.Assert(pool.claim(<-pool.reserve())).IsFalse()
+		})
+
+		g.It("Should acquire a node when released", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			pool := newPool()
+			pool.allocate(n)
+			pool.claim(<-pool.reserve())
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				pool.release(n)
+			}()
+			g.Assert(pool.acquire(n, time.Second, nil)).IsTrue()
+		})
+
+		g.It("Should timeout acquiring a busy node", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			pool := newPool()
+			pool.allocate(n)
+			pool.claim(<-pool.reserve())
+			g.Assert(pool.acquire(n, time.Millisecond, nil)).IsFalse()
+		})
+
+		g.It("Should not acquire a deallocated node", func() {
+			n := &model.Node{Addr: "unix:///var/run/docker.sock"}
+			pool := newPool()
+			g.Assert(pool.acquire(n, time.Second, nil)).IsFalse()
+		})
 	})
 }
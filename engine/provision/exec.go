@@ -0,0 +1,129 @@
+package provision
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/shared/envconfig"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Exec is a node provisioner that runs administrator provided
+// commands to create and destroy build nodes.
+//
+// The create command must write the node to stdout in JSON format:
+//
+//	{
+//	  "machine": "build-node-1",
+//	  "address": "tcp://10.0.0.5:2376",
+//	  "cert": "...",
+//	  "key": "...",
+//	  "ca": "..."
+//	}
+//
+// The destroy command is invoked with the node machine as its last
+// argument, and the DRONE_NODE_MACHINE and DRONE_NODE_ADDRESS
+// environment variables.
+type Exec struct {
+	CreateCmd  string
+	DestroyCmd string
+	Timeout    time.Duration
+}
+
+// LoadExec returns a new exec provisioner using the commands
+// specified in the environment variables.
+func LoadExec(env envconfig.Env) *Exec {
+	e := &Exec{
+		CreateCmd:  env.String("PROVISION_CREATE", ""),
+		DestroyCmd: env.String("PROVISION_DESTROY", ""),
+		Timeout:    env.Duration("PROVISION_TIMEOUT", 10*time.Minute),
+	}
+	if len(e.CreateCmd) == 0 || len(e.DestroyCmd) == 0 {
+		log.Fatalln("exec provision driver requires PROVISION_CREATE and PROVISION_DESTROY")
+	}
+	return e
+}
+
+// Create runs the create command and returns the node
+// written to stdout.
+func (e *Exec) Create() (*model.Node, error) {
+	out, err := e.run(e.CreateCmd, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	in := struct {
+		Machine string `json:"machine"`
+		Addr    string `json:"address"`
+		Arch    string `json:"architecture"`
+		Cert    string `json:"cert"`
+		Key     string `json:"key"`
+		CA      string `json:"ca"`
+	}{}
+	err = json.Unmarshal(out, &in)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing create command output. %s", err)
+	}
+	if len(in.Machine) == 0 || len(in.Addr) == 0 {
+		return nil, fmt.Errorf("Error parsing create command output. Machine and address are required")
+	}
+
+	node := &model.Node{
+		Machine: in.Machine,
+		Addr:    in.Addr,
+		Arch:    in.Arch,
+		Cert:    in.Cert,
+		Key:     in.Key,
+		CA:      in.CA,
+	}
+	if len(node.Arch) == 0 {
+		node.Arch = "linux_amd64"
+	}
+	return node, nil
+}
+
+// Destroy runs the destroy command for the node.
+func (e *Exec) Destroy(node *model.Node) error {
+	_, err := e.run(e.DestroyCmd+" "+node.Machine, []string{
+		"DRONE_NODE_MACHINE=" + node.Machine,
+		"DRONE_NODE_ADDRESS=" + node.Addr,
+	})
+	return err
+}
+
+// helper function to run the command and return the output
+// written to stdout.
+func (e *Exec) run(command string, envs []string) ([]byte, error) {
+	args := strings.Fields(command)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), envs...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("Error running %s. %s", args[0], err)
+	}
+
+	// kill the command if it does not complete in time.
+	timer := time.AfterFunc(e.Timeout, func() {
+		cmd.Process.Kill()
+	})
+	err = cmd.Wait()
+	if !timer.Stop() {
+		return nil, fmt.Errorf("Error running %s. Timeout after %s. %s", args[0], e.Timeout, strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error running %s. %s. %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
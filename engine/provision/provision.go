@@ -0,0 +1,35 @@
+package provision
+
+import (
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/shared/envconfig"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Provisioner creates and destroys build nodes on demand.
+type Provisioner interface {
+	// Create creates a new build node. The node must be ready to
+	// accept builds when Create returns, and the node Machine field
+	// must be set to a value that uniquely identifies the node.
+	Create() (*model.Node, error)
+
+	// Destroy destroys the build node.
+	Destroy(*model.Node) error
+}
+
+// Load returns the node provisioner specified in the environment
+// variables, or nil if node provisioning is not enabled.
+func Load(env envconfig.Env) Provisioner {
+	driver := env.Get("PROVISION_DRIVER")
+
+	switch driver {
+	case "":
+		return nil
+	case "exec":
+		return LoadExec(env)
+	default:
+		log.Fatalf("unknown provision driver %s", driver)
+	}
+	return nil
+}
@@ -4,15 +4,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sync"
+	"time"
 
 	"github.com/CiscoCloud/drone/model"
 	"github.com/CiscoCloud/drone/remote"
 	"github.com/CiscoCloud/drone/store"
+	log "github.com/Sirupsen/logrus"
 	"golang.org/x/net/context"
 )
 
 type updater struct {
 	bus *eventbus
+
+	// mu serializes the updates of builds that run jobs in
+	// parallel. Each parallel job updates its own copy of the
+	// job, which is copied to the build jobs while locked.
+	mu sync.Mutex
 }
 
 func (u *updater) SetBuild(c context.Context, r *Task) error {
@@ -26,7 +34,7 @@ func (u *updater) SetBuild(c context.Context, r *Task) error {
 		// log err
 	}
 
-	msg, err := json.Marshal(&payload{r.Build, r.Jobs})
+	msg, err := u.encode(r)
 	if err != nil {
 		return err
 	}
@@ -44,7 +52,16 @@ func (u *updater) SetJob(c context.Context, r *Task) error {
 		return err
 	}
 
-	msg, err := json.Marshal(&payload{r.Build, r.Jobs})
+	// builds with several jobs also report the status of each job,
+	// if the remote system supports it and the repository enables it.
+	if statuser, ok := remote.FromContext(c).(remote.JobStatuser); ok && len(r.Jobs) > 1 && r.Repo.JobStatus {
+		err = statuser.JobStatus(r.User, r.Repo, r.Build, r.Job, jobLink(r, r.Job))
+		if err != nil {
+			log.Errorf("error setting commit status for job %s/%d/%d. %s", r.Repo.FullName, r.Build.Number, r.Job.Number, err)
+		}
+	}
+
+	msg, err := u.encode(r)
 	if err != nil {
 		return err
 	}
@@ -56,6 +73,73 @@ func (u *updater) SetJob(c context.Context, r *Task) error {
 	return nil
 }
 
+// KillBuild marks a build that was never started, and all of
+// its pending jobs, as killed. Jobs that completed in a previous
+// run of a partially restarted build keep their status.
+func (u *updater) KillBuild(c context.Context, r *Task) {
+	for _, job := range r.Jobs {
+		if job.Status != model.StatusPending {
+			continue
+		}
+		r.Job = job
+		u.KillJob(c, r)
+	}
+
+	r.Build.Status = model.StatusKilled
+	r.Build.Finished = time.Now().UTC().Unix()
+	if r.Build.Started == 0 {
+		r.Build.Started = r.Build.Finished
+	}
+	err := u.SetBuild(c, r)
+	if err != nil {
+		log.Errorf("error updating killed build status. %s", err)
+	}
+}
+
+// KillJob marks a job that was never started as killed.
+func (u *updater) KillJob(c context.Context, r *Task) {
+	r.Job.Status = model.StatusKilled
+	r.Job.Started = time.Now().UTC().Unix()
+	r.Job.Finished = r.Job.Started
+	err := u.SetJob(c, r)
+	if err != nil {
+		log.Errorf("error updating killed job status. %s", err)
+	}
+}
+
+// syncJob copies the job to the build jobs of the task.
+func (u *updater) syncJob(r *Task, job *model.Job) {
+	u.mu.Lock()
+	copyJob(r.Jobs, job)
+	u.mu.Unlock()
+}
+
+// encode copies the job of the task to the build jobs, and
+// returns the build and jobs encoded for the event bus.
+func (u *updater) encode(r *Task) ([]byte, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if r.Job != nil {
+		copyJob(r.Jobs, r.Job)
+	}
+	return json.Marshal(&payload{r.Build, r.Jobs})
+}
+
+// helper function to copy the fields that change while the job
+// runs to the job with the same number in the list, if it is a copy.
+func copyJob(jobs []*model.Job, job *model.Job) {
+	for _, j := range jobs {
+		if j.Number != job.Number || j == job {
+			continue
+		}
+		j.NodeID = job.NodeID
+		j.Status = job.Status
+		j.ExitCode = job.ExitCode
+		j.Started = job.Started
+		j.Finished = job.Finished
+	}
+}
+
 func (u *updater) SetLogs(c context.Context, r *Task, rc io.ReadCloser) error {
 	return store.WriteLog(c, r.Job, rc)
 }
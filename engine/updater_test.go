@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/franela/goblin"
+)
+
+func TestUpdater(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Updater", func() {
+
+		var task *Task
+		var u *updater
+
+		g.BeforeEach(func() {
+			u = &updater{bus: newEventbus()}
+			task = fakeTask()
+			task.Repo.JobStatus = true
+			task.Jobs = append(task.Jobs, &model.Job{ID: 3, Number: 2, Status: model.StatusPending})
+			task.Job = task.Jobs[0]
+			task.Job.Status = model.StatusRunning
+		})
+
+		g.It("Should report the status of each job", func() {
+			c, s := fakeContext()
+			u.SetJob(c, task)
+			g.Assert(s.jobStatuses).Equal([]string{model.StatusRunning})
+		})
+
+		g.It("Should link each job status to the job page", func() {
+			c, s := fakeContext()
+			u.SetJob(c, task)
+			g.Assert(s.jobLinks).Equal([]string{"http://drone.local/repos/octocat/hello-world/builds/1/1"})
+		})
+
+		g.It("Should not report job statuses when disabled", func() {
+			task.Repo.JobStatus = false
+			c, s := fakeContext()
+			u.SetJob(c, task)
+			g.Assert(len(s.jobStatuses)).Equal(0)
+		})
+
+		g.It("Should not report job statuses for a single job", func() {
+			task.Jobs = task.Jobs[:1]
+			c, s := fakeContext()
+			u.SetJob(c, task)
+			g.Assert(len(s.jobStatuses)).Equal(0)
+		})
+	})
+}
@@ -2,8 +2,57 @@ package engine
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/shared/envconfig"
 )
 
+// proxyEnvs returns the HTTP_PROXY variables from the environment
+// as a list of "key=value" strings. This is a quick fix to propogate
+// the proxy variables throughout the build environment.
+func proxyEnvs(env envconfig.Env) []string {
+	var envs []string
+	var proxyVars = []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy"}
+	for _, proxyVar := range proxyVars {
+		proxyVal := env.Get(proxyVar)
+		if len(proxyVal) != 0 {
+			envs = append(envs, proxyVar+"="+proxyVal)
+		}
+	}
+	return envs
+}
+
+// buildStatus returns the overall build status, which is the
+// status of the first job that did not complete successfully.
+// Skipped jobs and jobs that are allowed to fail are ignored.
+func buildStatus(jobs []*model.Job) string {
+	for _, job := range jobs {
+		if job.AllowFailure {
+			continue
+		}
+		if job.Status != model.StatusSuccess && job.Status != model.StatusSkipped {
+			return job.Status
+		}
+	}
+	return model.StatusSuccess
+}
+
+// jobLink returns the url of the job page of the build,
+// which is the /repos/:owner/:name/builds/:number/:job route.
+func jobLink(r *Task, job *model.Job) string {
+	return fmt.Sprintf("%s/repos/%s/builds/%d/%d", r.System.Link, r.Repo.FullName, r.Build.Number, job.Number)
+}
+
+// splitList splits a comma or space separated list.
+func splitList(list string) []string {
+	return strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
 func encodeToLegacyFormat(t *Task) ([]byte, error) {
 	// t.System.Plugins = append(t.System.Plugins, "plugins/*")
 
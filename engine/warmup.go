@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/shared/envconfig"
+	"github.com/samalba/dockerclient"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// warmer pulls the build agent and commonly used images onto
+// the nodes so that builds do not spend time pulling them.
+type warmer struct {
+	sync.Mutex
+
+	// images is the list of images to pull.
+	images []string
+
+	// interval is the time between scheduled pulls. Scheduled
+	// pulls are disabled if the interval is zero.
+	interval time.Duration
+
+	// wait is true if a node must finish pulling the images
+	// before it is given work.
+	wait bool
+
+	// last is the time of the last scheduled pull.
+	last time.Time
+
+	// warming records the nodes currently pulling images.
+	warming map[*model.Node]bool
+}
+
+// loadWarmer returns a warmer that pulls the build agent, the
+// images specified in the environment and the plugins in the
+// plugin whitelist. Whitelist entries with glob patterns cannot
+// be pulled and are ignored.
+func loadWarmer(env envconfig.Env) *warmer {
+	images := []string{DefaultAgent}
+	images = append(images, splitList(env.String("NODE_WARMUP_IMAGES", ""))...)
+	for _, plugin := range splitList(env.String("PLUGIN_FILTER", "")) {
+		if !strings.ContainsAny(plugin, "*?[") {
+			images = append(images, plugin)
+		}
+	}
+	return newWarmer(
+		images,
+		env.Duration("NODE_WARMUP_INTERVAL", 24*time.Hour),
+		env.Bool("NODE_WARMUP_WAIT", false),
+	)
+}
+
+func newWarmer(images []string, interval time.Duration, wait bool) *warmer {
+	w := &warmer{
+		interval: interval,
+		wait:     wait,
+		warming:  make(map[*model.Node]bool),
+	}
+	seen := map[string]bool{}
+	for _, image := range images {
+		if !seen[image] {
+			seen[image] = true
+			w.images = append(w.images, image)
+		}
+	}
+	return w
+}
+
+// Due returns true if the next scheduled pull is due.
+func (w *warmer) due() bool {
+	return w.interval > 0 && time.Since(w.last) >= w.interval
+}
+
+// Start marks the node as warming. It returns false if
+// the node is already warming.
+func (w *warmer) start(node *model.Node) bool {
+	w.Lock()
+	defer w.Unlock()
+	if w.warming[node] {
+		return false
+	}
+	w.warming[node] = true
+	return true
+}
+
+// Done marks the node as warm.
+func (w *warmer) done(node *model.Node) {
+	w.Lock()
+	defer w.Unlock()
+	delete(w.warming, node)
+}
+
+// Pull pulls the images onto the node. It returns the
+// number of images that could not be pulled.
+func (w *warmer) pull(client dockerclient.Client, addr string) int {
+	var failed int
+	for _, image := range w.images {
+		err := client.PullImage(image, nil)
+		if err != nil {
+			log.Warnf("error pulling image %s on docker daemon %s. %s", image, addr, err)
+			failed++
+		}
+	}
+	return failed
+}
+
+// allocate allocates the node to the pool and pulls the images
+// in the background. If the warm-up must complete before the node
+// accepts work, the node is parked until the images are pulled.
+func (e *engine) allocate(node *model.Node) {
+	if e.warm.wait {
+		e.pool.allocateParked(node, parkWarmup)
+	} else {
+		e.pool.allocate(node)
+	}
+	go e.warmup(node)
+}
+
+// warmup pulls the images onto the node and unparks the node if
+// it was waiting for the warm-up to complete.
+func (e *engine) warmup(node *model.Node) {
+	if !e.warm.start(node) {
+		return
+	}
+	defer func() {
+		e.warm.done(node)
+		e.pool.unpark(node, parkWarmup)
+	}()
+
+	client, err := newDockerClient(node.Addr, node.Cert, node.Key, node.CA)
+	if err != nil {
+		log.Errorf("error creating docker client %s. %s.", node.Addr, err)
+		return
+	}
+
+	log.Infof("pulling %d images on docker daemon %s", len(e.warm.images), node.Addr)
+	failed := e.warm.pull(client, node.Addr)
+	log.Infof("pulled %d of %d images on docker daemon %s", len(e.warm.images)-failed, len(e.warm.images), node.Addr)
+}
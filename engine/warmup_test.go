@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/shared/envconfig"
+	"github.com/franela/goblin"
+	"github.com/samalba/dockerclient"
+)
+
+func TestWarmer(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Warmer", func() {
+
+		g.It("Should load the images to pull", func() {
+			env := envconfig.Env{
+				"NODE_WARMUP_IMAGES": "golang:1.5, node:5",
+				"PLUGIN_FILTER":      "plugins/* plugins/drone-git node:5",
+			}
+			w := loadWarmer(env)
+			g.Assert(w.images).Equal([]string{DefaultAgent, "golang:1.5", "node:5", "plugins/drone-git"})
+			g.Assert(w.wait).IsFalse()
+		})
+
+		g.It("Should pull the images", func() {
+			client := &fakePuller{fail: "node:5"}
+			w := newWarmer([]string{"golang:1.5", "node:5"}, 0, false)
+			g.Assert(w.pull(client, "unix:///var/run/docker.sock")).Equal(1)
+			g.Assert(client.pulled).Equal([]string{"golang:1.5", "node:5"})
+		})
+
+		g.It("Should not warm a node twice", func() {
+			n := &model.Node{}
+			w := newWarmer(nil, 0, false)
+			g.Assert(w.start(n)).IsTrue()
+			g.Assert(w.start(n)).IsFalse()
+			w.done(n)
+			g.Assert(w.start(n)).IsTrue()
+		})
+
+		g.It("Should park the node until warm", func() {
+			n := &model.Node{Addr: "tcp://127.0.0.1:0"}
+			e := &engine{pool: newPool(), warm: newWarmer(nil, 0, true)}
+			e.pool.allocateParked(n, parkWarmup)
+			g.Assert(e.pool.isParked(n)).IsTrue()
+			e.warmup(n)
+			g.Assert(e.pool.isParked(n)).IsFalse()
+			g.Assert(e.pool.claim(<-e.pool.reserve())).IsTrue()
+		})
+	})
+}
+
+// fakePuller is a fake docker client that records
+// the pulled images.
+type fakePuller struct {
+	dockerclient.Client
+	fail   string
+	pulled []string
+}
+
+func (f *fakePuller) PullImage(name string, auth *dockerclient.AuthConfig) error {
+	f.pulled = append(f.pulled, name)
+	if name == f.fail {
+		return errors.New("pull failed")
+	}
+	return nil
+}
@@ -23,6 +23,11 @@ type Build struct {
 	Avatar    string `json:"author_avatar" meddler:"build_avatar"`
 	Email     string `json:"author_email"  meddler:"build_email"`
 	Link      string `json:"link_url"      meddler:"build_link"`
+
+	// Waiting is the concurrency group the build is waiting
+	// on before it can start. It is empty if the build is
+	// not waiting.
+	Waiting string `json:"waiting_on,omitempty" meddler:"build_waiting"`
 }
 
 type BuildGroup struct {
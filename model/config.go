@@ -0,0 +1,13 @@
+package model
+
+// Config is a snapshot of the configuration a build ran with, which
+// is the yaml file, the encrypted secrets file and the calculated
+// matrix axes. It is used to restart the build with the same
+// configuration.
+type Config struct {
+	ID      int64               `json:"-"      meddler:"config_id,pk"`
+	BuildID int64               `json:"-"      meddler:"config_build_id"`
+	Data    string              `json:"config" meddler:"config_data"`
+	Secret  string              `json:"-"      meddler:"config_secret"`
+	Axes    []map[string]string `json:"axes"   meddler:"config_axes,json"`
+}
@@ -0,0 +1,20 @@
+package model
+
+// Delivery is an inbound hook received from the remote, and the
+// decision taken when the hook was processed. Secrets are redacted
+// from the header, url and payload before the delivery is stored.
+type Delivery struct {
+	ID       int64               `json:"id"           meddler:"delivery_id,pk"`
+	RepoID   int64               `json:"-"            meddler:"delivery_repo_id"`
+	Remote   string              `json:"remote"       meddler:"delivery_remote"`
+	Method   string              `json:"method"       meddler:"delivery_method"`
+	URL      string              `json:"url"          meddler:"delivery_url"`
+	Header   map[string][]string `json:"header"       meddler:"delivery_header,json"`
+	Payload  string              `json:"payload"      meddler:"delivery_payload"`
+	Status   int                 `json:"status"       meddler:"delivery_status"`
+	Decision string              `json:"decision"     meddler:"delivery_decision"`
+	Error    string              `json:"error"        meddler:"delivery_error"`
+	Build    int                 `json:"build_number" meddler:"delivery_build"`
+	Replay   int64               `json:"replay_of"    meddler:"delivery_replay"`
+	Created  int64               `json:"created_at"   meddler:"delivery_created"`
+}
@@ -1,5 +1,11 @@
 package model
 
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
 type Job struct {
 	ID       int64  `json:"id"           meddler:"job_id,pk"`
 	BuildID  int64  `json:"-"            meddler:"job_build_id"`
@@ -12,4 +18,37 @@ type Job struct {
 	Finished int64  `json:"finished_at"  meddler:"job_finished"`
 
 	Environment map[string]string `json:"environment" meddler:"job_environment,json"`
+
+	// Name is the name of the job declared in the jobs section
+	// of the yaml file, and DependsOn the numbers of the jobs in
+	// the build that must complete successfully before it starts.
+	Name      string `json:"name,omitempty"       meddler:"job_name"`
+	DependsOn []int  `json:"depends_on,omitempty" meddler:"job_depends_on,json"`
+
+	// AllowFailure is true if the job matches the allowed failures
+	// of the build matrix and does not affect the build status.
+	AllowFailure bool `json:"allow_failure" meddler:"job_allow_failure"`
+}
+
+// Label returns a short name that identifies the job within its
+// build: the job name, else its matrix variables, else its number.
+func (j *Job) Label() string {
+	if len(j.Name) != 0 {
+		return j.Name
+	}
+	var keys []string
+	for k := range j.Environment {
+		if k != "DRONE_JOB_NAME" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return strconv.Itoa(j.Number)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, k+"="+j.Environment[k])
+	}
+	return strings.Join(parts, " ")
 }
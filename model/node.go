@@ -33,4 +33,26 @@ type Node struct {
 	Cert string `meddler:"node_cert"  json:"-"`
 	Key  string `meddler:"node_key"   json:"-"`
 	CA   string `meddler:"node_ca"    json:"-"`
+
+	// Machine identifies the machine created by the node provisioner.
+	// It is empty for nodes registered by an administrator.
+	Machine string `meddler:"node_machine" json:"machine,omitempty"`
+
+	// Resources reported by the docker daemon, refreshed when the
+	// node is allocated and periodically after that. Memory and
+	// DiskFree are in bytes. DiskFree is zero if the storage driver
+	// does not report the available space.
+	Version    string `meddler:"node_version"    json:"version"`
+	Driver     string `meddler:"node_driver"     json:"storage_driver"`
+	CPUs       int64  `meddler:"node_cpus"       json:"cpus"`
+	Memory     int64  `meddler:"node_memory"     json:"memory"`
+	Containers int64  `meddler:"node_containers" json:"containers"`
+	DiskFree   int64  `meddler:"node_disk_free"  json:"disk_free"`
+	Updated    int64  `meddler:"node_updated"    json:"updated_at"`
+
+	// Reclaimed is the disk space in bytes reclaimed by the
+	// last garbage collection of unused images, containers
+	// and volumes on the node.
+	Reclaimed int64 `meddler:"node_reclaimed" json:"reclaimed"`
+	Collected int64 `meddler:"node_collected" json:"collected_at"`
 }
@@ -26,5 +26,11 @@ type Repo struct {
 	AllowPush   bool   `json:"allow_push"        meddler:"repo_allow_push"`
 	AllowDeploy bool   `json:"allow_deploys"     meddler:"repo_allow_deploys"`
 	AllowTag    bool   `json:"allow_tags"        meddler:"repo_allow_tags"`
+	Poll        bool   `json:"poll"              meddler:"repo_poll"`
+	JobStatus   bool   `json:"job_status"        meddler:"repo_job_status"`
+	Comment     bool   `json:"comment"           meddler:"repo_comment"`
+	CommentLogs int    `json:"comment_log_lines" meddler:"repo_comment_log_lines"`
+	Remote      string `json:"remote"            meddler:"repo_remote"`
 	Hash        string `json:"-"                 meddler:"repo_hash"`
+	HookSecret  string `json:"-"                 meddler:"repo_hook_secret"`
 }
@@ -11,4 +11,5 @@ type User struct {
 	Active bool   `json:"active,"    meddler:"user_active"`
 	Admin  bool   `json:"admin,"     meddler:"user_admin"`
 	Hash   string `json:"-"          meddler:"user_hash"`
+	Remote string `json:"remote"     meddler:"user_remote"`
 }
@@ -0,0 +1,76 @@
+package poller
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+)
+
+const refHeads = "refs/heads/"
+
+// lsRemote returns the branch heads of the remote repository,
+// mapping branch names to commit shas. The netrc credentials are
+// written to the home directory of the git command, so that they
+// are not exposed in the command arguments. The command is killed
+// if it does not complete before the timeout.
+func lsRemote(rawurl string, netrc *model.Netrc, timeout time.Duration) (map[string]string, error) {
+	home, err := ioutil.TempDir("", "drone-poll")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(home)
+
+	if netrc != nil && len(netrc.Machine) != 0 {
+		data := fmt.Sprintf("machine %s login %s password %s\n", netrc.Machine, netrc.Login, netrc.Password)
+		err = ioutil.WriteFile(filepath.Join(home, ".netrc"), []byte(data), 0600)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "ls-remote", "--heads", rawurl)
+	cmd.Env = append(os.Environ(), "HOME="+home, "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-remote failed. %s", err)
+	}
+
+	// kill the command if it does not complete in time.
+	timer := time.AfterFunc(timeout, func() {
+		cmd.Process.Kill()
+	})
+	err = cmd.Wait()
+	if !timer.Stop() {
+		return nil, fmt.Errorf("git ls-remote failed. Timeout after %s. %s", timeout, strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("git ls-remote failed. %s %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseHeads(stdout.Bytes()), nil
+}
+
+// parseHeads parses the output of git ls-remote, where
+// each line is the commit sha and the ref name.
+func parseHeads(out []byte) map[string]string {
+	heads := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], refHeads) {
+			continue
+		}
+		heads[strings.TrimPrefix(fields[1], refHeads)] = fields[0]
+	}
+	return heads
+}
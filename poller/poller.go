@@ -0,0 +1,181 @@
+package poller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/shared/envconfig"
+	"github.com/CiscoCloud/drone/store"
+	"golang.org/x/net/context"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// TriggerFunc creates and schedules the build for the
+// repository owned by the user.
+type TriggerFunc func(c context.Context, user *model.User, repo *model.Repo, build *model.Build) error
+
+// Poller polls the repositories flagged for polling for new and
+// changed branch heads, for remotes or networks where hooks cannot
+// be delivered, and triggers a push build for each change.
+type Poller struct {
+	// interval is the time between polls. Polling
+	// is disabled if the interval is zero.
+	interval time.Duration
+
+	// timeout is the time after which git ls-remote is
+	// killed, so that an unresponsive remote does not
+	// block polling.
+	timeout time.Duration
+
+	store   store.Store
+	remotes *remote.Remotes
+	trigger TriggerFunc
+
+	// heads are the branch heads of each repository
+	// seen by the last poll, by repository id.
+	heads map[int64]map[string]string
+
+	done chan struct{}
+}
+
+// Load returns a poller for the store and remotes with the
+// polling interval specified in the environment variables.
+func Load(env envconfig.Env, s store.Store, r *remote.Remotes, trigger TriggerFunc) *Poller {
+	p := New(s, r, trigger, env.Duration("POLL_INTERVAL", 5*time.Minute))
+	p.timeout = env.Duration("POLL_TIMEOUT", p.timeout)
+	return p
+}
+
+// New returns a poller that polls the repositories
+// at the given interval.
+func New(s store.Store, r *remote.Remotes, trigger TriggerFunc, interval time.Duration) *Poller {
+	return &Poller{
+		interval: interval,
+		timeout:  time.Minute,
+		store:    s,
+		remotes:  r,
+		trigger:  trigger,
+		heads:    map[int64]map[string]string{},
+		done:     make(chan struct{}),
+	}
+}
+
+// Start polls the repositories in the background
+// until the poller is stopped.
+func (p *Poller) Start() {
+	if p.interval == 0 {
+		return
+	}
+	log.Infof("polling repositories every %s", p.interval)
+
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+				p.Poll()
+			}
+		}
+	}()
+}
+
+// Stop stops polling the repositories.
+func (p *Poller) Stop() {
+	if p.interval == 0 {
+		return
+	}
+	close(p.done)
+}
+
+// Poll polls each repository flagged for polling once.
+func (p *Poller) Poll() {
+	c := context.WithValue(context.Background(), "store", p.store)
+	repos, err := store.GetRepoPollList(c)
+	if err != nil {
+		log.Errorf("error listing repositories to poll. %s", err)
+		return
+	}
+	for _, repo := range repos {
+		err := p.poll(c, repo)
+		if err != nil {
+			log.Errorf("error polling %s. %s", repo.FullName, err)
+		}
+	}
+}
+
+// poll lists the branch heads of the repository and triggers a
+// build for each head that is new or changed since the last poll,
+// and that was not already built, for example by a hook.
+func (p *Poller) poll(c context.Context, repo *model.Repo) error {
+	if repo.UserID == 0 || !repo.AllowPush {
+		return nil
+	}
+	remote_ := p.remotes.Get(repo.Remote)
+	if remote_ == nil {
+		return fmt.Errorf("unknown remote %s", repo.Remote)
+	}
+	c = context.WithValue(c, "remote", remote_)
+
+	user, err := store.GetUser(c, repo.UserID)
+	if err != nil {
+		return err
+	}
+
+	// refresh the access token prior to generating the
+	// netrc, since the token may be stale.
+	if refresher, ok := remote_.(remote.Refresher); ok {
+		ok, _ := refresher.Refresh(user)
+		if ok {
+			store.UpdateUser(c, user)
+		}
+	}
+	netrc, err := remote_.Netrc(user, repo)
+	if err != nil {
+		return err
+	}
+	heads, err := lsRemote(repo.Clone, netrc, p.timeout)
+	if err != nil {
+		return err
+	}
+
+	seen, ok := p.heads[repo.ID]
+	for branch, sha := range heads {
+		if ok && seen[branch] == sha {
+			continue
+		}
+		if _, err := store.GetBuildCommit(c, repo, sha, branch); err == nil {
+			continue
+		}
+
+		// the heads are not known the first time the repository is
+		// polled, for example after a restart. Only branches that
+		// were built before are built, so that enabling polling
+		// does not build every branch of the repository.
+		if !ok {
+			if _, err := store.GetBuildLast(c, repo, branch); err != nil {
+				continue
+			}
+		}
+
+		log.Infof("polling found new commit %s for %s branch %s", sha, repo.FullName, branch)
+		build := &model.Build{
+			Event:     model.EventPush,
+			Commit:    sha,
+			Branch:    branch,
+			Ref:       refHeads + branch,
+			Timestamp: time.Now().UTC().Unix(),
+		}
+		err := p.trigger(c, user, repo, build)
+		if err != nil {
+			log.Errorf("error triggering build for %s branch %s. %s", repo.FullName, branch, err)
+		}
+	}
+	p.heads[repo.ID] = heads
+	return nil
+}
@@ -0,0 +1,186 @@
+package poller
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/store/datastore"
+	"github.com/franela/goblin"
+	"golang.org/x/net/context"
+)
+
+func TestPoller(t *testing.T) {
+	s := datastore.New("sqlite3", ":memory:")
+
+	user := &model.User{Login: "octocat", Token: "token", Hash: "hash"}
+	s.Users().Create(user)
+
+	remotes := remote.NewRemotes()
+	remotes.Add("github", &fakeRemote{})
+
+	g := goblin.Goblin(t)
+	g.Describe("Poller", func() {
+
+		var dir, work string
+		var repo *model.Repo
+		var triggered []*model.Build
+
+		trigger := func(c context.Context, u *model.User, r *model.Repo, b *model.Build) error {
+			triggered = append(triggered, b)
+			return nil
+		}
+
+		// helper function to commit to the working copy
+		// and push to the bare repository.
+		commit := func(branch string) string {
+			git(work, "checkout", "-q", "-B", branch)
+			git(work, "-c", "user.name=octocat", "-c", "user.email=octocat@github.com",
+				"commit", "-q", "--allow-empty", "-m", "update")
+			git(work, "push", "-q", "origin", branch)
+			return git(work, "rev-parse", "HEAD")
+		}
+
+		g.BeforeEach(func() {
+			dir, _ = ioutil.TempDir("", "drone-poller")
+			work = filepath.Join(dir, "work")
+			git(dir, "init", "-q", "--bare", "remote.git")
+			git(dir, "clone", "-q", filepath.Join(dir, "remote.git"), "work")
+
+			triggered = nil
+			repo = &model.Repo{
+				UserID:    user.ID,
+				Owner:     "octocat",
+				Name:      filepath.Base(dir),
+				FullName:  "octocat/" + filepath.Base(dir),
+				Clone:     filepath.Join(dir, "remote.git"),
+				AllowPush: true,
+				Poll:      true,
+			}
+			s.Repos().Create(repo)
+		})
+
+		g.AfterEach(func() {
+			s.Repos().Delete(repo)
+			os.RemoveAll(dir)
+		})
+
+		g.It("Should list the branch heads", func() {
+			sha := commit("master")
+			heads, err := lsRemote(repo.Clone, &model.Netrc{}, time.Minute)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(heads).Equal(map[string]string{"master": sha})
+		})
+
+		g.It("Should fail to list a missing repository", func() {
+			_, err := lsRemote(filepath.Join(dir, "missing.git"), nil, time.Minute)
+			g.Assert(err != nil).IsTrue()
+		})
+
+		g.It("Should kill git after the timeout", func() {
+			_, err := lsRemote(repo.Clone, nil, 0)
+			g.Assert(err != nil).IsTrue()
+			g.Assert(strings.Contains(err.Error(), "Timeout")).IsTrue()
+		})
+
+		g.It("Should build new and changed branches", func() {
+			commit("master")
+			p := New(s, remotes, trigger, 0)
+			p.Poll()
+			g.Assert(len(triggered)).Equal(0)
+
+			sha := commit("master")
+			p.Poll()
+			g.Assert(len(triggered)).Equal(1)
+			g.Assert(triggered[0].Event).Equal(model.EventPush)
+			g.Assert(triggered[0].Commit).Equal(sha)
+			g.Assert(triggered[0].Branch).Equal("master")
+			g.Assert(triggered[0].Ref).Equal("refs/heads/master")
+
+			p.Poll()
+			g.Assert(len(triggered)).Equal(1)
+
+			sha = commit("feature")
+			p.Poll()
+			g.Assert(len(triggered)).Equal(2)
+			g.Assert(triggered[1].Commit).Equal(sha)
+			g.Assert(triggered[1].Branch).Equal("feature")
+		})
+
+		g.It("Should not build commits that were already built", func() {
+			commit("master")
+			p := New(s, remotes, trigger, 0)
+			p.Poll()
+
+			sha := commit("master")
+			s.Builds().Create(&model.Build{RepoID: repo.ID, Event: model.EventPush, Commit: sha, Branch: "master"})
+			p.Poll()
+			g.Assert(len(triggered)).Equal(0)
+		})
+
+		g.It("Should build changed branches after a restart", func() {
+			sha := commit("master")
+			s.Builds().Create(&model.Build{RepoID: repo.ID, Event: model.EventPush, Commit: sha, Branch: "master"})
+			commit("feature")
+
+			sha = commit("master")
+			p := New(s, remotes, trigger, 0)
+			p.Poll()
+			g.Assert(len(triggered)).Equal(1)
+			g.Assert(triggered[0].Commit).Equal(sha)
+			g.Assert(triggered[0].Branch).Equal("master")
+		})
+
+		g.It("Should not poll repositories of unknown remotes", func() {
+			commit("master")
+			repo.Remote = "gitlab"
+			s.Repos().Update(repo)
+
+			p := New(s, remotes, trigger, 0)
+			p.Poll()
+			commit("master")
+			p.Poll()
+			g.Assert(len(triggered)).Equal(0)
+		})
+
+		g.It("Should not poll repositories disabled for push", func() {
+			commit("master")
+			repo.AllowPush = false
+			s.Repos().Update(repo)
+
+			p := New(s, remotes, trigger, 0)
+			p.Poll()
+			commit("master")
+			p.Poll()
+			g.Assert(len(triggered)).Equal(0)
+		})
+	})
+}
+
+// fakeRemote is a remote that returns empty netrc
+// credentials, which are not needed for local repositories.
+type fakeRemote struct {
+	remote.Remote
+}
+
+func (r *fakeRemote) Netrc(u *model.User, repo *model.Repo) (*model.Netrc, error) {
+	return &model.Netrc{}, nil
+}
+
+// helper function to run the git command in the
+// directory, and return the trimmed output.
+func git(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		panic(string(out))
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/CiscoCloud/drone/model"
 	"github.com/CiscoCloud/drone/shared/envconfig"
 	"github.com/CiscoCloud/drone/shared/httputil"
+	"github.com/CiscoCloud/drone/shared/statuskey"
 
 	log "github.com/Sirupsen/logrus"
 	"golang.org/x/oauth2"
@@ -267,7 +268,48 @@ func (bb *Bitbucket) Script(u *model.User, r *model.Repo, b *model.Build) ([]byt
 // Status sends the commit status to the remote system.
 // An example would be the GitHub pull request status.
 func (bb *Bitbucket) Status(u *model.User, r *model.Repo, b *model.Build, link string) error {
-	return nil
+	status := BuildStatus{
+		State: getStatus(b.Status),
+		Key:   statuskey.Key(r, nil),
+		Name:  StatusName,
+		Url:   link,
+		Desc:  getDesc(b.Status),
+	}
+	return bb.newClient(u).CreateStatus(r.Owner, r.Name, b.Commit, &status)
+}
+
+// JobStatus sends the status of a single build job to the remote
+// system, under a build key that is unique to the job.
+func (bb *Bitbucket) JobStatus(u *model.User, r *model.Repo, b *model.Build, j *model.Job, link string) error {
+	status := BuildStatus{
+		State: getStatus(j.Status),
+		Key:   statuskey.Key(r, j),
+		Name:  StatusName + ": " + j.Label(),
+		Url:   link,
+		Desc:  getDesc(j.Status),
+	}
+	return bb.newClient(u).CreateStatus(r.Owner, r.Name, b.Commit, &status)
+}
+
+// Comment creates the build summary comment on the pull request, or
+// edits the comment of a previous build of the pull request in place.
+func (bb *Bitbucket) Comment(u *model.User, r *model.Repo, b *model.Build, body string) error {
+	id, err := pullRequestID(b.Link)
+	if err != nil {
+		return err
+	}
+
+	client := bb.newClient(u)
+	comment, err := getComment(client, r.Owner, r.Name, id, u.Login, commentMarker)
+	if err != nil {
+		return err
+	}
+
+	data := Comment{Content: Content{Raw: commentMarker + "\n\n" + body}}
+	if comment != nil {
+		return client.UpdateComment(r.Owner, r.Name, id, comment.ID, &data)
+	}
+	return client.CreateComment(r.Owner, r.Name, id, &data)
 }
 
 // Netrc returns a .netrc file that can be used to clone
@@ -375,6 +417,17 @@ func (bb *Bitbucket) Hook(r *http.Request) (*model.Repo, *model.Build, error) {
 	return nil, nil, nil
 }
 
+func (bb *Bitbucket) newClient(u *model.User) *Client {
+	return NewClientToken(
+		bb.Client,
+		bb.Secret,
+		&oauth2.Token{
+			AccessToken:  u.Token,
+			RefreshToken: u.Secret,
+		},
+	)
+}
+
 func (bb *Bitbucket) String() string {
 	return "bitbucket"
 }
@@ -450,3 +503,56 @@ func (bb *Bitbucket) pullHook(r *http.Request) (*model.Repo, *model.Build, error
 		Timestamp: hook.PullRequest.Updated.UTC().Unix(),
 	}, nil
 }
+
+const StatusName = "Drone"
+
+// commentMarker is the first line of the build summary comment, used
+// to find the comment of a previous build. It is not rendered.
+const commentMarker = "[//]: # (drone)"
+
+const (
+	StatusPending = "INPROGRESS"
+	StatusSuccess = "SUCCESSFUL"
+	StatusFailure = "FAILED"
+	StatusStopped = "STOPPED"
+)
+
+const (
+	DescPending = "this build is pending"
+	DescSuccess = "the build was successful"
+	DescFailure = "the build failed"
+	DescError   = "oops, something went wrong"
+	DescKilled  = "the build was killed"
+)
+
+// getStatus is a helper function that converts a Drone
+// status to a Bitbucket build status.
+func getStatus(status string) string {
+	switch status {
+	case model.StatusPending, model.StatusRunning:
+		return StatusPending
+	case model.StatusSuccess:
+		return StatusSuccess
+	case model.StatusFailure, model.StatusError:
+		return StatusFailure
+	default:
+		return StatusStopped
+	}
+}
+
+// getDesc is a helper function that generates a description
+// message for the build based on the status.
+func getDesc(status string) string {
+	switch status {
+	case model.StatusPending, model.StatusRunning:
+		return DescPending
+	case model.StatusSuccess:
+		return DescSuccess
+	case model.StatusFailure:
+		return DescFailure
+	case model.StatusError:
+		return DescError
+	default:
+		return DescKilled
+	}
+}
@@ -0,0 +1,126 @@
+package bitbucket
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/remote/bitbucket/testdata"
+	"github.com/CiscoCloud/drone/shared/statuskey"
+	"github.com/franela/goblin"
+)
+
+func Test_Bitbucket(t *testing.T) {
+	// setup a dummy bitbucket server
+	var server = testdata.NewServer()
+	defer server.Close()
+
+	// the client always sends requests to the bitbucket api,
+	// so requests are rewritten to the dummy server.
+	serverURL, _ := url.Parse(server.URL)
+	client := NewClient(&http.Client{Transport: rewriter(serverURL.Host)})
+
+	var repo = model.Repo{
+		Owner:    "octocat",
+		Name:     "hello-world",
+		FullName: "octocat/hello-world",
+	}
+
+	g := goblin.Goblin(t)
+	g.Describe("Bitbucket Plugin", func() {
+
+		g.Describe("Status", func() {
+			g.It("Should create a build status", func() {
+				status := BuildStatus{
+					State: getStatus(model.StatusSuccess),
+					Key:   statuskey.Key(&repo, nil),
+					Name:  StatusName,
+					Url:   "http://drone.example.com/octocat/hello-world/1",
+					Desc:  getDesc(model.StatusSuccess),
+				}
+				err := client.CreateStatus("octocat", "hello-world", "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d", &status)
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should return an error, when the status is invalid", func() {
+				status := BuildStatus{State: getStatus(model.StatusSuccess)}
+				err := client.CreateStatus("octocat", "hello-world", "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d", &status)
+				g.Assert(err != nil).IsTrue()
+				g.Assert(err.(Error).Status).Equal(400)
+				g.Assert(err.Error()).Equal("key: This field is required.")
+			})
+
+			g.It("Should return an error, when the commit is not found", func() {
+				status := BuildStatus{
+					State: getStatus(model.StatusFailure),
+					Key:   statuskey.Key(&repo, nil),
+					Url:   "http://drone.example.com/octocat/hello-world/1",
+				}
+				err := client.CreateStatus("octocat", "hello-world", "0000000000000000000000000000000000000000", &status)
+				g.Assert(err != nil).IsTrue()
+				g.Assert(err.(Error).Status).Equal(404)
+			})
+
+			g.It("Should map the build status", func() {
+				g.Assert(getStatus(model.StatusPending)).Equal(StatusPending)
+				g.Assert(getStatus(model.StatusRunning)).Equal(StatusPending)
+				g.Assert(getStatus(model.StatusSuccess)).Equal(StatusSuccess)
+				g.Assert(getStatus(model.StatusFailure)).Equal(StatusFailure)
+				g.Assert(getStatus(model.StatusError)).Equal(StatusFailure)
+				g.Assert(getStatus(model.StatusKilled)).Equal(StatusStopped)
+				g.Assert(getStatus(model.StatusSkipped)).Equal(StatusStopped)
+			})
+
+		})
+
+		g.Describe("Comment", func() {
+			g.It("Should find the comment of the user", func() {
+				comment, err := getComment(client, "octocat", "hello-world", 1, "octocat", commentMarker)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(comment.ID).Equal(2)
+			})
+
+			g.It("Should not find the comment of another user", func() {
+				comment, err := getComment(client, "octocat", "hello-world", 1, "spaceghost", commentMarker)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(comment == nil).IsTrue()
+			})
+
+			g.It("Should create the comment", func() {
+				err := client.CreateComment("octocat", "hello-world", 1, &Comment{Content: Content{Raw: "the build summary"}})
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should update the comment", func() {
+				err := client.UpdateComment("octocat", "hello-world", 1, 2, &Comment{Content: Content{Raw: "the build summary"}})
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should return an error, when the pull request is not found", func() {
+				err := client.CreateComment("octocat", "hello-world", 2, &Comment{Content: Content{Raw: "the build summary"}})
+				g.Assert(err != nil).IsTrue()
+				g.Assert(err.(Error).Status).Equal(404)
+			})
+
+			g.It("Should parse the pull request id", func() {
+				id, err := pullRequestID("https://bitbucket.org/octocat/hello-world/pull-requests/12")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(id).Equal(12)
+
+				_, err = pullRequestID("https://bitbucket.org/octocat/hello-world")
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+	})
+}
+
+// rewriter returns a transport that sends all requests to
+// the given host over plain http.
+type rewriter string
+
+func (r rewriter) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = "http"
+	req.URL.Host = string(r)
+	return http.DefaultTransport.RoundTrip(req)
+}
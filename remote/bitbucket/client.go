@@ -30,6 +30,10 @@ const (
 	pathHook   = "%s/2.0/repositories/%s/%s/hooks/%s"
 	pathHooks  = "%s/2.0/repositories/%s/%s/hooks?%s"
 	pathSource = "%s/1.0/repositories/%s/%s/src/%s/%s"
+	pathStatus = "%s/2.0/repositories/%s/%s/commit/%s/statuses/build"
+
+	pathComment  = "%s/2.0/repositories/%s/%s/pullrequests/%d/comments/%d"
+	pathComments = "%s/2.0/repositories/%s/%s/pullrequests/%d/comments?%s"
 )
 
 type Client struct {
@@ -133,6 +137,28 @@ func (c *Client) FindSource(owner, name, revision, path string) (*Source, error)
 	return out, err
 }
 
+func (c *Client) CreateStatus(owner, name, revision string, status *BuildStatus) error {
+	uri := fmt.Sprintf(pathStatus, base, owner, name, revision)
+	return c.do(uri, post, status, nil)
+}
+
+func (c *Client) ListComments(owner, name string, id int, opts *ListOpts) (*CommentResp, error) {
+	out := new(CommentResp)
+	uri := fmt.Sprintf(pathComments, base, owner, name, id, opts.Encode())
+	err := c.do(uri, get, nil, out)
+	return out, err
+}
+
+func (c *Client) CreateComment(owner, name string, id int, comment *Comment) error {
+	uri := fmt.Sprintf(pathComments, base, owner, name, id, "")
+	return c.do(uri, post, comment, nil)
+}
+
+func (c *Client) UpdateComment(owner, name string, id, commentID int, comment *Comment) error {
+	uri := fmt.Sprintf(pathComment, base, owner, name, id, commentID)
+	return c.do(uri, put, comment, nil)
+}
+
 func (c *Client) do(rawurl, method string, in, out interface{}) error {
 
 	uri, err := url.Parse(rawurl)
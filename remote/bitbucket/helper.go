@@ -1,7 +1,9 @@
 package bitbucket
 
 import (
+	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/CiscoCloud/drone/model"
@@ -99,3 +101,38 @@ func convertRepoLite(from *Repo) *model.RepoLite {
 		Avatar:   from.Owner.Links.Avatar.Href,
 	}
 }
+
+// getComment is a helper function that retrieves the comment of the
+// user on the pull request that starts with the marker. To do this, it
+// will retrieve a list of all comments and iterate through the list.
+func getComment(client *Client, owner, name string, id int, login, marker string) (*Comment, error) {
+	opts := &ListOpts{Page: 1, PageLen: 100}
+	for {
+		resp, err := client.ListComments(owner, name, id, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range resp.Values {
+			if comment.User == nil || comment.User.Login != login {
+				continue
+			}
+			if strings.HasPrefix(comment.Content.Raw, marker) {
+				return comment, nil
+			}
+		}
+		if len(resp.Next) == 0 {
+			return nil, nil
+		}
+		opts.Page++
+	}
+}
+
+// pullRequestID is a helper function that parses the id of the
+// pull request from the pull request url, which ends with the id.
+func pullRequestID(link string) (int, error) {
+	i := strings.LastIndex(link, "/pull-requests/")
+	if i == -1 {
+		return 0, fmt.Errorf("Unable to find the pull request of %s", link)
+	}
+	return strconv.Atoi(strings.Trim(link[i+len("/pull-requests/"):], "/"))
+}
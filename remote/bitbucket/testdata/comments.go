@@ -0,0 +1,46 @@
+package testdata
+
+// sample pull request comments response, first page
+var commentsPayload = []byte(`
+{
+  "pagelen": 1,
+  "page": 1,
+  "size": 2,
+  "next": "https://api.bitbucket.org/2.0/repositories/octocat/hello-world/pullrequests/1/comments?page=2",
+  "values": [
+    {
+      "id": 1,
+      "content": {
+        "raw": "looks good to me"
+      },
+      "user": {
+        "username": "spaceghost",
+        "display_name": "Space Ghost",
+        "type": "user"
+      }
+    }
+  ]
+}
+`)
+
+// sample pull request comments response, last page
+var commentsPage2Payload = []byte(`
+{
+  "pagelen": 1,
+  "page": 2,
+  "size": 2,
+  "values": [
+    {
+      "id": 2,
+      "content": {
+        "raw": "[//]: # (drone)\n\nthe summary of a previous build"
+      },
+      "user": {
+        "username": "octocat",
+        "display_name": "The Octocat",
+        "type": "user"
+      }
+    }
+  ]
+}
+`)
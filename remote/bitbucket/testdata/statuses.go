@@ -0,0 +1,43 @@
+package testdata
+
+// sample build status response
+var statusPayload = []byte(`
+{
+  "key": "drone-4a2d9c6b",
+  "name": "Drone",
+  "url": "http://drone.example.com/octocat/hello-world/1",
+  "state": "SUCCESSFUL",
+  "description": "the build was successful",
+  "type": "build",
+  "created_on": "2016-01-19T08:40:25.934187+00:00",
+  "updated_on": "2016-01-19T08:40:25.934211+00:00",
+  "links": {
+    "commit": {
+      "href": "https://api.bitbucket.org/2.0/repositories/octocat/hello-world/commit/7fd1a60b01f91b314f59955a4e4d4e80d8edf11d"
+    },
+    "self": {
+      "href": "https://api.bitbucket.org/2.0/repositories/octocat/hello-world/commit/7fd1a60b01f91b314f59955a4e4d4e80d8edf11d/statuses/build/drone-4a2d9c6b"
+    }
+  }
+}
+`)
+
+// sample build status error response
+var statusErrorPayload = []byte(`
+{
+  "type": "error",
+  "error": {
+    "message": "key: This field is required."
+  }
+}
+`)
+
+// sample not found error response
+var notFoundPayload = []byte(`
+{
+  "type": "error",
+  "error": {
+    "message": "Repository octocat/hello-world not found"
+  }
+}
+`)
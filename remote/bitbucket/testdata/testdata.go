@@ -0,0 +1,83 @@
+package testdata
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+)
+
+// setup a mock server for testing purposes.
+func NewServer() *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	// handle requests and serve mock data
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// evaluate the path to serve a dummy data file
+		switch r.URL.Path {
+		case "/2.0/repositories/octocat/hello-world/pullrequests/1/comments":
+			switch r.Method {
+			case "GET":
+				if r.FormValue("page") == "2" {
+					w.Write(commentsPage2Payload)
+				} else {
+					w.Write(commentsPayload)
+				}
+				return
+			case "POST":
+				if !validComment(r) {
+					break
+				}
+				w.WriteHeader(201)
+				return
+			}
+		case "/2.0/repositories/octocat/hello-world/pullrequests/1/comments/2":
+			if r.Method != "PUT" || !validComment(r) {
+				break
+			}
+			w.WriteHeader(200)
+			return
+		case "/2.0/repositories/octocat/hello-world/commit/7fd1a60b01f91b314f59955a4e4d4e80d8edf11d/statuses/build":
+			if r.Method != "POST" {
+				break
+			}
+			in := map[string]string{}
+			json.NewDecoder(r.Body).Decode(&in)
+			switch in["state"] {
+			case "INPROGRESS", "SUCCESSFUL", "FAILED", "STOPPED":
+			default:
+				w.WriteHeader(400)
+				w.Write(statusErrorPayload)
+				return
+			}
+			if in["key"] == "" || in["url"] == "" {
+				w.WriteHeader(400)
+				w.Write(statusErrorPayload)
+				return
+			}
+			w.WriteHeader(201)
+			w.Write(statusPayload)
+			return
+		}
+
+		// else return a 404
+		w.WriteHeader(404)
+		w.Write(notFoundPayload)
+	})
+
+	// return the server to the client which
+	// will need to know the base URL path
+	return server
+}
+
+// helper function that returns true if the
+// request body is a comment with content.
+func validComment(r *http.Request) bool {
+	in := struct {
+		Content struct {
+			Raw string `json:"raw"`
+		} `json:"content"`
+	}{}
+	json.NewDecoder(r.Body).Decode(&in)
+	return len(in.Content.Raw) != 0
+}
@@ -21,6 +21,32 @@ type AccountResp struct {
 	Values []*Account `json:"values"`
 }
 
+type BuildStatus struct {
+	State string `json:"state"`
+	Key   string `json:"key"`
+	Name  string `json:"name,omitempty"`
+	Url   string `json:"url"`
+	Desc  string `json:"description,omitempty"`
+}
+
+type Comment struct {
+	ID      int      `json:"id,omitempty"`
+	Content Content  `json:"content"`
+	User    *Account `json:"user,omitempty"`
+}
+
+type CommentResp struct {
+	Page   int        `json:"page"`
+	Pages  int        `json:"pagelen"`
+	Size   int        `json:"size"`
+	Next   string     `json:"next"`
+	Values []*Comment `json:"values"`
+}
+
+type Content struct {
+	Raw string `json:"raw"`
+}
+
 type Email struct {
 	Email       string `json:"email"`
 	IsConfirmed bool   `json:"is_confirmed"`
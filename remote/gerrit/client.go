@@ -0,0 +1,181 @@
+package gerrit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	get  = "GET"
+	put  = "PUT"
+	post = "POST"
+	del  = "DELETE"
+)
+
+const (
+	pathSelf     = "%s/a/accounts/self"
+	pathProjects = "%s/a/projects/?d"
+	pathProject  = "%s/a/projects/%s"
+	pathAccess   = "%s/a/projects/%s/access"
+	pathContent  = "%s/a/projects/%s/commits/%s/files/%s/content"
+	pathReview   = "%s/a/changes/%s~%d/revisions/%s/review"
+	pathWebhook  = "%s/a/config/server/webhooks~projects/%s/remotes/%s"
+)
+
+// magic prefix of json responses, which prevents cross
+// site script inclusion.
+const magicPrefix = ")]}'"
+
+type Client struct {
+	*http.Client
+	base string
+}
+
+// NewClient returns a client for the Gerrit server at the base url
+// that authorizes requests with the username and http password.
+func NewClient(base, username, password string, transport http.RoundTripper) *Client {
+	client := &http.Client{
+		Transport: &basicTransport{username, password, transport},
+	}
+	return &Client{client, strings.TrimSuffix(base, "/")}
+}
+
+func (c *Client) FindCurrent() (*Account, error) {
+	out := new(Account)
+	uri := fmt.Sprintf(pathSelf, c.base)
+	err := c.do(uri, get, nil, out)
+	return out, err
+}
+
+func (c *Client) FindProject(name string) (*Project, error) {
+	out := new(Project)
+	uri := fmt.Sprintf(pathProject, c.base, url.QueryEscape(name))
+	err := c.do(uri, get, nil, out)
+	return out, err
+}
+
+// ListProjects returns the projects visible to the user, keyed
+// by the project name.
+func (c *Client) ListProjects() (map[string]*Project, error) {
+	out := map[string]*Project{}
+	uri := fmt.Sprintf(pathProjects, c.base)
+	err := c.do(uri, get, nil, &out)
+	return out, err
+}
+
+func (c *Client) FindAccess(name string) (*ProjectAccess, error) {
+	out := new(ProjectAccess)
+	uri := fmt.Sprintf(pathAccess, c.base, url.QueryEscape(name))
+	err := c.do(uri, get, nil, out)
+	return out, err
+}
+
+// FindContent returns the contents of the file at the commit.
+func (c *Client) FindContent(name, commit, path string) ([]byte, error) {
+	var out bytes.Buffer
+	uri := fmt.Sprintf(pathContent, c.base, url.QueryEscape(name), commit, url.QueryEscape(path))
+	err := c.do(uri, get, nil, &out)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(out.String())
+}
+
+// SetReview sets a review on the revision of the change in
+// the project.
+func (c *Client) SetReview(name string, change int64, revision string, review *Review) error {
+	uri := fmt.Sprintf(pathReview, c.base, url.QueryEscape(name), change, revision)
+	return c.do(uri, post, review, nil)
+}
+
+// SetWebhook creates or updates the remote of the webhooks plugin
+// for the project.
+func (c *Client) SetWebhook(name, remote string, hook *Webhook) error {
+	uri := fmt.Sprintf(pathWebhook, c.base, url.QueryEscape(name), remote)
+	return c.do(uri, put, hook, nil)
+}
+
+// DeleteWebhook removes the remote of the webhooks plugin
+// for the project.
+func (c *Client) DeleteWebhook(name, remote string) error {
+	uri := fmt.Sprintf(pathWebhook, c.base, url.QueryEscape(name), remote)
+	return c.do(uri, del, nil, nil)
+}
+
+// do sends the request. If out is a bytes.Buffer the response body
+// is written to the buffer as-is, else it is parsed as json.
+func (c *Client) do(rawurl, method string, in, out interface{}) error {
+
+	// if we are posting or putting data, we need to
+	// write it to the body of the request.
+	var buf io.ReadWriter
+	if in != nil {
+		buf = new(bytes.Buffer)
+		err := json.NewEncoder(buf).Encode(in)
+		if err != nil {
+			return err
+		}
+	}
+
+	// creates a new http request to gerrit.
+	req, err := http.NewRequest(method, rawurl, buf)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	// gerrit returns errors as plain text.
+	if resp.StatusCode > http.StatusNoContent {
+		return fmt.Errorf("<%d> %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case *bytes.Buffer:
+		_, err = out.Write(body)
+		return err
+	default:
+		body = bytes.TrimPrefix(body, []byte(magicPrefix))
+		return json.Unmarshal(body, out)
+	}
+}
+
+// basicTransport is an http.RoundTripper that authorizes
+// requests using basic authentication.
+type basicTransport struct {
+	username  string
+	password  string
+	transport http.RoundTripper
+}
+
+func (t *basicTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	clone := new(http.Request)
+	*clone = *req
+	clone.Header = make(http.Header)
+	for k, v := range req.Header {
+		clone.Header[k] = v
+	}
+	clone.SetBasicAuth(t.username, t.password)
+	return t.transport.RoundTrip(clone)
+}
@@ -0,0 +1,280 @@
+package gerrit
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/shared/envconfig"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	DefaultLabel  = "Verified"
+	DefaultRemote = "drone"
+)
+
+type Gerrit struct {
+	URL        string
+	Label      string
+	Open       bool
+	SkipVerify bool
+}
+
+func Load(env envconfig.Env) *Gerrit {
+	config := env.String("REMOTE_CONFIG", "")
+
+	// parse the remote DSN configuration string
+	url_, err := url.Parse(config)
+	if err != nil {
+		log.Fatalf("unable to parse remote dsn. %s", err)
+	}
+	params := url_.Query()
+	url_.RawQuery = ""
+
+	// create the Gerrit remote using parameters from
+	// the parsed DSN configuration string.
+	gerrit := Gerrit{}
+	gerrit.URL = url_.String()
+	gerrit.Label = params.Get("label")
+	gerrit.Open, _ = strconv.ParseBool(params.Get("open"))
+	gerrit.SkipVerify, _ = strconv.ParseBool(params.Get("skip_verify"))
+
+	if len(gerrit.Label) == 0 {
+		gerrit.Label = DefaultLabel
+	}
+
+	return &gerrit
+}
+
+// Login authenticates the session and returns the
+// remote user details.
+func (g *Gerrit) Login(res http.ResponseWriter, req *http.Request) (*model.User, bool, error) {
+	var (
+		username = req.FormValue("username")
+		password = req.FormValue("password")
+	)
+
+	// if the username or password doesn't exist we re-direct
+	// the user to the login screen.
+	if len(username) == 0 || len(password) == 0 {
+		http.Redirect(res, req, "/login/form", http.StatusSeeOther)
+		return nil, false, nil
+	}
+
+	client := NewClient(g.URL, username, password, g.transport())
+	account, err := client.FindCurrent()
+	if err != nil {
+		return nil, false, err
+	}
+
+	user := model.User{}
+	user.Login = account.Username
+	user.Email = account.Email
+	user.Token = password
+	if len(account.Avatars) != 0 {
+		user.Avatar = account.Avatars[len(account.Avatars)-1].Url
+	}
+	return &user, g.Open, nil
+}
+
+// Auth authenticates the session and returns the remote user
+// login for the given token and secret
+func (g *Gerrit) Auth(token, secret string) (string, error) {
+	return "", fmt.Errorf("Method not supported")
+}
+
+// Repo fetches the named repository from the remote system.
+func (g *Gerrit) Repo(u *model.User, owner, name string) (*model.Repo, error) {
+	project, err := g.newClient(u).FindProject(joinProject(owner, name))
+	if err != nil {
+		return nil, err
+	}
+	return convertRepo(g.URL, project.Name), nil
+}
+
+// Repos fetches a list of repos from the remote system.
+func (g *Gerrit) Repos(u *model.User) ([]*model.RepoLite, error) {
+	var repos []*model.RepoLite
+
+	projects, err := g.newClient(u).ListProjects()
+	if err != nil {
+		return repos, err
+	}
+
+	var names []string
+	for name, project := range projects {
+		if systemProjects[name] || project.State == "HIDDEN" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		repos = append(repos, convertRepoLite(name))
+	}
+	return repos, nil
+}
+
+// Perm fetches the named repository permissions from
+// the remote system for the specified user.
+func (g *Gerrit) Perm(u *model.User, owner, name string) (*model.Perm, error) {
+	access, err := g.newClient(u).FindAccess(joinProject(owner, name))
+	if err != nil {
+		return nil, err
+	}
+	return &model.Perm{
+		Pull:  true,
+		Push:  access.CanUpload || access.IsOwner,
+		Admin: access.IsOwner,
+	}, nil
+}
+
+// Script fetches the build script (.drone.yml) from the remote
+// repository and returns in string format.
+func (g *Gerrit) Script(u *model.User, r *model.Repo, b *model.Build) ([]byte, []byte, error) {
+	client := g.newClient(u)
+	project := joinProject(r.Owner, r.Name)
+
+	// fetches the .drone.yml for the specified revision. This file
+	// is required, and will error if not found
+	config, err := client.FindContent(project, b.Commit, ".drone.yml")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// fetches the .drone.sec for the specified revision. This file
+	// is completely optional, therefore we will not return a not
+	// found error
+	sec, _ := client.FindContent(project, b.Commit, ".drone.sec")
+
+	return config, sec, nil
+}
+
+// Status sends the commit status to the remote system. The status
+// of patchset builds is reported as a vote on the verified label,
+// with a review message that links to the build.
+func (g *Gerrit) Status(u *model.User, r *model.Repo, b *model.Build, link string) error {
+	number, ok := changeNumber(b.Ref)
+	if !ok {
+		return nil
+	}
+
+	// the running status is not reported, since the pending
+	// status already resets the vote and links to the build.
+	review := getReview(g.Label, b.Status, link)
+	if review == nil {
+		return nil
+	}
+	return g.newClient(u).SetReview(joinProject(r.Owner, r.Name), number, b.Commit, review)
+}
+
+// Netrc returns a .netrc file that can be used to clone
+// private repositories from a remote system.
+func (g *Gerrit) Netrc(u *model.User, r *model.Repo) (*model.Netrc, error) {
+	url_, err := url.Parse(g.URL)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Netrc{
+		Machine:  url_.Host,
+		Login:    u.Login,
+		Password: u.Token,
+	}, nil
+}
+
+// Activate activates a repository by configuring the webhooks
+// plugin to send the project events.
+func (g *Gerrit) Activate(u *model.User, r *model.Repo, k *model.Key, link string) error {
+	hook := Webhook{
+		Url:    link,
+		Events: []string{eventPatchset, eventRefUpdated},
+	}
+	return g.newClient(u).SetWebhook(joinProject(r.Owner, r.Name), DefaultRemote, &hook)
+}
+
+// Deactivate removes a repository by removing the webhooks
+// plugin remote.
+func (g *Gerrit) Deactivate(u *model.User, r *model.Repo, link string) error {
+	return g.newClient(u).DeleteWebhook(joinProject(r.Owner, r.Name), DefaultRemote)
+}
+
+// Hook parses the post-commit hook from the Request body
+// and returns the required data in a standard format.
+func (g *Gerrit) Hook(r *http.Request) (*model.Repo, *model.Build, error) {
+	hook, err := parseEvent(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch hook.Type {
+	case eventPatchset:
+		return convertRepo(g.URL, hook.Change.Project), buildFromPatchset(hook), nil
+	case eventRefUpdated:
+		build := buildFromRefUpdated(g.URL, hook)
+		if build == nil {
+			return nil, nil, nil
+		}
+		return convertRepo(g.URL, hook.RefUpdate.Project), build, nil
+	}
+	return nil, nil, nil
+}
+
+func (g *Gerrit) String() string {
+	return "gerrit"
+}
+
+// newClient returns a client that authorizes requests as the
+// user with the http password.
+func (g *Gerrit) newClient(u *model.User) *Client {
+	return NewClient(g.URL, u.Login, u.Token, g.transport())
+}
+
+func (g *Gerrit) transport() http.RoundTripper {
+	if !g.SkipVerify {
+		return http.DefaultTransport
+	}
+	return &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+}
+
+const (
+	DescPending = "Build started"
+	DescSuccess = "Build successful"
+	DescFailure = "Build failed"
+	DescError   = "Build error"
+	DescKilled  = "Build killed"
+)
+
+// getReview is a helper function that returns the review of the
+// build status, or nil if the status is not reported.
+func getReview(label, status, link string) *Review {
+	var desc string
+	var vote int
+	switch status {
+	case model.StatusPending:
+		desc, vote = DescPending, 0
+	case model.StatusSuccess:
+		desc, vote = DescSuccess, 1
+	case model.StatusFailure:
+		desc, vote = DescFailure, -1
+	case model.StatusError:
+		desc, vote = DescError, -1
+	case model.StatusKilled:
+		desc, vote = DescKilled, -1
+	default:
+		return nil
+	}
+	return &Review{
+		Message: fmt.Sprintf("%s: %s", desc, link),
+		Labels:  map[string]int{label: vote},
+		Tag:     "autogenerated:drone",
+	}
+}
@@ -0,0 +1,244 @@
+package gerrit
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/remote/gerrit/testdata"
+	"github.com/franela/goblin"
+)
+
+func Test_Gerrit(t *testing.T) {
+	// setup a dummy gerrit server
+	var server = testdata.NewServer()
+	defer server.Close()
+
+	gerrit := Load(map[string]string{"REMOTE_CONFIG": server.URL + "?open=true"})
+
+	var user = model.User{
+		Login: "gordon",
+		Token: "http-password",
+	}
+
+	var repo = model.Repo{
+		Owner:    "go",
+		Name:     "hello-world",
+		FullName: "go/hello-world",
+	}
+
+	var build = model.Build{
+		Number: 1,
+		Event:  model.EventPull,
+		Commit: "9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5",
+		Ref:    "refs/changes/45/12345/2",
+		Status: model.StatusSuccess,
+	}
+
+	g := goblin.Goblin(t)
+	g.Describe("Gerrit Plugin", func() {
+
+		g.It("Should load the configuration", func() {
+			g.Assert(gerrit.URL).Equal(server.URL)
+			g.Assert(gerrit.Label).Equal("Verified")
+			g.Assert(gerrit.Open).IsTrue()
+			g.Assert(gerrit.String()).Equal("gerrit")
+		})
+
+		g.Describe("Login", func() {
+			g.It("Should redirect to the login form", func() {
+				req, _ := http.NewRequest("GET", "http://drone.golang.org/authorize", nil)
+				res := httptest.NewRecorder()
+				u, _, err := gerrit.Login(res, req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(u == nil).IsTrue()
+				g.Assert(res.Header().Get("Location")).Equal("/login/form")
+			})
+
+			g.It("Should login with the http password", func() {
+				form := url.Values{"username": {"gordon"}, "password": {"http-password"}}
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/authorize", bytes.NewBufferString(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				u, open, err := gerrit.Login(httptest.NewRecorder(), req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(open).IsTrue()
+				g.Assert(u.Login).Equal("gordon")
+				g.Assert(u.Email).Equal("gordon@golang.org")
+				g.Assert(u.Token).Equal("http-password")
+				g.Assert(u.Avatar).Equal("https://gerrit.golang.org/avatar/gordon?s=100")
+			})
+
+			g.It("Should fail with an invalid password", func() {
+				form := url.Values{"username": {"gordon"}, "password": {"invalid"}}
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/authorize", bytes.NewBufferString(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				_, _, err := gerrit.Login(httptest.NewRecorder(), req)
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.Describe("Repo", func() {
+			g.It("Should return a valid repo", func() {
+				r, err := gerrit.Repo(&user, "go", "hello-world")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(r.Owner).Equal("go")
+				g.Assert(r.Name).Equal("hello-world")
+				g.Assert(r.FullName).Equal("go/hello-world")
+				g.Assert(r.Clone).Equal(server.URL + "/a/go/hello-world")
+			})
+
+			g.It("Should return an error, when the repo does not exist", func() {
+				_, err := gerrit.Repo(&user, "go", "not-found")
+				g.Assert(err != nil).IsTrue()
+			})
+
+			g.It("Should return the visible repos", func() {
+				repos, err := gerrit.Repos(&user)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(len(repos)).Equal(2)
+				g.Assert(repos[0].FullName).Equal("gerrit/drone")
+				g.Assert(repos[1].FullName).Equal("go/hello-world")
+			})
+
+			g.It("Should return the repo permissions", func() {
+				perm, err := gerrit.Perm(&user, "go", "hello-world")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(perm.Pull).IsTrue()
+				g.Assert(perm.Push).IsTrue()
+				g.Assert(perm.Admin).IsFalse()
+			})
+
+			g.It("Should map project names", func() {
+				owner, name := splitProject("go/hello-world")
+				g.Assert(owner).Equal("go")
+				g.Assert(name).Equal("hello-world")
+				owner, name = splitProject("drone")
+				g.Assert(owner).Equal("gerrit")
+				g.Assert(name).Equal("drone")
+				g.Assert(joinProject("gerrit", "drone")).Equal("drone")
+				g.Assert(joinProject("go", "hello-world")).Equal("go/hello-world")
+			})
+		})
+
+		g.Describe("Script", func() {
+			g.It("Should return the .drone.yml of the patchset", func() {
+				raw, sec, err := gerrit.Script(&user, &repo, &build)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(string(raw)).Equal("build:\n  image: golang\n")
+				g.Assert(len(sec)).Equal(0)
+			})
+
+			g.It("Should return an error, when the commit does not exist", func() {
+				other := model.Build{Commit: "0000000000000000000000000000000000000000"}
+				_, _, err := gerrit.Script(&user, &repo, &other)
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.Describe("Status", func() {
+			g.It("Should vote on the patchset", func() {
+				err := gerrit.Status(&user, &repo, &build, "http://drone.golang.org/go/hello-world/1")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should return an error, when the label does not exist", func() {
+				other := *gerrit
+				other.Label = "Code-Review"
+				err := other.Status(&user, &repo, &build, "http://drone.golang.org/go/hello-world/1")
+				g.Assert(err != nil).IsTrue()
+			})
+
+			g.It("Should not vote on branch builds", func() {
+				push := build
+				push.Ref = "refs/heads/master"
+				push.Commit = "0000000000000000000000000000000000000000"
+				err := gerrit.Status(&user, &repo, &push, "http://drone.golang.org/go/hello-world/1")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should map the build status to votes", func() {
+				link := "http://drone.golang.org/go/hello-world/1"
+				g.Assert(getReview("Verified", model.StatusPending, link).Labels["Verified"]).Equal(0)
+				g.Assert(getReview("Verified", model.StatusSuccess, link).Labels["Verified"]).Equal(1)
+				g.Assert(getReview("Verified", model.StatusFailure, link).Labels["Verified"]).Equal(-1)
+				g.Assert(getReview("Verified", model.StatusError, link).Labels["Verified"]).Equal(-1)
+				g.Assert(getReview("Verified", model.StatusKilled, link).Labels["Verified"]).Equal(-1)
+				g.Assert(getReview("Verified", model.StatusRunning, link) == nil).IsTrue()
+				g.Assert(getReview("Verified", model.StatusSuccess, link).Message).Equal("Build successful: " + link)
+			})
+
+			g.It("Should parse the change number", func() {
+				number, ok := changeNumber("refs/changes/45/12345/2")
+				g.Assert(ok).IsTrue()
+				g.Assert(number).Equal(int64(12345))
+				_, ok = changeNumber("refs/heads/master")
+				g.Assert(ok).IsFalse()
+			})
+		})
+
+		g.Describe("Hooks", func() {
+			g.It("Should return the netrc", func() {
+				netrc, err := gerrit.Netrc(&user, &repo)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(netrc.Login).Equal("gordon")
+				g.Assert(netrc.Password).Equal("http-password")
+			})
+
+			g.It("Should activate the repo", func() {
+				err := gerrit.Activate(&user, &repo, &model.Key{}, "http://drone.golang.org/hook?access_token=secret")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should deactivate the repo", func() {
+				err := gerrit.Deactivate(&user, &repo, "http://drone.golang.org")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should parse the patchset hook", func() {
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.PatchsetHook))
+				r, b, err := gerrit.Hook(req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(r.FullName).Equal("go/hello-world")
+				g.Assert(b.Event).Equal(model.EventPull)
+				g.Assert(b.Commit).Equal("9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5")
+				g.Assert(b.Ref).Equal("refs/changes/45/12345/2")
+				g.Assert(b.Refspec).Equal("refs/changes/45/12345/2")
+				g.Assert(b.Branch).Equal("master")
+				g.Assert(b.Title).Equal("Add a README")
+				g.Assert(b.Link).Equal("https://gerrit.golang.org/12345")
+				g.Assert(b.Author).Equal("gordon")
+			})
+
+			g.It("Should parse the ref updated hook", func() {
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.RefUpdatedHook))
+				r, b, err := gerrit.Hook(req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(r.FullName).Equal("go/hello-world")
+				g.Assert(b.Event).Equal(model.EventPush)
+				g.Assert(b.Ref).Equal("refs/heads/master")
+				g.Assert(b.Branch).Equal("master")
+				g.Assert(b.Commit).Equal("9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5")
+			})
+
+			g.It("Should parse the tag hook", func() {
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.TagHook))
+				_, b, err := gerrit.Hook(req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(b.Event).Equal(model.EventTag)
+				g.Assert(b.Ref).Equal("refs/tags/v1.0.0")
+			})
+
+			g.It("Should ignore change refs and other events", func() {
+				for _, payload := range []string{testdata.ChangeRefHook, testdata.CommentHook} {
+					req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(payload))
+					_, b, err := gerrit.Hook(req)
+					g.Assert(err == nil).IsTrue()
+					g.Assert(b == nil).IsTrue()
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,148 @@
+package gerrit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+)
+
+// rootOwner is the owner of the projects that are not
+// in a folder, such as the project named "drone".
+const rootOwner = "gerrit"
+
+// hook event types
+const (
+	eventPatchset   = "patchset-created"
+	eventRefUpdated = "ref-updated"
+)
+
+// projects that hold the site configuration and
+// are never built.
+var systemProjects = map[string]bool{
+	"All-Projects": true,
+	"All-Users":    true,
+}
+
+// splitProject is a helper function that splits the Gerrit
+// project name into the repository owner and name.
+func splitProject(project string) (owner, name string) {
+	i := strings.Index(project, "/")
+	if i == -1 {
+		return rootOwner, project
+	}
+	return project[:i], project[i+1:]
+}
+
+// joinProject is a helper function that returns the Gerrit
+// project name of the repository owner and name.
+func joinProject(owner, name string) string {
+	if owner == rootOwner {
+		return name
+	}
+	return owner + "/" + name
+}
+
+// convertRepo is a helper function used to convert a Gerrit
+// project to the common Drone repository structure.
+func convertRepo(base, project string) *model.Repo {
+	owner, name := splitProject(project)
+	return &model.Repo{
+		Owner:     owner,
+		Name:      name,
+		FullName:  owner + "/" + name,
+		Link:      fmt.Sprintf("%s/#/admin/projects/%s", base, project),
+		Clone:     fmt.Sprintf("%s/a/%s", base, project),
+		Kind:      model.RepoGit,
+		Branch:    "master",
+		IsPrivate: true,
+	}
+}
+
+// convertRepoLite is a helper function used to convert a Gerrit
+// project to the simplified Drone repository structure.
+func convertRepoLite(project string) *model.RepoLite {
+	owner, name := splitProject(project)
+	return &model.RepoLite{
+		Owner:    owner,
+		Name:     name,
+		FullName: owner + "/" + name,
+	}
+}
+
+// changeNumber is a helper function that returns the change
+// number of a change ref, such as refs/changes/45/12345/2.
+func changeNumber(ref string) (int64, bool) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 5 || parts[0] != "refs" || parts[1] != "changes" {
+		return 0, false
+	}
+	number, err := strconv.ParseInt(parts[3], 10, 64)
+	return number, err == nil
+}
+
+// buildFromPatchset is a helper function that extracts the Build
+// data from a patchset-created event.
+func buildFromPatchset(hook *Event) *model.Build {
+	return &model.Build{
+		Event:     model.EventPull,
+		Commit:    hook.PatchSet.Revision,
+		Ref:       hook.PatchSet.Ref,
+		Refspec:   hook.PatchSet.Ref,
+		Branch:    hook.Change.Branch,
+		Title:     hook.Change.Subject,
+		Message:   hook.Change.CommitMessage,
+		Link:      hook.Change.Url,
+		Author:    hook.PatchSet.Uploader.Username,
+		Email:     hook.PatchSet.Author.Email,
+		Timestamp: time.Now().UTC().Unix(),
+	}
+}
+
+// buildFromRefUpdated is a helper function that extracts the Build
+// data from a ref-updated event. It returns nil if the ref was
+// deleted, or if the ref is not a branch or tag.
+func buildFromRefUpdated(base string, hook *Event) *model.Build {
+	update := hook.RefUpdate
+	if strings.Trim(update.NewRev, "0") == "" {
+		return nil
+	}
+
+	// older versions of gerrit omit the refs/heads
+	// prefix of branches.
+	ref := update.RefName
+	if !strings.HasPrefix(ref, "refs/") {
+		ref = "refs/heads/" + ref
+	}
+
+	build := &model.Build{
+		Event:     model.EventPush,
+		Commit:    update.NewRev,
+		Ref:       ref,
+		Branch:    strings.TrimPrefix(ref, "refs/heads/"),
+		Link:      fmt.Sprintf("%s/#/q/%s", base, update.NewRev),
+		Author:    hook.Submitter.Username,
+		Email:     hook.Submitter.Email,
+		Timestamp: time.Now().UTC().Unix(),
+	}
+	switch {
+	case strings.HasPrefix(ref, "refs/tags/"):
+		build.Event = model.EventTag
+		build.Branch = ref
+	case !strings.HasPrefix(ref, "refs/heads/"):
+		return nil
+	}
+	return build
+}
+
+// parseEvent is a helper function that parses an event
+// from a reader.
+func parseEvent(r io.Reader) (*Event, error) {
+	hook := new(Event)
+	err := json.NewDecoder(r).Decode(hook)
+	return hook, err
+}
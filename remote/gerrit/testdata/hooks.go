@@ -0,0 +1,116 @@
+package testdata
+
+// PatchsetHook is a sample patchset-created event.
+var PatchsetHook = `
+{
+  "type": "patchset-created",
+  "change": {
+    "project": "go/hello-world",
+    "branch": "master",
+    "id": "I3cdc3f1ba6e8fa3b0e3e6b2f6f3bd1c8e1f9a7d2",
+    "number": 12345,
+    "subject": "Add a README",
+    "owner": {
+      "name": "Gordon the Gopher",
+      "email": "gordon@golang.org",
+      "username": "gordon"
+    },
+    "url": "https://gerrit.golang.org/12345",
+    "commitMessage": "Add a README\n\nChange-Id: I3cdc3f1ba6e8fa3b0e3e6b2f6f3bd1c8e1f9a7d2\n",
+    "status": "NEW"
+  },
+  "patchSet": {
+    "number": 2,
+    "revision": "9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5",
+    "parents": ["ecddabb624f6f5ba43816f5926e580a5f680a932"],
+    "ref": "refs/changes/45/12345/2",
+    "uploader": {
+      "name": "Gordon the Gopher",
+      "email": "gordon@golang.org",
+      "username": "gordon"
+    },
+    "createdOn": 1505781611,
+    "author": {
+      "name": "Gordon the Gopher",
+      "email": "gordon@golang.org",
+      "username": "gordon"
+    },
+    "kind": "REWORK"
+  },
+  "uploader": {
+    "name": "Gordon the Gopher",
+    "email": "gordon@golang.org",
+    "username": "gordon"
+  },
+  "eventCreatedOn": 1505781611
+}
+`
+
+// RefUpdatedHook is a sample ref-updated event for a branch.
+var RefUpdatedHook = `
+{
+  "type": "ref-updated",
+  "submitter": {
+    "name": "Gordon the Gopher",
+    "email": "gordon@golang.org",
+    "username": "gordon"
+  },
+  "refUpdate": {
+    "oldRev": "ecddabb624f6f5ba43816f5926e580a5f680a932",
+    "newRev": "9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5",
+    "refName": "refs/heads/master",
+    "project": "go/hello-world"
+  },
+  "eventCreatedOn": 1505781702
+}
+`
+
+// TagHook is a sample ref-updated event for a tag.
+var TagHook = `
+{
+  "type": "ref-updated",
+  "submitter": {
+    "name": "Gordon the Gopher",
+    "email": "gordon@golang.org",
+    "username": "gordon"
+  },
+  "refUpdate": {
+    "oldRev": "0000000000000000000000000000000000000000",
+    "newRev": "9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5",
+    "refName": "refs/tags/v1.0.0",
+    "project": "go/hello-world"
+  },
+  "eventCreatedOn": 1505781702
+}
+`
+
+// ChangeRefHook is a sample ref-updated event for a change ref,
+// which is sent along with the patchset-created event.
+var ChangeRefHook = `
+{
+  "type": "ref-updated",
+  "submitter": {
+    "username": "gordon"
+  },
+  "refUpdate": {
+    "oldRev": "0000000000000000000000000000000000000000",
+    "newRev": "9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5",
+    "refName": "refs/changes/45/12345/2",
+    "project": "go/hello-world"
+  },
+  "eventCreatedOn": 1505781611
+}
+`
+
+// CommentHook is a sample comment-added event.
+var CommentHook = `
+{
+  "type": "comment-added",
+  "change": {
+    "project": "go/hello-world",
+    "branch": "master",
+    "number": 12345
+  },
+  "comment": "Looks good to me"
+}
+`
@@ -0,0 +1,96 @@
+package testdata
+
+// sample account response
+var accountPayload = []byte(`)]}'
+{
+  "_account_id": 1000096,
+  "name": "Gordon the Gopher",
+  "email": "gordon@golang.org",
+  "username": "gordon",
+  "avatars": [
+    {
+      "url": "https://gerrit.golang.org/avatar/gordon?s=26",
+      "height": 26
+    },
+    {
+      "url": "https://gerrit.golang.org/avatar/gordon?s=100",
+      "height": 100
+    }
+  ]
+}
+`)
+
+// sample project list response
+var projectsPayload = []byte(`)]}'
+{
+  "All-Projects": {
+    "id": "All-Projects",
+    "state": "ACTIVE"
+  },
+  "All-Users": {
+    "id": "All-Users",
+    "state": "ACTIVE"
+  },
+  "go/hello-world": {
+    "id": "go%2Fhello-world",
+    "state": "ACTIVE"
+  },
+  "drone": {
+    "id": "drone",
+    "state": "ACTIVE"
+  },
+  "go/archived": {
+    "id": "go%2Farchived",
+    "state": "HIDDEN"
+  }
+}
+`)
+
+// sample project response
+var projectPayload = []byte(`)]}'
+{
+  "id": "go%2Fhello-world",
+  "name": "go/hello-world",
+  "parent": "All-Projects",
+  "description": "Hello World",
+  "state": "ACTIVE"
+}
+`)
+
+// sample project access response
+var accessPayload = []byte(`)]}'
+{
+  "revision": "61157ed63e14d261b6dca40650472a9b0bd88474",
+  "inherits_from": {
+    "id": "All-Projects",
+    "name": "All-Projects"
+  },
+  "local": {},
+  "is_owner": false,
+  "owner_of": [],
+  "can_upload": true,
+  "can_add": false,
+  "config_visible": false
+}
+`)
+
+// sample file content response, which is base64 encoded
+// and is not prefixed.
+var contentPayload = []byte(`YnVpbGQ6CiAgaW1hZ2U6IGdvbGFuZwo=`)
+
+// sample review response
+var reviewPayload = []byte(`)]}'
+{
+  "labels": {
+    "Verified": 1
+  }
+}
+`)
+
+// sample webhook response
+var webhookPayload = []byte(`)]}'
+{
+  "url": "http://drone.golang.org/hook?access_token=secret",
+  "events": ["patchset-created", "ref-updated"]
+}
+`)
@@ -0,0 +1,85 @@
+package testdata
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+)
+
+// setup a mock server for testing purposes.
+func NewServer() *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	// handle requests and serve mock data
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "gordon" || password != "http-password" {
+			w.WriteHeader(401)
+			w.Write([]byte("Unauthorized"))
+			return
+		}
+
+		// project names are escaped in the path, so the
+		// escaped path is evaluated.
+		switch r.URL.EscapedPath() {
+		case "/a/accounts/self":
+			w.Write(accountPayload)
+			return
+		case "/a/projects/":
+			w.Write(projectsPayload)
+			return
+		case "/a/projects/go%2Fhello-world":
+			w.Write(projectPayload)
+			return
+		case "/a/projects/go%2Fhello-world/access":
+			w.Write(accessPayload)
+			return
+		case "/a/projects/go%2Fhello-world/commits/9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5/files/.drone.yml/content":
+			w.Write(contentPayload)
+			return
+		case "/a/changes/go%2Fhello-world~12345/revisions/9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5/review":
+			in := struct {
+				Message string         `json:"message"`
+				Labels  map[string]int `json:"labels"`
+			}{}
+			json.NewDecoder(r.Body).Decode(&in)
+			if len(in.Message) == 0 || len(in.Labels) == 0 {
+				w.WriteHeader(400)
+				w.Write([]byte("message and labels required"))
+				return
+			}
+			if _, ok := in.Labels["Verified"]; !ok {
+				w.WriteHeader(400)
+				w.Write([]byte("label \"Code-Review\" is not a configured label"))
+				return
+			}
+			w.Write(reviewPayload)
+			return
+		case "/a/config/server/webhooks~projects/go%2Fhello-world/remotes/drone":
+			switch r.Method {
+			case "PUT":
+				in := map[string]interface{}{}
+				json.NewDecoder(r.Body).Decode(&in)
+				if in["url"] == nil {
+					w.WriteHeader(400)
+					return
+				}
+				w.WriteHeader(201)
+				w.Write(webhookPayload)
+				return
+			case "DELETE":
+				w.WriteHeader(204)
+				return
+			}
+		}
+
+		// else return a 404
+		w.WriteHeader(404)
+		w.Write([]byte("Not Found"))
+	})
+
+	// return the server to the client which
+	// will need to know the base URL path
+	return server
+}
@@ -0,0 +1,72 @@
+package gerrit
+
+type Account struct {
+	ID       int64  `json:"_account_id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Avatars  []struct {
+		Url    string `json:"url"`
+		Height int    `json:"height"`
+	} `json:"avatars"`
+}
+
+type Project struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+type ProjectAccess struct {
+	IsOwner   bool `json:"is_owner"`
+	CanUpload bool `json:"can_upload"`
+}
+
+type Review struct {
+	Message string         `json:"message"`
+	Labels  map[string]int `json:"labels,omitempty"`
+	Tag     string         `json:"tag,omitempty"`
+}
+
+type Webhook struct {
+	Url    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+type EventAccount struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+type Event struct {
+	Type string `json:"type"`
+
+	// fields of the patchset-created event.
+	Change struct {
+		Project       string       `json:"project"`
+		Branch        string       `json:"branch"`
+		ID            string       `json:"id"`
+		Number        int64        `json:"number"`
+		Subject       string       `json:"subject"`
+		Owner         EventAccount `json:"owner"`
+		Url           string       `json:"url"`
+		CommitMessage string       `json:"commitMessage"`
+	} `json:"change"`
+	PatchSet struct {
+		Number   int          `json:"number"`
+		Revision string       `json:"revision"`
+		Ref      string       `json:"ref"`
+		Uploader EventAccount `json:"uploader"`
+		Author   EventAccount `json:"author"`
+	} `json:"patchSet"`
+
+	// fields of the ref-updated event.
+	Submitter EventAccount `json:"submitter"`
+	RefUpdate struct {
+		OldRev  string `json:"oldRev"`
+		NewRev  string `json:"newRev"`
+		RefName string `json:"refName"`
+		Project string `json:"project"`
+	} `json:"refUpdate"`
+}
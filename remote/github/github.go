@@ -1,9 +1,13 @@
 package github
 
 import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -225,20 +229,54 @@ func (g *Github) Script(u *model.User, r *model.Repo, b *model.Build) ([]byte, [
 // Status sends the commit status to the remote system.
 // An example would be the GitHub pull request status.
 func (g *Github) Status(u *model.User, r *model.Repo, b *model.Build, link string) error {
+	return g.status(u, r, b, "Drone", b.Status, link)
+}
+
+// JobStatus sends the status of a single build job to the remote
+// system, under a context named from the job label.
+func (g *Github) JobStatus(u *model.User, r *model.Repo, b *model.Build, j *model.Job, link string) error {
+	return g.status(u, r, b, "Drone/"+j.Label(), j.Status, link)
+}
+
+func (g *Github) status(u *model.User, r *model.Repo, b *model.Build, context, status, link string) error {
 	client := NewClient(g.API, u.Token, g.SkipVerify)
 
-	status := getStatus(b.Status)
-	desc := getDesc(b.Status)
 	data := github.RepoStatus{
-		Context:     github.String("Drone"),
-		State:       github.String(status),
-		Description: github.String(desc),
+		Context:     github.String(context),
+		State:       github.String(getStatus(status)),
+		Description: github.String(getDesc(status)),
 		TargetURL:   github.String(link),
 	}
 	_, _, err := client.Repositories.CreateStatus(r.Owner, r.Name, b.Commit, &data)
 	return err
 }
 
+// Comment creates the build summary comment on the pull request, or
+// edits the comment of a previous build of the pull request in place.
+func (g *Github) Comment(u *model.User, r *model.Repo, b *model.Build, body string) error {
+	var number int
+	_, err := fmt.Sscanf(b.Ref, "refs/pull/%d/", &number)
+	if err != nil {
+		return fmt.Errorf("Unable to find the pull request of ref %s", b.Ref)
+	}
+
+	client := NewClient(g.API, u.Token, g.SkipVerify)
+	comment, err := GetComment(client, r.Owner, r.Name, number, u.Login, commentMarker)
+	if err != nil {
+		return err
+	}
+
+	data := github.IssueComment{
+		Body: github.String(commentMarker + "\n\n" + body),
+	}
+	if comment != nil {
+		_, _, err = client.Issues.EditComment(r.Owner, r.Name, *comment.ID, &data)
+	} else {
+		_, _, err = client.Issues.CreateComment(r.Owner, r.Name, number, &data)
+	}
+	return err
+}
+
 // Netrc returns a .netrc file that can be used to clone
 // private repositories from a remote system.
 func (g *Github) Netrc(u *model.User, r *model.Repo) (*model.Netrc, error) {
@@ -271,7 +309,7 @@ func (g *Github) Activate(u *model.User, r *model.Repo, k *model.Key, link strin
 		}
 	}
 
-	_, err = CreateUpdateHook(client, r.Owner, r.Name, link)
+	_, err = CreateUpdateHook(client, r.Owner, r.Name, link, r.HookSecret)
 	return err
 }
 
@@ -294,6 +332,32 @@ func (g *Github) Deactivate(u *model.User, r *model.Repo, link string) error {
 	return DeleteHook(client, r.Owner, r.Name, link)
 }
 
+// VerifyHook verifies the X-Hub-Signature-256 or X-Hub-Signature
+// header of the hook, which GitHub computes from the request body
+// and the secret the hook was registered with.
+func (g *Github) VerifyHook(r *http.Request, secret string) error {
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(payload))
+
+	if signature := r.Header.Get("X-Hub-Signature-256"); len(signature) != 0 {
+		if !ValidSignature(sha256.New, payload, secret, signature) {
+			return fmt.Errorf("Invalid hook signature")
+		}
+		return nil
+	}
+	if signature := r.Header.Get("X-Hub-Signature"); len(signature) != 0 {
+		if !ValidSignature(sha1.New, payload, secret, signature) {
+			return fmt.Errorf("Invalid hook signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("Missing hook signature")
+}
+
 // Hook parses the post-commit hook from the Request body
 // and returns the required data in a standard format.
 func (g *Github) Hook(r *http.Request) (*model.Repo, *model.Build, error) {
@@ -490,6 +554,10 @@ func (g *Github) String() string {
 	return "github"
 }
 
+// commentMarker is the first line of the build summary comment, used
+// to find the comment of a previous build. It is not rendered.
+const commentMarker = "[//]: # (drone)"
+
 const (
 	StatusPending = "pending"
 	StatusSuccess = "success"
@@ -0,0 +1,224 @@
+package github
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/remote/github/testdata"
+	"github.com/franela/goblin"
+)
+
+func Test_Github(t *testing.T) {
+	// setup a dummy github server
+	var server = testdata.NewServer()
+	defer server.Close()
+
+	github := Load(map[string]string{"REMOTE_CONFIG": server.URL + "?client_id=test&client_secret=test"})
+
+	var user = model.User{
+		Login: "octocat",
+		Token: "e3b0c44298fc1c149afbf4c8996fb",
+	}
+
+	var repo = model.Repo{
+		Owner:    "octocat",
+		Name:     "hello-world",
+		FullName: "octocat/hello-world",
+	}
+
+	var payload = []byte(`{"ref":"refs/heads/master"}`)
+
+	g := goblin.Goblin(t)
+	g.Describe("Github Plugin", func() {
+
+		// Test verify hook method
+		g.Describe("VerifyHook", func() {
+			g.It("Should verify the sha256 signature", func() {
+				req, _ := http.NewRequest("POST", "http://example.com/hook", bytes.NewReader(payload))
+				req.Header.Set("X-Hub-Signature-256", sign(sha256.New, "sha256", payload, "secret"))
+				g.Assert(github.VerifyHook(req, "secret") == nil).IsTrue()
+			})
+
+			g.It("Should verify the sha1 signature", func() {
+				req, _ := http.NewRequest("POST", "http://example.com/hook", bytes.NewReader(payload))
+				req.Header.Set("X-Hub-Signature", sign(sha1.New, "sha1", payload, "secret"))
+				g.Assert(github.VerifyHook(req, "secret") == nil).IsTrue()
+			})
+
+			g.It("Should prefer the sha256 signature", func() {
+				req, _ := http.NewRequest("POST", "http://example.com/hook", bytes.NewReader(payload))
+				req.Header.Set("X-Hub-Signature-256", sign(sha256.New, "sha256", payload, "other"))
+				req.Header.Set("X-Hub-Signature", sign(sha1.New, "sha1", payload, "secret"))
+				g.Assert(github.VerifyHook(req, "secret") != nil).IsTrue()
+			})
+
+			g.It("Should return error, when sha256 signature is invalid", func() {
+				req, _ := http.NewRequest("POST", "http://example.com/hook", bytes.NewReader(payload))
+				req.Header.Set("X-Hub-Signature-256", sign(sha256.New, "sha256", payload, "other"))
+				g.Assert(github.VerifyHook(req, "secret") != nil).IsTrue()
+			})
+
+			g.It("Should return error, when sha1 signature is invalid", func() {
+				req, _ := http.NewRequest("POST", "http://example.com/hook", bytes.NewReader(payload))
+				req.Header.Set("X-Hub-Signature", sign(sha1.New, "sha1", payload, "other"))
+				g.Assert(github.VerifyHook(req, "secret") != nil).IsTrue()
+			})
+
+			g.It("Should return error, when signature is missing", func() {
+				req, _ := http.NewRequest("POST", "http://example.com/hook", bytes.NewReader(payload))
+				g.Assert(github.VerifyHook(req, "secret") != nil).IsTrue()
+			})
+
+			g.It("Should restore the request body", func() {
+				req, _ := http.NewRequest("POST", "http://example.com/hook", bytes.NewReader(payload))
+				req.Header.Set("X-Hub-Signature-256", sign(sha256.New, "sha256", payload, "secret"))
+				github.VerifyHook(req, "secret")
+				body, err := ioutil.ReadAll(req.Body)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(body).Equal(payload)
+			})
+		})
+
+		// Test status method
+		g.Describe("Status", func() {
+			var build = model.Build{
+				Number: 1,
+				Event:  model.EventPush,
+				Commit: "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+				Branch: "master",
+			}
+
+			g.It("Should send the build status", func() {
+				for _, status := range []string{
+					model.StatusPending,
+					model.StatusRunning,
+					model.StatusSuccess,
+					model.StatusFailure,
+					model.StatusError,
+					model.StatusKilled,
+				} {
+					build.Status = status
+					err := github.Status(&user, &repo, &build, "http://drone.example.com/octocat/hello-world/1")
+					g.Assert(err == nil).IsTrue()
+				}
+			})
+
+			g.It("Should send the job status with the job context and link", func() {
+				job := model.Job{Number: 1, Status: model.StatusFailure, Environment: map[string]string{"GO_VERSION": "1.5"}}
+				err := github.JobStatus(&user, &repo, &build, &job, "http://drone.example.com/repos/octocat/hello-world/builds/1/1")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should name the job context after the job name", func() {
+				job := model.Job{Number: 2, Status: model.StatusSuccess, Name: "deploy"}
+				err := github.JobStatus(&user, &repo, &build, &job, "http://drone.example.com/repos/octocat/hello-world/builds/1/2")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should return error, when job link is not the job", func() {
+				job := model.Job{Number: 1, Status: model.StatusFailure, Environment: map[string]string{"GO_VERSION": "1.5"}}
+				err := github.JobStatus(&user, &repo, &build, &job, "http://drone.example.com/octocat/hello-world/1")
+				g.Assert(err != nil).IsTrue()
+			})
+
+			g.It("Should return error, when commit not exist", func() {
+				other := build
+				other.Commit = "0000000000000000000000000000000000000000"
+				err := github.Status(&user, &repo, &other, "http://drone.example.com/octocat/hello-world/1")
+				g.Assert(err != nil).IsTrue()
+			})
+
+			g.It("Should map the build status", func() {
+				g.Assert(getStatus(model.StatusPending)).Equal(StatusPending)
+				g.Assert(getStatus(model.StatusRunning)).Equal(StatusPending)
+				g.Assert(getStatus(model.StatusSuccess)).Equal(StatusSuccess)
+				g.Assert(getStatus(model.StatusFailure)).Equal(StatusFailure)
+				g.Assert(getStatus(model.StatusError)).Equal(StatusError)
+				g.Assert(getStatus(model.StatusKilled)).Equal(StatusError)
+			})
+		})
+
+		// Test comment method
+		g.Describe("Comment", func() {
+			var build = model.Build{
+				Number: 1,
+				Event:  model.EventPull,
+				Commit: "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+				Ref:    "refs/pull/1/merge",
+			}
+
+			g.It("Should find the comment of the user", func() {
+				client := NewClient(github.API, user.Token, false)
+				comment, err := GetComment(client, "octocat", "hello-world", 1, "octocat", commentMarker)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(*comment.ID).Equal(3)
+			})
+
+			g.It("Should not find the comment of another user", func() {
+				client := NewClient(github.API, user.Token, false)
+				comment, err := GetComment(client, "octocat", "hello-world", 1, "mona", commentMarker)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(comment == nil).IsTrue()
+			})
+
+			g.It("Should edit the comment of a previous build", func() {
+				err := github.Comment(&user, &repo, &build, "the build summary")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should create the comment", func() {
+				other := model.User{Login: "mona", Token: "1f2e3d4c5b6a"}
+				err := github.Comment(&other, &repo, &build, "the build summary")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should return error, when pull request not exist", func() {
+				other := build
+				other.Ref = "refs/pull/2/merge"
+				err := github.Comment(&user, &repo, &other, "the build summary")
+				g.Assert(err != nil).IsTrue()
+			})
+
+			g.It("Should return error, when ref is not a pull request", func() {
+				other := build
+				other.Ref = "refs/heads/master"
+				err := github.Comment(&user, &repo, &other, "the build summary")
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.Describe("ValidSignature", func() {
+			g.It("Should validate the signature", func() {
+				signature := sign(sha256.New, "sha256", payload, "secret")
+				g.Assert(ValidSignature(sha256.New, payload, "secret", signature)).IsTrue()
+			})
+
+			g.It("Should not validate the signature of another payload", func() {
+				signature := sign(sha256.New, "sha256", []byte("{}"), "secret")
+				g.Assert(ValidSignature(sha256.New, payload, "secret", signature)).IsFalse()
+			})
+
+			g.It("Should not validate malformed signatures", func() {
+				g.Assert(ValidSignature(sha256.New, payload, "secret", "")).IsFalse()
+				g.Assert(ValidSignature(sha256.New, payload, "secret", "sha256")).IsFalse()
+				g.Assert(ValidSignature(sha256.New, payload, "secret", "sha256=invalid")).IsFalse()
+			})
+		})
+	})
+}
+
+// sign returns the hook signature of the payload, in the
+// form sent by GitHub.
+func sign(h func() hash.Hash, prefix string, payload []byte, secret string) string {
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(payload)
+	return prefix + "=" + hex.EncodeToString(mac.Sum(nil))
+}
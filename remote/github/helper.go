@@ -1,9 +1,12 @@
 package github
 
 import (
+	"crypto/hmac"
 	"crypto/tls"
 	"encoding/base32"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -220,14 +223,18 @@ func DeleteHook(client *github.Client, owner, name, url string) error {
 }
 
 // CreateHook is a heper function that creates a post-commit hook
-// for the specified repository.
-func CreateHook(client *github.Client, owner, name, url string) (*github.Hook, error) {
+// for the specified repository. If the secret is not empty, GitHub
+// signs the hook payloads with the secret.
+func CreateHook(client *github.Client, owner, name, url, secret string) (*github.Hook, error) {
 	var hook = new(github.Hook)
 	hook.Name = github.String("web")
 	hook.Events = []string{"push", "pull_request", "deployment"}
 	hook.Config = map[string]interface{}{}
 	hook.Config["url"] = url
 	hook.Config["content_type"] = "form"
+	if len(secret) != 0 {
+		hook.Config["secret"] = secret
+	}
 	created, _, err := client.Repositories.CreateHook(owner, name, hook)
 	return created, err
 }
@@ -235,7 +242,7 @@ func CreateHook(client *github.Client, owner, name, url string) (*github.Hook, e
 // CreateUpdateHook is a heper function that creates a post-commit hook
 // for the specified repository if it does not already exist, otherwise
 // it updates the existing hook
-func CreateUpdateHook(client *github.Client, owner, name, url string) (*github.Hook, error) {
+func CreateUpdateHook(client *github.Client, owner, name, url, secret string) (*github.Hook, error) {
 	var hook, _ = GetHook(client, owner, name, url)
 	if hook != nil {
 		hook.Name = github.String("web")
@@ -243,11 +250,57 @@ func CreateUpdateHook(client *github.Client, owner, name, url string) (*github.H
 		hook.Config = map[string]interface{}{}
 		hook.Config["url"] = url
 		hook.Config["content_type"] = "form"
+		if len(secret) != 0 {
+			hook.Config["secret"] = secret
+		}
 		var updated, _, err = client.Repositories.EditHook(owner, name, *hook.ID, hook)
 		return updated, err
 	}
 
-	return CreateHook(client, owner, name, url)
+	return CreateHook(client, owner, name, url, secret)
+}
+
+// ValidSignature is a helper function that reports whether the
+// signature, in the form "<algorithm>=<hex digest>", is the hmac
+// of the payload with the secret.
+func ValidSignature(h func() hash.Hash, payload []byte, secret, signature string) bool {
+	parts := strings.SplitN(signature, "=", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	sig, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+// GetComment is a helper function that retrieves the comment of the
+// user on the pull request that starts with the marker. To do this, it
+// will retrieve a list of all comments and iterate through the list.
+func GetComment(client *github.Client, owner, name string, number int, login, marker string) (*github.IssueComment, error) {
+	var opts = github.IssueListCommentsOptions{}
+	opts.PerPage = 100
+	opts.Page = 1
+
+	for opts.Page > 0 {
+		comments, resp, err := client.Issues.ListComments(owner, name, number, &opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range comments {
+			if comment.User == nil || comment.User.Login == nil || *comment.User.Login != login {
+				continue
+			}
+			if comment.Body != nil && strings.HasPrefix(*comment.Body, marker) {
+				return &comment, nil
+			}
+		}
+		opts.Page = resp.NextPage
+	}
+	return nil, nil
 }
 
 // GetKey is a heper function that retrieves a public Key by
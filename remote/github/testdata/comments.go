@@ -0,0 +1,61 @@
+package testdata
+
+// sample pull request comments response, first page
+var commentsPayload = []byte(`
+[
+  {
+    "id": 1,
+    "body": "Looks good to me",
+    "user": {
+      "login": "octocat",
+      "id": 1
+    }
+  },
+  {
+    "id": 2,
+    "body": "[//]: # (drone)\n\nthe build summary",
+    "user": {
+      "login": "hubot",
+      "id": 2
+    }
+  }
+]
+`)
+
+// sample pull request comments response, second page
+var commentsPage2Payload = []byte(`
+[
+  {
+    "id": 3,
+    "body": "[//]: # (drone)\n\nthe build summary",
+    "user": {
+      "login": "octocat",
+      "id": 1
+    }
+  }
+]
+`)
+
+// sample comment response
+var commentPayload = []byte(`
+{
+  "id": 4,
+  "body": "[//]: # (drone)\n\nthe build summary",
+  "user": {
+    "login": "mona",
+    "id": 3
+  }
+}
+`)
+
+// sample edited comment response
+var commentEditedPayload = []byte(`
+{
+  "id": 3,
+  "body": "[//]: # (drone)\n\nthe build summary",
+  "user": {
+    "login": "octocat",
+    "id": 1
+  }
+}
+`)
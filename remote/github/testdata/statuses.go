@@ -0,0 +1,21 @@
+package testdata
+
+// links expected for each commit status context
+var statusLinks = map[string]string{
+	"Drone":                "http://drone.example.com/octocat/hello-world/1",
+	"Drone/GO_VERSION=1.5": "http://drone.example.com/repos/octocat/hello-world/builds/1/1",
+	"Drone/deploy":         "http://drone.example.com/repos/octocat/hello-world/builds/1/2",
+}
+
+// sample commit status response
+var statusPayload = []byte(`
+{
+  "id": 1,
+  "state": "success",
+  "description": "the build was successful",
+  "target_url": "http://drone.example.com/octocat/hello-world/1",
+  "context": "Drone",
+  "created_at": "2016-01-19T08:40:25Z",
+  "updated_at": "2016-01-19T08:40:25Z"
+}
+`)
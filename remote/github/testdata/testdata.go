@@ -0,0 +1,87 @@
+package testdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// comment marker of the build summary comment
+const commentMarker = "[//]: # (drone)"
+
+// token of the user that already commented on the pull request
+const commentToken = "Bearer e3b0c44298fc1c149afbf4c8996fb"
+
+// setup a mock server for testing purposes.
+func NewServer() *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	// handle requests and serve mock data
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// evaluate the path to serve a dummy data file
+		switch r.URL.Path {
+		case "/api/v3/repos/octocat/hello-world/statuses/6dcb09b5b57875f334f61aebed695e2e4193db5e":
+			in := map[string]string{}
+			json.NewDecoder(r.Body).Decode(&in)
+			switch in["state"] {
+			case "pending", "success", "failure", "error":
+			default:
+				w.WriteHeader(422)
+				return
+			}
+
+			// each status context links to the build,
+			// or to the job it reports.
+			link, ok := statusLinks[in["context"]]
+			if r.Method != "POST" || !ok || in["target_url"] != link {
+				w.WriteHeader(422)
+				return
+			}
+			w.WriteHeader(201)
+			w.Write(statusPayload)
+			return
+		case "/api/v3/repos/octocat/hello-world/issues/1/comments":
+			switch r.Method {
+			case "GET":
+				if r.FormValue("page") == "2" {
+					w.Write(commentsPage2Payload)
+					return
+				}
+				w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2>; rel="next"`, server.URL, r.URL.Path))
+				w.Write(commentsPayload)
+				return
+			case "POST":
+				// the summary of the user that already commented
+				// must be edited instead of created again.
+				in := map[string]string{}
+				json.NewDecoder(r.Body).Decode(&in)
+				if !strings.HasPrefix(in["body"], commentMarker) || r.Header.Get("Authorization") == commentToken {
+					w.WriteHeader(422)
+					return
+				}
+				w.WriteHeader(201)
+				w.Write(commentPayload)
+				return
+			}
+		case "/api/v3/repos/octocat/hello-world/issues/comments/3":
+			in := map[string]string{}
+			json.NewDecoder(r.Body).Decode(&in)
+			if r.Method != "PATCH" || !strings.HasPrefix(in["body"], commentMarker) || r.Header.Get("Authorization") != commentToken {
+				w.WriteHeader(422)
+				return
+			}
+			w.Write(commentEditedPayload)
+			return
+		}
+
+		// else return a 404
+		http.NotFound(w, r)
+	})
+
+	// return the server to the client which
+	// will need to know the base URL path
+	return server
+}
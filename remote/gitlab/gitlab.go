@@ -1,6 +1,7 @@
 package gitlab
 
 import (
+	"crypto/subtle"
 	"crypto/tls"
 	"fmt"
 	"io/ioutil"
@@ -225,11 +226,63 @@ func (g *Gitlab) Script(user *model.User, repo *model.Repo, build *model.Build)
 	return out1, out2, err
 }
 
-// NOTE Currently gitlab doesn't support status for commits and events,
-//      also if we want get MR status in gitlab we need implement a special plugin for gitlab,
-//      gitlab uses API to fetch build status on client side. But for now we skip this.
+// Status sends the commit status to the remote system.
 func (g *Gitlab) Status(u *model.User, repo *model.Repo, b *model.Build, link string) error {
-	return nil
+	return g.status(u, repo, b, StatusContext, b.Status, link)
+}
+
+// JobStatus sends the status of a single build job to the remote
+// system, under a context name that includes the job label.
+func (g *Gitlab) JobStatus(u *model.User, repo *model.Repo, b *model.Build, j *model.Job, link string) error {
+	return g.status(u, repo, b, StatusContext+"/"+j.Label(), j.Status, link)
+}
+
+func (g *Gitlab) status(u *model.User, repo *model.Repo, b *model.Build, name, status, link string) error {
+	var client = NewClient(g.URL, u.Token, g.SkipVerify)
+	id, err := GetProjectId(g, client, repo.Owner, repo.Name)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]string{
+		"state":       getStatus(status),
+		"name":        name,
+		"target_url":  link,
+		"description": getDesc(status),
+	}
+	if b.Event != model.EventTag && len(b.Branch) != 0 {
+		params["ref"] = b.Branch
+	}
+	return SetStatus(client, id, b.Commit, params)
+}
+
+// Comment creates the build summary note on the merge request, or
+// edits the note of a previous build of the merge request in place.
+func (g *Gitlab) Comment(u *model.User, repo *model.Repo, b *model.Build, body string) error {
+	iid, err := mergeRequestIid(b.Link)
+	if err != nil {
+		return err
+	}
+
+	var client = NewClient(g.URL, u.Token, g.SkipVerify)
+	id, err := GetProjectId(g, client, repo.Owner, repo.Name)
+	if err != nil {
+		return err
+	}
+	mergeRequestId, err := GetMergeRequestId(client, id, iid)
+	if err != nil {
+		return err
+	}
+	note, err := GetNote(client, id, mergeRequestId, u.Login, commentMarker)
+	if err != nil {
+		return err
+	}
+
+	var noteId string
+	if note != nil {
+		noteId = strconv.Itoa(note.Id)
+	}
+	return CreateUpdateNote(client, id, mergeRequestId, noteId, commentMarker+"\n\n"+body)
 }
 
 // Netrc returns a .netrc file that can be used to clone
@@ -263,6 +316,12 @@ func (g *Gitlab) Activate(user *model.User, repo *model.Repo, k *model.Key, link
 		return err
 	}
 
+	// repositories with a hook secret use a project hook, since
+	// the drone service cannot send the secret token.
+	if len(repo.HookSecret) != 0 {
+		return AddHook(client, id, link, repo.HookSecret, !g.SkipVerify)
+	}
+
 	uri, err := url.Parse(link)
 	if err != nil {
 		return err
@@ -288,9 +347,26 @@ func (g *Gitlab) Deactivate(user *model.User, repo *model.Repo, link string) err
 		return err
 	}
 
+	err = DeleteHooks(client, id, link)
+	if err != nil {
+		return err
+	}
 	return client.DeleteDroneService(id)
 }
 
+// VerifyHook verifies the X-Gitlab-Token header of the hook,
+// which GitLab sets to the secret token of the project hook.
+func (g *Gitlab) VerifyHook(req *http.Request, secret string) error {
+	token := req.Header.Get("X-Gitlab-Token")
+	if len(token) == 0 {
+		return fmt.Errorf("Missing hook token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return fmt.Errorf("Invalid hook token")
+	}
+	return nil
+}
+
 // ParseHook parses the post-commit hook from the Request body
 // and returns the required data in a standard format.
 func (g *Gitlab) Hook(req *http.Request) (*model.Repo, *model.Build, error) {
@@ -431,3 +507,62 @@ func (g *Gitlab) Scope() string {
 func (g *Gitlab) String() string {
 	return "gitlab"
 }
+
+const StatusContext = "drone"
+
+// commentMarker is the first line of the build summary note, used
+// to find the note of a previous build. It is not rendered.
+const commentMarker = "[//]: # (drone)"
+
+const (
+	StatusPending  = "pending"
+	StatusRunning  = "running"
+	StatusSuccess  = "success"
+	StatusFailure  = "failed"
+	StatusCanceled = "canceled"
+)
+
+const (
+	DescPending  = "this build is pending"
+	DescRunning  = "this build is running"
+	DescSuccess  = "the build was successful"
+	DescFailure  = "the build failed"
+	DescError    = "oops, something went wrong"
+	DescCanceled = "the build was killed"
+)
+
+// getStatus is a helper function that converts a Drone
+// status to a GitLab commit status.
+func getStatus(status string) string {
+	switch status {
+	case model.StatusPending:
+		return StatusPending
+	case model.StatusRunning:
+		return StatusRunning
+	case model.StatusSuccess:
+		return StatusSuccess
+	case model.StatusFailure, model.StatusError:
+		return StatusFailure
+	default:
+		return StatusCanceled
+	}
+}
+
+// getDesc is a helper function that generates a description
+// message for the build based on the status.
+func getDesc(status string) string {
+	switch status {
+	case model.StatusPending:
+		return DescPending
+	case model.StatusRunning:
+		return DescRunning
+	case model.StatusSuccess:
+		return DescSuccess
+	case model.StatusFailure:
+		return DescFailure
+	case model.StatusError:
+		return DescError
+	default:
+		return DescCanceled
+	}
+}
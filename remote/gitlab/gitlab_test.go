@@ -81,6 +81,14 @@ func Test_Gitlab(t *testing.T) {
 
 				g.Assert(err != nil).IsTrue()
 			})
+
+			g.It("Should create a project hook with the hook secret", func() {
+				other := repo
+				other.HookSecret = "secret"
+				err := gitlab.Activate(&user, &other, &model.Key{}, "http://example.com/hook?owner=diaspora&name=diaspora-client")
+
+				g.Assert(err == nil).IsTrue()
+			})
 		})
 
 		// Test deactivate method
@@ -90,9 +98,120 @@ func Test_Gitlab(t *testing.T) {
 
 				g.Assert(err == nil).IsTrue()
 			})
+
+			g.It("Should remove the project hooks", func() {
+				err := gitlab.Deactivate(&user, &repo, "http://example.com")
+
+				g.Assert(err == nil).IsTrue()
+			})
+		})
+
+		// Test verify hook method
+		g.Describe("VerifyHook", func() {
+			g.It("Should verify the hook token", func() {
+				req, _ := http.NewRequest("POST", "http://example.com/hook", bytes.NewReader(testdata.PushHook))
+				req.Header.Set("X-Gitlab-Token", "secret")
+				g.Assert(gitlab.VerifyHook(req, "secret") == nil).IsTrue()
+			})
+
+			g.It("Should return error, when token is invalid", func() {
+				req, _ := http.NewRequest("POST", "http://example.com/hook", bytes.NewReader(testdata.PushHook))
+				req.Header.Set("X-Gitlab-Token", "other")
+				g.Assert(gitlab.VerifyHook(req, "secret") != nil).IsTrue()
+			})
+
+			g.It("Should return error, when token is missing", func() {
+				req, _ := http.NewRequest("POST", "http://example.com/hook", bytes.NewReader(testdata.PushHook))
+				g.Assert(gitlab.VerifyHook(req, "secret") != nil).IsTrue()
+			})
+		})
+
+		// Test status method
+		g.Describe("Status", func() {
+			var build = model.Build{
+				Number: 1,
+				Event:  model.EventPush,
+				Commit: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4",
+				Branch: "master",
+			}
+
+			g.It("Should send the build status", func() {
+				for _, status := range []string{
+					model.StatusPending,
+					model.StatusRunning,
+					model.StatusSuccess,
+					model.StatusFailure,
+					model.StatusError,
+					model.StatusKilled,
+				} {
+					build.Status = status
+					err := gitlab.Status(&user, &repo, &build, "http://drone.example.com/diaspora/diaspora-client/1")
+					g.Assert(err == nil).IsTrue()
+				}
+			})
+
+			g.It("Should send the job status", func() {
+				job := model.Job{Number: 2, Status: model.StatusFailure, Environment: map[string]string{"GO_VERSION": "1.5"}}
+				err := gitlab.JobStatus(&user, &repo, &build, &job, "http://drone.example.com/repos/diaspora/diaspora-client/builds/1/2")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should return error, when commit not exist", func() {
+				other := build
+				other.Commit = "0000000000000000000000000000000000000000"
+				err := gitlab.Status(&user, &repo, &other, "http://drone.example.com/diaspora/diaspora-client/1")
+				g.Assert(err != nil).IsTrue()
+			})
+
+			g.It("Should map the build status", func() {
+				g.Assert(getStatus(model.StatusPending)).Equal(StatusPending)
+				g.Assert(getStatus(model.StatusRunning)).Equal(StatusRunning)
+				g.Assert(getStatus(model.StatusSuccess)).Equal(StatusSuccess)
+				g.Assert(getStatus(model.StatusFailure)).Equal(StatusFailure)
+				g.Assert(getStatus(model.StatusError)).Equal(StatusFailure)
+				g.Assert(getStatus(model.StatusKilled)).Equal(StatusCanceled)
+				g.Assert(getStatus(model.StatusSkipped)).Equal(StatusCanceled)
+			})
 		})
 
 		// Test login method
+		// Test comment method
+		g.Describe("Comment", func() {
+			var build = model.Build{
+				Number: 1,
+				Event:  model.EventPull,
+				Commit: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4",
+				Link:   "http://example.com/diaspora/diaspora-client/merge_requests/1",
+			}
+
+			g.It("Should edit the note of a previous build", func() {
+				err := gitlab.Comment(&user, &repo, &build, "the build summary")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should create the note", func() {
+				other := model.User{Login: "other_user", Token: user.Token}
+				err := gitlab.Comment(&other, &repo, &build, "the build summary")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should return error, when merge request not exist", func() {
+				other := build
+				other.Link = "http://example.com/diaspora/diaspora-client/merge_requests/2"
+				err := gitlab.Comment(&user, &repo, &other, "the build summary")
+				g.Assert(err != nil).IsTrue()
+			})
+
+			g.It("Should parse the merge request iid", func() {
+				iid, err := mergeRequestIid("http://example.com/diaspora/diaspora-client/merge_requests/12")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(iid).Equal(12)
+
+				_, err = mergeRequestIid("http://example.com/diaspora/diaspora-client/commit/12")
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
 		// g.Describe("Login", func() {
 		// 	g.It("Should return user", func() {
 		// 		user, err := gitlab.Login("valid_token", "")
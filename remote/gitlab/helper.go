@@ -1,9 +1,14 @@
 package gitlab
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/Bugagazavr/go-gitlab-client"
 )
@@ -97,3 +102,174 @@ func GetProjectId(r *Gitlab, client *gogitlab.Gitlab, owner, name string) (proje
 		return projectId, nil
 	}
 }
+
+const (
+	statusURL        = "/projects/:id/statuses/:sha"
+	hooksURL         = "/projects/:id/hooks"
+	mergeRequestsURL = "/projects/:id/merge_requests"
+	notesURL         = "/projects/:id/merge_requests/:merge_request_id/notes"
+	noteURL          = "/projects/:id/merge_requests/:merge_request_id/notes/:note_id"
+)
+
+// SetStatus is a helper function that creates a commit status for
+// the sha in the project, using the commit status API that the
+// vendored client does not implement.
+func SetStatus(client *gogitlab.Gitlab, id, sha string, params map[string]string) error {
+	uri, opaque := client.ResourceUrlQueryRaw(statusURL, map[string]string{":id": id, ":sha": sha}, params)
+	err := do(client, "POST", uri, opaque, nil, nil)
+	if err != nil {
+		return fmt.Errorf("Error setting commit status. %s", err)
+	}
+	return nil
+}
+
+// AddHook is a helper function that creates a project hook with
+// a secret token, which the vendored client does not support. GitLab
+// sends the token in the X-Gitlab-Token header of the hook.
+func AddHook(client *gogitlab.Gitlab, id, link, token string, sslVerify bool) error {
+	params := map[string]string{
+		"url":                     link,
+		"token":                   token,
+		"push_events":             "true",
+		"tag_push_events":         "true",
+		"merge_requests_events":   "true",
+		"enable_ssl_verification": strconv.FormatBool(sslVerify),
+	}
+	uri, opaque := client.ResourceUrlQueryRaw(hooksURL, map[string]string{":id": id}, params)
+	err := do(client, "POST", uri, opaque, nil, nil)
+	if err != nil {
+		return fmt.Errorf("Error creating project hook. %s", err)
+	}
+	return nil
+}
+
+// DeleteHooks is a helper function that removes the project
+// hooks with a url that is prefixed by the link.
+func DeleteHooks(client *gogitlab.Gitlab, id, link string) error {
+	hooks, err := client.ProjectHooks(id)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if !strings.HasPrefix(hook.Url, link) {
+			continue
+		}
+		err = client.RemoveProjectHook(id, strconv.Itoa(hook.Id))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// helper function to parse the iid of the merge request
+// from the merge request url, which ends with the iid.
+func mergeRequestIid(link string) (int, error) {
+	i := strings.LastIndex(link, "/merge_requests/")
+	if i == -1 {
+		return 0, fmt.Errorf("Unable to find the merge request of %s", link)
+	}
+	return strconv.Atoi(strings.Trim(link[i+len("/merge_requests/"):], "/"))
+}
+
+// GetMergeRequestId is a helper function that returns the id of
+// the merge request with the iid, which is the number of the merge
+// request within the project.
+func GetMergeRequestId(client *gogitlab.Gitlab, id string, iid int) (string, error) {
+	uri, opaque := client.ResourceUrlQueryRaw(mergeRequestsURL, map[string]string{":id": id}, map[string]string{"iid": strconv.Itoa(iid)})
+
+	var requests []*gogitlab.MergeRequest
+	err := do(client, "GET", uri, opaque, nil, &requests)
+	if err != nil {
+		return "", err
+	}
+	if len(requests) == 0 {
+		return "", fmt.Errorf("Unable to find merge request %d", iid)
+	}
+	return strconv.Itoa(requests[0].Id), nil
+}
+
+// GetNote is a helper function that retrieves the note of the user on
+// the merge request that starts with the marker. To do this, it will
+// retrieve a list of all notes and iterate through the list.
+func GetNote(client *gogitlab.Gitlab, id, mergeRequestId, login, marker string) (*gogitlab.MergeRequestNote, error) {
+	params := map[string]string{":id": id, ":merge_request_id": mergeRequestId}
+	for page := 1; ; page++ {
+		uri, opaque := client.ResourceUrlQueryRaw(notesURL, params, map[string]string{
+			"page":     strconv.Itoa(page),
+			"per_page": "100",
+		})
+
+		var notes []*gogitlab.MergeRequestNote
+		err := do(client, "GET", uri, opaque, nil, &notes)
+		if err != nil {
+			return nil, err
+		}
+		for _, note := range notes {
+			if note.Author == nil || note.Author.Username != login {
+				continue
+			}
+			if strings.HasPrefix(note.Body, marker) {
+				return note, nil
+			}
+		}
+		if len(notes) < 100 {
+			return nil, nil
+		}
+	}
+}
+
+// CreateUpdateNote is a helper function that creates a note on the
+// merge request, or updates the note if the note id is not empty. The
+// vendored client does not encode the note body.
+func CreateUpdateNote(client *gogitlab.Gitlab, id, mergeRequestId, noteId, body string) error {
+	params := map[string]string{":id": id, ":merge_request_id": mergeRequestId}
+	method, path := "POST", notesURL
+	if len(noteId) != 0 {
+		params[":note_id"] = noteId
+		method, path = "PUT", noteURL
+	}
+	uri, opaque := client.ResourceUrlQueryRaw(path, params, nil)
+
+	err := do(client, method, uri, opaque, url.Values{"body": {body}}, nil)
+	if err != nil {
+		return fmt.Errorf("Error commenting on merge request. %s", err)
+	}
+	return nil
+}
+
+// helper function to send a request to the resource url, with the
+// form as the request body, and decode the json response into out.
+func do(client *gogitlab.Gitlab, method, uri, opaque string, form url.Values, out interface{}) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, uri, body)
+	if err != nil {
+		return err
+	}
+	if len(opaque) != 0 {
+		req.URL.Opaque = opaque
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if client.Bearer {
+		req.Header.Set("Authorization", "Bearer "+client.Token)
+	}
+
+	res, err := client.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 400 {
+		body, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("<%d> %s", res.StatusCode, body)
+	}
+	if out != nil {
+		return json.NewDecoder(res.Body).Decode(out)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package testdata
+
+// sample merge requests response
+var mergeRequestsPayload = []byte(`
+[
+  {
+    "id": 42,
+    "iid": 1,
+    "project_id": 4,
+    "title": "test1",
+    "state": "opened",
+    "target_branch": "master",
+    "source_branch": "test1"
+  }
+]
+`)
+
+// sample merge request notes response
+var notesPayload = []byte(`
+[
+  {
+    "id": 1,
+    "body": "[//]: # (drone)\n\nthe summary of another user",
+    "author": {
+      "id": 1,
+      "username": "root",
+      "name": "Administrator"
+    },
+    "created_at": "2016-01-19T08:40:25.934Z"
+  },
+  {
+    "id": 2,
+    "body": "[//]: # (drone)\n\nthe summary of a previous build",
+    "author": {
+      "id": 28,
+      "username": "test_user",
+      "name": "Test User"
+    },
+    "created_at": "2016-01-19T08:40:25.934Z"
+  }
+]
+`)
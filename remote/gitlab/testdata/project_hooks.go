@@ -0,0 +1,29 @@
+package testdata
+
+// sample project hooks response
+var projectHooksPayload = []byte(`
+[
+  {
+    "id": 1,
+    "url": "http://example.com/hook?owner=diaspora&name=diaspora-client",
+    "project_id": 4,
+    "push_events": true,
+    "issues_events": false,
+    "merge_requests_events": true,
+    "tag_push_events": true,
+    "enable_ssl_verification": true,
+    "created_at": "2016-01-19T08:40:25.934Z"
+  },
+  {
+    "id": 2,
+    "url": "http://ci.example.com/hook",
+    "project_id": 4,
+    "push_events": true,
+    "issues_events": false,
+    "merge_requests_events": false,
+    "tag_push_events": false,
+    "enable_ssl_verification": true,
+    "created_at": "2016-01-19T08:40:25.934Z"
+  }
+]
+`)
@@ -0,0 +1,26 @@
+package testdata
+
+// sample commit status response
+var statusPayload = []byte(`
+{
+  "id": 93,
+  "sha": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4",
+  "ref": "master",
+  "status": "success",
+  "name": "drone",
+  "target_url": "http://drone.example.com/diaspora/diaspora-client/1",
+  "description": "the build was successful",
+  "created_at": "2016-01-19T08:40:25.934Z",
+  "started_at": null,
+  "finished_at": "2016-01-19T08:40:25.934Z",
+  "allow_failure": false,
+  "author": {
+    "username": "test_user",
+    "id": 28,
+    "name": "Test User",
+    "state": "active",
+    "avatar_url": "http://www.gravatar.com/avatar/8c2b0b0d7a4e1d9d5b3f5c2f6b0d1a3e?s=80&d=identicon",
+    "web_url": "https://gitlab.example.com/u/test_user"
+  }
+}
+`)
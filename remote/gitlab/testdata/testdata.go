@@ -3,6 +3,7 @@ package testdata
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 )
 
 // setup a mock server for testing purposes.
@@ -33,6 +34,61 @@ func NewServer() *httptest.Server {
 				w.WriteHeader(201)
 			}
 
+			return
+		case "/api/v3/projects/diaspora/diaspora-client/hooks":
+			switch r.Method {
+			case "GET":
+				w.Write(projectHooksPayload)
+			case "POST":
+				if r.FormValue("url") == "" || r.FormValue("token") == "" {
+					w.WriteHeader(400)
+				} else {
+					w.WriteHeader(201)
+				}
+			}
+			return
+		case "/api/v3/projects/diaspora/diaspora-client/hooks/1":
+			if r.Method != "DELETE" {
+				w.WriteHeader(405)
+			}
+			return
+		case "/api/v3/projects/diaspora/diaspora-client/merge_requests":
+			if r.FormValue("iid") == "1" {
+				w.Write(mergeRequestsPayload)
+			} else {
+				w.Write([]byte("[]"))
+			}
+			return
+		case "/api/v3/projects/diaspora/diaspora-client/merge_requests/42/notes":
+			switch r.Method {
+			case "GET":
+				w.Write(notesPayload)
+			case "POST":
+				if !strings.HasPrefix(r.FormValue("body"), "[//]: # (drone)") {
+					w.WriteHeader(400)
+					return
+				}
+				w.WriteHeader(201)
+			}
+			return
+		case "/api/v3/projects/diaspora/diaspora-client/merge_requests/42/notes/2":
+			if r.Method != "PUT" || !strings.HasPrefix(r.FormValue("body"), "[//]: # (drone)") {
+				w.WriteHeader(400)
+			}
+			return
+		case "/api/v3/projects/diaspora/diaspora-client/statuses/e3b0c44298fc1c149afbf4c8996fb92427ae41e4":
+			switch r.FormValue("state") {
+			case "pending", "running", "success", "failed", "canceled":
+			default:
+				w.WriteHeader(400)
+				return
+			}
+			if r.Method != "POST" || r.FormValue("name") == "" {
+				w.WriteHeader(400)
+				return
+			}
+			w.WriteHeader(201)
+			w.Write(statusPayload)
 			return
 		case "/oauth/token":
 			w.Write(accessTokenPayload)
@@ -1,10 +1,16 @@
 package gogs
 
 import (
+	"bytes"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/CiscoCloud/drone/model"
 	"github.com/CiscoCloud/drone/shared/envconfig"
@@ -168,8 +174,33 @@ func (g *Gogs) Script(u *model.User, r *model.Repo, b *model.Build) ([]byte, []b
 
 // Status sends the commit status to the remote system.
 // An example would be the GitHub pull request status.
+//
+// Commit statuses are supported by Gitea and recent versions of
+// Gogs. Older versions do not have the API and respond with 404,
+// in which case the status is silently dropped.
 func (g *Gogs) Status(u *model.User, r *model.Repo, b *model.Build, link string) error {
-	return fmt.Errorf("Not Implemented")
+	return g.status(u, r, b.Commit, StatusContext, b.Status, link)
+}
+
+// JobStatus sends the status of a single build job to the remote
+// system, under a context name that includes the job label.
+func (g *Gogs) JobStatus(u *model.User, r *model.Repo, b *model.Build, j *model.Job, link string) error {
+	return g.status(u, r, b.Commit, StatusContext+"/"+j.Label(), j.Status, link)
+}
+
+func (g *Gogs) status(u *model.User, r *model.Repo, sha, context, status, link string) error {
+	data := Status{
+		State:   getStatus(status),
+		Target:  link,
+		Desc:    getDesc(status),
+		Context: context,
+	}
+	err := createStatus(g.client(), g.URL, u.Token, r.Owner, r.Name, sha, &data)
+	if err == errStatusNotSupported {
+		log.Debugf("commit status is not supported by %s", g.URL)
+		return nil
+	}
+	return err
 }
 
 // Netrc returns a .netrc file that can be used to clone
@@ -189,14 +220,21 @@ func (g *Gogs) Netrc(u *model.User, r *model.Repo) (*model.Netrc, error) {
 // Activate activates a repository by creating the post-commit hook and
 // adding the SSH deploy key, if applicable.
 func (g *Gogs) Activate(u *model.User, r *model.Repo, k *model.Key, link string) error {
+	// repositories activated before hook secrets were
+	// introduced are registered with the repository hash.
+	secret := r.HookSecret
+	if len(secret) == 0 {
+		secret = r.Hash
+	}
 	config := map[string]string{
 		"url":          link,
-		"secret":       r.Hash,
+		"secret":       secret,
 		"content_type": "json",
 	}
 	hook := gogs.CreateHookOption{
 		Type:   "gogs",
 		Config: config,
+		Events: []string{"push", "create", "pull_request"},
 		Active: true,
 	}
 
@@ -208,7 +246,52 @@ func (g *Gogs) Activate(u *model.User, r *model.Repo, k *model.Key, link string)
 // Deactivate removes a repository by removing all the post-commit hooks
 // which are equal to link and removing the SSH deploy key.
 func (g *Gogs) Deactivate(u *model.User, r *model.Repo, link string) error {
-	return fmt.Errorf("Not Implemented")
+	client := gogs.NewClient(g.URL, u.Token)
+	hooks, err := client.ListRepoHooks(r.Owner, r.Name)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if !strings.HasPrefix(hook.Config["url"], link) {
+			continue
+		}
+		err = deleteHook(g.client(), g.URL, u.Token, r.Owner, r.Name, hook.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyHook verifies the X-Gogs-Signature header of the hook, which
+// is the hmac of the request body with the secret. Gogs servers that
+// do not sign hooks send the secret in the payload instead.
+func (g *Gogs) VerifyHook(r *http.Request, secret string) error {
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(payload))
+
+	if signature := r.Header.Get("X-Gogs-Signature"); len(signature) != 0 {
+		if !validSignature(payload, secret, signature) {
+			return fmt.Errorf("Invalid hook signature")
+		}
+		return nil
+	}
+
+	hook := struct {
+		Secret string `json:"secret"`
+	}{}
+	json.Unmarshal(payload, &hook)
+	if len(hook.Secret) == 0 {
+		return fmt.Errorf("Missing hook secret")
+	}
+	if subtle.ConstantTimeCompare([]byte(hook.Secret), []byte(secret)) != 1 {
+		return fmt.Errorf("Invalid hook secret")
+	}
+	return nil
 }
 
 // Hook parses the post-commit hook from the Request body
@@ -228,10 +311,109 @@ func (g *Gogs) Hook(r *http.Request) (*model.Repo, *model.Build, error) {
 			repo = repoFromPush(push)
 			build = buildFromPush(push)
 		}
+	case "create":
+		var create *CreateHook
+		create, err = parseCreate(r.Body)
+		if err != nil || create.RefType != "tag" {
+			break
+		}
+		// older versions of Gogs do not include the commit
+		// sha in the create hook, which is required to build.
+		if len(create.Sha) == 0 {
+			err = fmt.Errorf("Tag hook for %s is missing the commit sha", create.Ref)
+			break
+		}
+		repo = repoFromCreate(create)
+		build = buildFromTag(create)
+	case "pull_request":
+		var pr *PullRequestHook
+		pr, err = parsePullRequest(r.Body)
+		if err != nil || !buildAction(pr.Action) {
+			break
+		}
+		// older versions of Gogs do not include the head
+		// commit sha in the pull request hook.
+		if len(pr.PullRequest.Head.Sha) == 0 {
+			err = fmt.Errorf("Pull request hook for #%d is missing the commit sha", pr.Number)
+			break
+		}
+		repo = repoFromPullRequest(pr)
+		build = buildFromPullRequest(pr)
 	}
 	return repo, build, err
 }
 
+// buildAction returns true if the pull request action
+// opens the pull request or changes its commits.
+func buildAction(action string) bool {
+	switch action {
+	case "opened", "reopened", "synchronized":
+		return true
+	default:
+		return false
+	}
+}
+
+// client returns the http client used to send requests the
+// vendored Gogs client does not support.
+func (g *Gogs) client() *http.Client {
+	if !g.SkipVerify {
+		return http.DefaultClient
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+}
+
 func (g *Gogs) String() string {
 	return "gogs"
 }
+
+const StatusContext = "drone"
+
+const (
+	StatusPending = "pending"
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+	StatusError   = "error"
+)
+
+const (
+	DescPending = "this build is pending"
+	DescSuccess = "the build was successful"
+	DescFailure = "the build failed"
+	DescError   = "oops, something went wrong"
+)
+
+// getStatus is a helper function that converts a Drone
+// status to a Gogs commit status.
+func getStatus(status string) string {
+	switch status {
+	case model.StatusPending, model.StatusRunning:
+		return StatusPending
+	case model.StatusSuccess:
+		return StatusSuccess
+	case model.StatusFailure:
+		return StatusFailure
+	default:
+		return StatusError
+	}
+}
+
+// getDesc is a helper function that generates a description
+// message for the build based on the status.
+func getDesc(status string) string {
+	switch status {
+	case model.StatusPending, model.StatusRunning:
+		return DescPending
+	case model.StatusSuccess:
+		return DescSuccess
+	case model.StatusFailure:
+		return DescFailure
+	default:
+		return DescError
+	}
+}
@@ -0,0 +1,190 @@
+package gogs
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/remote/gogs/testdata"
+
+	"github.com/franela/goblin"
+)
+
+func Test_Gogs(t *testing.T) {
+
+	// setup a dummy gogs server that supports the
+	// commit status api for a single repository.
+	var statuses []*Status
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/repos/gordon/hello-world/statuses/ef98532add3b2feb7a137426bba1248724367df5", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token cfcd2084" {
+			w.WriteHeader(401)
+			return
+		}
+		status := new(Status)
+		json.NewDecoder(r.Body).Decode(status)
+		statuses = append(statuses, status)
+		w.WriteHeader(201)
+	})
+	mux.HandleFunc("/api/v1/repos/gordon/broken/statuses/ef98532add3b2feb7a137426bba1248724367df5", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	})
+	mux.HandleFunc("/api/v1/repos/gordon/hello-world/hooks", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id": 1, "type": "gogs", "config": {"url": "http://drone.golang.org/hook?owner=gordon&name=hello-world"}},
+			{"id": 2, "type": "gogs", "config": {"url": "http://ci.golang.org/hook"}}
+		]`))
+	})
+	mux.HandleFunc("/api/v1/repos/gordon/hello-world/hooks/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			w.WriteHeader(405)
+			return
+		}
+		deleted = append(deleted, r.URL.Path)
+		w.WriteHeader(204)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	gogs := Gogs{URL: server.URL}
+	user := model.User{Login: "gordon", Token: "cfcd2084"}
+	repo := model.Repo{Owner: "gordon", Name: "hello-world"}
+	build := model.Build{Number: 1, Commit: "ef98532add3b2feb7a137426bba1248724367df5", Status: model.StatusSuccess}
+
+	g := goblin.Goblin(t)
+	g.Describe("Gogs", func() {
+
+		g.BeforeEach(func() {
+			statuses = nil
+			deleted = nil
+		})
+
+		g.It("Should send the build status", func() {
+			err := gogs.Status(&user, &repo, &build, "http://drone.golang.org/gordon/hello-world/1")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(statuses)).Equal(1)
+			g.Assert(statuses[0].State).Equal(StatusSuccess)
+			g.Assert(statuses[0].Context).Equal("drone")
+			g.Assert(statuses[0].Desc).Equal(DescSuccess)
+			g.Assert(statuses[0].Target).Equal("http://drone.golang.org/gordon/hello-world/1")
+		})
+
+		g.It("Should send the job status", func() {
+			job := model.Job{Number: 2, Status: model.StatusFailure, Name: "backend"}
+			err := gogs.JobStatus(&user, &repo, &build, &job, "http://drone.golang.org/repos/gordon/hello-world/builds/1/2")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(statuses)).Equal(1)
+			g.Assert(statuses[0].State).Equal(StatusFailure)
+			g.Assert(statuses[0].Context).Equal("drone/backend")
+		})
+
+		g.It("Should ignore servers without the status api", func() {
+			other := model.Repo{Owner: "gordon", Name: "other"}
+			err := gogs.Status(&user, &other, &build, "http://drone.golang.org/gordon/other/1")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(statuses)).Equal(0)
+		})
+
+		g.It("Should return an error, when the status fails", func() {
+			broken := model.Repo{Owner: "gordon", Name: "broken"}
+			err := gogs.Status(&user, &broken, &build, "http://drone.golang.org/gordon/broken/1")
+			g.Assert(err != nil).IsTrue()
+		})
+
+		g.It("Should map the build status", func() {
+			g.Assert(getStatus(model.StatusPending)).Equal(StatusPending)
+			g.Assert(getStatus(model.StatusRunning)).Equal(StatusPending)
+			g.Assert(getStatus(model.StatusSuccess)).Equal(StatusSuccess)
+			g.Assert(getStatus(model.StatusFailure)).Equal(StatusFailure)
+			g.Assert(getStatus(model.StatusError)).Equal(StatusError)
+			g.Assert(getStatus(model.StatusKilled)).Equal(StatusError)
+		})
+
+		g.It("Should remove the hooks on deactivate", func() {
+			err := gogs.Deactivate(&user, &repo, "http://drone.golang.org")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(deleted).Equal([]string{"/api/v1/repos/gordon/hello-world/hooks/1"})
+		})
+
+		g.It("Should verify the hook signature", func() {
+			req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.PushHook))
+			req.Header.Set("X-Gogs-Signature", "f2d0ddb1a2a4d4e1f1e3ac2d69b01e4e73b1e4b1c02a8f1a7e7a5c1b3c2d1e0f")
+			g.Assert(gogs.VerifyHook(req, "secret") != nil).IsTrue()
+
+			mac := hmac.New(sha256.New, []byte("secret"))
+			mac.Write([]byte(testdata.PushHook))
+			req, _ = http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.PushHook))
+			req.Header.Set("X-Gogs-Signature", hex.EncodeToString(mac.Sum(nil)))
+			g.Assert(gogs.VerifyHook(req, "secret") == nil).IsTrue()
+
+			// the body must remain readable by the hook parser
+			req.Header.Set("X-Gogs-Event", "push")
+			_, build, err := gogs.Hook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(build != nil).IsTrue()
+		})
+
+		g.It("Should verify the hook secret in the payload", func() {
+			payload := strings.Replace(testdata.PushHook, `"ref":`, `"secret": "secret", "ref":`, 1)
+			req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(payload))
+			g.Assert(gogs.VerifyHook(req, "secret") == nil).IsTrue()
+
+			req, _ = http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(payload))
+			g.Assert(gogs.VerifyHook(req, "other") != nil).IsTrue()
+
+			req, _ = http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.PushHook))
+			g.Assert(gogs.VerifyHook(req, "secret") != nil).IsTrue()
+		})
+
+		g.It("Should parse the pull request hook", func() {
+			req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.PullRequestHook))
+			req.Header.Set("X-Gogs-Event", "pull_request")
+			repo, build, err := gogs.Hook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(repo.FullName).Equal("gordon/hello-world")
+			g.Assert(build.Event).Equal(model.EventPull)
+		})
+
+		g.It("Should ignore closed pull requests", func() {
+			payload := strings.Replace(testdata.PullRequestHook, `"action": "opened"`, `"action": "closed"`, 1)
+			req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(payload))
+			req.Header.Set("X-Gogs-Event", "pull_request")
+			_, build, err := gogs.Hook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(build == nil).IsTrue()
+		})
+
+		g.It("Should parse the tag hook", func() {
+			req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.TagHook))
+			req.Header.Set("X-Gogs-Event", "create")
+			repo, build, err := gogs.Hook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(repo.FullName).Equal("gordon/hello-world")
+			g.Assert(build.Event).Equal(model.EventTag)
+		})
+
+		g.It("Should ignore the branch hook", func() {
+			req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.BranchHook))
+			req.Header.Set("X-Gogs-Event", "create")
+			_, build, err := gogs.Hook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(build == nil).IsTrue()
+		})
+
+		g.It("Should return an error, when the tag hook has no sha", func() {
+			payload := strings.Replace(testdata.TagHook, `"sha": "ef98532add3b2feb7a137426bba1248724367df5",`, "", 1)
+			req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(payload))
+			req.Header.Set("X-Gogs-Event", "create")
+			_, _, err := gogs.Hook(req)
+			g.Assert(err != nil).IsTrue()
+		})
+	})
+}
@@ -1,9 +1,16 @@
 package gogs
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net/http"
 	"net/url"
 	"strings"
 	"time"
@@ -95,6 +102,70 @@ func repoFromPush(hook *PushHook) *model.Repo {
 	}
 }
 
+// helper function that extracts the Build data
+// from a Gogs pull request hook
+func buildFromPullRequest(hook *PullRequestHook) *model.Build {
+	avatar := expandAvatar(
+		hook.Repo.Url,
+		fixMalformedAvatar(hook.PullRequest.User.Avatar),
+	)
+	return &model.Build{
+		Event:     model.EventPull,
+		Commit:    hook.PullRequest.Head.Sha,
+		Ref:       fmt.Sprintf("refs/pull/%d/head", hook.Number),
+		Link:      hook.PullRequest.Url,
+		Branch:    hook.PullRequest.HeadBranch,
+		Message:   hook.PullRequest.Title,
+		Title:     hook.PullRequest.Title,
+		Avatar:    avatar,
+		Author:    hook.PullRequest.User.Username,
+		Email:     hook.PullRequest.User.Email,
+		Timestamp: time.Now().UTC().Unix(),
+	}
+}
+
+// helper function that extracts the Repository data
+// from a Gogs pull request hook
+func repoFromPullRequest(hook *PullRequestHook) *model.Repo {
+	return &model.Repo{
+		Name:     hook.Repo.Name,
+		Owner:    hook.Repo.Owner.Username,
+		FullName: hook.Repo.FullName,
+		Link:     hook.Repo.Url,
+	}
+}
+
+// helper function that extracts the Build data
+// from a Gogs tag create hook
+func buildFromTag(hook *CreateHook) *model.Build {
+	avatar := expandAvatar(
+		hook.Repo.Url,
+		fixMalformedAvatar(hook.Sender.Avatar),
+	)
+	return &model.Build{
+		Event:     model.EventTag,
+		Commit:    hook.Sha,
+		Ref:       fmt.Sprintf("refs/tags/%s", hook.Ref),
+		Link:      fmt.Sprintf("%s/src/%s", hook.Repo.Url, hook.Ref),
+		Branch:    fmt.Sprintf("refs/tags/%s", hook.Ref),
+		Message:   fmt.Sprintf("created tag %s", hook.Ref),
+		Avatar:    avatar,
+		Author:    hook.Sender.Login,
+		Timestamp: time.Now().UTC().Unix(),
+	}
+}
+
+// helper function that extracts the Repository data
+// from a Gogs create hook
+func repoFromCreate(hook *CreateHook) *model.Repo {
+	return &model.Repo{
+		Name:     hook.Repo.Name,
+		Owner:    hook.Repo.Owner.Username,
+		FullName: hook.Repo.FullName,
+		Link:     hook.Repo.Url,
+	}
+}
+
 // helper function that parses a push hook from
 // a read closer.
 func parsePush(r io.Reader) (*PushHook, error) {
@@ -103,6 +174,22 @@ func parsePush(r io.Reader) (*PushHook, error) {
 	return push, err
 }
 
+// helper function that parses a pull request hook
+// from a read closer.
+func parsePullRequest(r io.Reader) (*PullRequestHook, error) {
+	pr := new(PullRequestHook)
+	err := json.NewDecoder(r).Decode(pr)
+	return pr, err
+}
+
+// helper function that parses a create hook from
+// a read closer.
+func parseCreate(r io.Reader) (*CreateHook, error) {
+	create := new(CreateHook)
+	err := json.NewDecoder(r).Decode(create)
+	return create, err
+}
+
 // fixMalformedAvatar is a helper function that fixes
 // an avatar url if malformed (known bug with gogs)
 func fixMalformedAvatar(url string) string {
@@ -130,3 +217,76 @@ func expandAvatar(repo, rawurl string) string {
 	url_.Path = rawurl
 	return url_.String()
 }
+
+// errStatusNotSupported is returned by createStatus when the
+// Gogs server does not implement the commit status API.
+var errStatusNotSupported = errors.New("Commit status is not supported")
+
+// helper function that creates a commit status using the
+// commit status API, which the vendored client does not
+// implement.
+func createStatus(client *http.Client, rawurl, token, owner, name, sha string, status *Status) error {
+	buf := new(bytes.Buffer)
+	err := json.NewEncoder(buf).Encode(status)
+	if err != nil {
+		return err
+	}
+
+	uri := fmt.Sprintf("%s/api/v1/repos/%s/%s/statuses/%s", strings.TrimSuffix(rawurl, "/"), owner, name, sha)
+	req, err := http.NewRequest("POST", uri, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == 404 || res.StatusCode == 405:
+		return errStatusNotSupported
+	case res.StatusCode >= 400:
+		body, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("Error setting commit status. <%d> %s", res.StatusCode, body)
+	}
+	return nil
+}
+
+// helper function that deletes a repository hook, which
+// the vendored client does not implement.
+func deleteHook(client *http.Client, rawurl, token, owner, name string, id int64) error {
+	uri := fmt.Sprintf("%s/api/v1/repos/%s/%s/hooks/%d", strings.TrimSuffix(rawurl, "/"), owner, name, id)
+	req, err := http.NewRequest("DELETE", uri, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		body, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("Error deleting hook. <%d> %s", res.StatusCode, body)
+	}
+	return nil
+}
+
+// helper function that reports whether the signature is the
+// hex encoded hmac-sha256 of the payload with the secret.
+func validSignature(payload []byte, secret, signature string) bool {
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
@@ -166,3 +166,93 @@ func Test_parse(t *testing.T) {
 		})
 	})
 }
+
+func Test_parseEvents(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Gogs events", func() {
+
+		g.It("Should parse pull request hook payload", func() {
+			buf := bytes.NewBufferString(testdata.PullRequestHook)
+			hook, err := parsePullRequest(buf)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hook.Action).Equal("opened")
+			g.Assert(hook.Number).Equal(int64(1))
+			g.Assert(hook.PullRequest.Title).Equal("Update the README with new information")
+			g.Assert(hook.PullRequest.Url).Equal("http://gogs.golang.org/gordon/hello-world/pulls/1")
+			g.Assert(hook.PullRequest.HeadBranch).Equal("feature/changes")
+			g.Assert(hook.PullRequest.Head.Sha).Equal("0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c")
+			g.Assert(hook.PullRequest.BaseBranch).Equal("master")
+			g.Assert(hook.PullRequest.User.Username).Equal("gordon")
+			g.Assert(hook.Repo.FullName).Equal("gordon/hello-world")
+			g.Assert(hook.Repo.Owner.Username).Equal("gordon")
+		})
+
+		g.It("Should return a Build struct from a pull request hook", func() {
+			buf := bytes.NewBufferString(testdata.PullRequestHook)
+			hook, _ := parsePullRequest(buf)
+			build := buildFromPullRequest(hook)
+			g.Assert(build.Event).Equal(model.EventPull)
+			g.Assert(build.Commit).Equal(hook.PullRequest.Head.Sha)
+			g.Assert(build.Ref).Equal("refs/pull/1/head")
+			g.Assert(build.Link).Equal(hook.PullRequest.Url)
+			g.Assert(build.Branch).Equal("feature/changes")
+			g.Assert(build.Message).Equal(hook.PullRequest.Title)
+			g.Assert(build.Title).Equal(hook.PullRequest.Title)
+			g.Assert(build.Avatar).Equal("//1.gravatar.com/avatar/8c58a0be77ee441bb8f8595b7f1b4e87")
+			g.Assert(build.Author).Equal("gordon")
+			g.Assert(build.Email).Equal("gordon@golang.org")
+		})
+
+		g.It("Should return a Repo struct from a pull request hook", func() {
+			buf := bytes.NewBufferString(testdata.PullRequestHook)
+			hook, _ := parsePullRequest(buf)
+			repo := repoFromPullRequest(hook)
+			g.Assert(repo.Name).Equal("hello-world")
+			g.Assert(repo.Owner).Equal("gordon")
+			g.Assert(repo.FullName).Equal("gordon/hello-world")
+			g.Assert(repo.Link).Equal("http://gogs.golang.org/gordon/hello-world")
+		})
+
+		g.It("Should parse tag hook payload", func() {
+			buf := bytes.NewBufferString(testdata.TagHook)
+			hook, err := parseCreate(buf)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(hook.Ref).Equal("v1.0.0")
+			g.Assert(hook.RefType).Equal("tag")
+			g.Assert(hook.Sha).Equal("ef98532add3b2feb7a137426bba1248724367df5")
+			g.Assert(hook.Repo.FullName).Equal("gordon/hello-world")
+			g.Assert(hook.Sender.Login).Equal("gordon")
+		})
+
+		g.It("Should return a Build struct from a tag hook", func() {
+			buf := bytes.NewBufferString(testdata.TagHook)
+			hook, _ := parseCreate(buf)
+			build := buildFromTag(hook)
+			g.Assert(build.Event).Equal(model.EventTag)
+			g.Assert(build.Commit).Equal(hook.Sha)
+			g.Assert(build.Ref).Equal("refs/tags/v1.0.0")
+			g.Assert(build.Branch).Equal("refs/tags/v1.0.0")
+			g.Assert(build.Link).Equal("http://gogs.golang.org/gordon/hello-world/src/v1.0.0")
+			g.Assert(build.Message).Equal("created tag v1.0.0")
+			g.Assert(build.Author).Equal("gordon")
+		})
+
+		g.It("Should return a Repo struct from a tag hook", func() {
+			buf := bytes.NewBufferString(testdata.TagHook)
+			hook, _ := parseCreate(buf)
+			repo := repoFromCreate(hook)
+			g.Assert(repo.Name).Equal("hello-world")
+			g.Assert(repo.Owner).Equal("gordon")
+			g.Assert(repo.FullName).Equal("gordon/hello-world")
+		})
+
+		g.It("Should build opened and synchronized pull requests", func() {
+			g.Assert(buildAction("opened")).IsTrue()
+			g.Assert(buildAction("reopened")).IsTrue()
+			g.Assert(buildAction("synchronized")).IsTrue()
+			g.Assert(buildAction("closed")).IsFalse()
+			g.Assert(buildAction("label_updated")).IsFalse()
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package testdata
+
+var PullRequestHook = `
+{
+  "action": "opened",
+  "number": 1,
+  "pull_request": {
+    "id": 1,
+    "number": 1,
+    "user": {
+      "id": 1,
+      "login": "gordon",
+      "full_name": "Gordon the Gopher",
+      "email": "gordon@golang.org",
+      "avatar_url": "http://gogs.golang.org///1.gravatar.com/avatar/8c58a0be77ee441bb8f8595b7f1b4e87",
+      "username": "gordon"
+    },
+    "title": "Update the README with new information",
+    "body": "please merge",
+    "state": "open",
+    "html_url": "http://gogs.golang.org/gordon/hello-world/pulls/1",
+    "mergeable": true,
+    "merged": false,
+    "merge_base": "4b2626259b5a97b6b4eab5e6cca66adb986b672b",
+    "base_branch": "master",
+    "base": {
+      "label": "master",
+      "ref": "master",
+      "sha": "4b2626259b5a97b6b4eab5e6cca66adb986b672b"
+    },
+    "head_branch": "feature/changes",
+    "head": {
+      "label": "feature/changes",
+      "ref": "feature/changes",
+      "sha": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c"
+    }
+  },
+  "repository": {
+    "id": 1,
+    "owner": {
+      "id": 1,
+      "login": "gordon",
+      "full_name": "Gordon the Gopher",
+      "email": "gordon@golang.org",
+      "avatar_url": "http://gogs.golang.org///1.gravatar.com/avatar/8c58a0be77ee441bb8f8595b7f1b4e87",
+      "username": "gordon"
+    },
+    "name": "hello-world",
+    "full_name": "gordon/hello-world",
+    "description": "",
+    "private": true,
+    "fork": false,
+    "html_url": "http://gogs.golang.org/gordon/hello-world",
+    "ssh_url": "git@gogs.golang.org:gordon/hello-world.git",
+    "clone_url": "http://gogs.golang.org/gordon/hello-world.git",
+    "default_branch": "master"
+  },
+  "sender": {
+    "id": 1,
+    "login": "gordon",
+    "full_name": "Gordon the Gopher",
+    "email": "gordon@golang.org",
+    "avatar_url": "http://gogs.golang.org///1.gravatar.com/avatar/8c58a0be77ee441bb8f8595b7f1b4e87",
+    "username": "gordon"
+  }
+}
+`
@@ -0,0 +1,65 @@
+package testdata
+
+var TagHook = `
+{
+  "ref": "v1.0.0",
+  "ref_type": "tag",
+  "sha": "ef98532add3b2feb7a137426bba1248724367df5",
+  "default_branch": "master",
+  "repository": {
+    "id": 1,
+    "owner": {
+      "id": 1,
+      "login": "gordon",
+      "full_name": "Gordon the Gopher",
+      "email": "gordon@golang.org",
+      "avatar_url": "http://gogs.golang.org///1.gravatar.com/avatar/8c58a0be77ee441bb8f8595b7f1b4e87",
+      "username": "gordon"
+    },
+    "name": "hello-world",
+    "full_name": "gordon/hello-world",
+    "description": "",
+    "private": true,
+    "fork": false,
+    "html_url": "http://gogs.golang.org/gordon/hello-world",
+    "ssh_url": "git@gogs.golang.org:gordon/hello-world.git",
+    "clone_url": "http://gogs.golang.org/gordon/hello-world.git",
+    "default_branch": "master"
+  },
+  "sender": {
+    "id": 1,
+    "login": "gordon",
+    "full_name": "Gordon the Gopher",
+    "email": "gordon@golang.org",
+    "avatar_url": "http://gogs.golang.org///1.gravatar.com/avatar/8c58a0be77ee441bb8f8595b7f1b4e87",
+    "username": "gordon"
+  }
+}
+`
+
+// BranchHook is a create hook for a branch, which is ignored
+// since the branch is also built by the push hook.
+var BranchHook = `
+{
+  "ref": "feature/changes",
+  "ref_type": "branch",
+  "sha": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c",
+  "default_branch": "master",
+  "repository": {
+    "id": 1,
+    "owner": {
+      "id": 1,
+      "login": "gordon",
+      "username": "gordon"
+    },
+    "name": "hello-world",
+    "full_name": "gordon/hello-world",
+    "html_url": "http://gogs.golang.org/gordon/hello-world"
+  },
+  "sender": {
+    "id": 1,
+    "login": "gordon",
+    "username": "gordon"
+  }
+}
+`
@@ -36,3 +36,90 @@ type PushHook struct {
 		Avatar string `json:"avatar_url"`
 	} `json:"sender"`
 }
+
+type PullRequestHook struct {
+	Action string `json:"action"`
+	Number int64  `json:"number"`
+
+	PullRequest struct {
+		ID    int64  `json:"id"`
+		Title string `json:"title"`
+		Body  string `json:"body"`
+		State string `json:"state"`
+		Url   string `json:"html_url"`
+
+		User struct {
+			ID       int64  `json:"id"`
+			Username string `json:"username"`
+			Name     string `json:"full_name"`
+			Email    string `json:"email"`
+			Avatar   string `json:"avatar_url"`
+		} `json:"user"`
+
+		BaseBranch string `json:"base_branch"`
+		Base       struct {
+			Ref string `json:"ref"`
+			Sha string `json:"sha"`
+		} `json:"base"`
+
+		HeadBranch string `json:"head_branch"`
+		Head       struct {
+			Ref string `json:"ref"`
+			Sha string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+
+	Repo struct {
+		ID       int64  `json:"id"`
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+		Url      string `json:"html_url"`
+		Private  bool   `json:"private"`
+		Owner    struct {
+			ID       int64  `json:"id"`
+			Username string `json:"username"`
+			Name     string `json:"full_name"`
+			Email    string `json:"email"`
+		} `json:"owner"`
+	} `json:"repository"`
+
+	Sender struct {
+		ID     int64  `json:"id"`
+		Login  string `json:"login"`
+		Avatar string `json:"avatar_url"`
+	} `json:"sender"`
+}
+
+type CreateHook struct {
+	Ref           string `json:"ref"`
+	RefType       string `json:"ref_type"`
+	Sha           string `json:"sha"`
+	DefaultBranch string `json:"default_branch"`
+
+	Repo struct {
+		ID       int64  `json:"id"`
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+		Url      string `json:"html_url"`
+		Private  bool   `json:"private"`
+		Owner    struct {
+			ID       int64  `json:"id"`
+			Username string `json:"username"`
+			Name     string `json:"full_name"`
+			Email    string `json:"email"`
+		} `json:"owner"`
+	} `json:"repository"`
+
+	Sender struct {
+		ID     int64  `json:"id"`
+		Login  string `json:"login"`
+		Avatar string `json:"avatar_url"`
+	} `json:"sender"`
+}
+
+type Status struct {
+	State   string `json:"state"`
+	Target  string `json:"target_url"`
+	Desc    string `json:"description"`
+	Context string `json:"context"`
+}
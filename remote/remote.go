@@ -5,9 +5,11 @@ import (
 
 	"github.com/CiscoCloud/drone/model"
 	"github.com/CiscoCloud/drone/remote/bitbucket"
+	"github.com/CiscoCloud/drone/remote/gerrit"
 	"github.com/CiscoCloud/drone/remote/github"
 	"github.com/CiscoCloud/drone/remote/gitlab"
 	"github.com/CiscoCloud/drone/remote/gogs"
+	"github.com/CiscoCloud/drone/remote/stash"
 	"github.com/CiscoCloud/drone/shared/envconfig"
 
 	log "github.com/Sirupsen/logrus"
@@ -19,12 +21,16 @@ func Load(env envconfig.Env) Remote {
 	switch driver {
 	case "bitbucket":
 		return bitbucket.Load(env)
+	case "gerrit":
+		return gerrit.Load(env)
 	case "github":
 		return github.Load(env)
 	case "gitlab":
 		return gitlab.Load(env)
 	case "gogs":
 		return gogs.Load(env)
+	case "stash":
+		return stash.Load(env)
 
 	default:
 		log.Fatalf("unknown remote driver %s", driver)
@@ -83,3 +89,22 @@ type Refresher interface {
 	// token was not refreshed, and error if it failed to refersh.
 	Refresh(*model.User) (bool, error)
 }
+
+type JobStatuser interface {
+	// JobStatus sends the status of a single build job to the remote
+	// system, as a commit status separate from that of the build.
+	JobStatus(u *model.User, r *model.Repo, b *model.Build, j *model.Job, link string) error
+}
+
+type Commenter interface {
+	// Comment creates the comment on the pull request of the build, or
+	// edits the comment of a previous build of the pull request in place.
+	Comment(u *model.User, r *model.Repo, b *model.Build, body string) error
+}
+
+type HookVerifier interface {
+	// VerifyHook verifies the hook in the request with the secret the
+	// hook was registered with in Activate, before the hook is parsed.
+	// The request body must remain readable by Hook.
+	VerifyHook(r *http.Request, secret string) error
+}
@@ -0,0 +1,96 @@
+package remote
+
+import (
+	"strings"
+
+	"github.com/CiscoCloud/drone/shared/envconfig"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Remotes is the set of remotes hosted by the server, by id. The
+// first remote is the default remote, used for the users, repos and
+// hooks that are not tagged with a remote.
+type Remotes struct {
+	ids     []string
+	remotes map[string]Remote
+}
+
+// LoadRemotes loads the remotes listed in REMOTES, where each remote
+// is configured with REMOTE_<ID>_DRIVER and REMOTE_<ID>_CONFIG. If no
+// remotes are listed, the single remote configured with REMOTE_DRIVER
+// and REMOTE_CONFIG is loaded, with the driver name as id.
+func LoadRemotes(env envconfig.Env) *Remotes {
+	remotes := NewRemotes()
+
+	ids := splitList(env.String("REMOTES", ""))
+	if len(ids) == 0 {
+		remotes.Add(env.Get("REMOTE_DRIVER"), Load(env))
+		return remotes
+	}
+
+	for _, id := range ids {
+		prefix := "REMOTE_" + strings.ToUpper(strings.Replace(id, "-", "_", -1)) + "_"
+
+		// the drivers read their configuration from the
+		// environment, so each driver is loaded with the
+		// configuration of the remote.
+		sub := envconfig.Env{}
+		for k, v := range env {
+			sub[k] = v
+		}
+		sub["REMOTE_DRIVER"] = env.String(prefix+"DRIVER", id)
+		sub["REMOTE_CONFIG"] = env.String(prefix+"CONFIG", "")
+
+		log.Infof("using remote %s with driver %s", id, sub["REMOTE_DRIVER"])
+		remotes.Add(id, Load(sub))
+	}
+	return remotes
+}
+
+// NewRemotes returns an empty set of remotes.
+func NewRemotes() *Remotes {
+	return &Remotes{remotes: map[string]Remote{}}
+}
+
+// Add adds the remote with the id to the set.
+func (r *Remotes) Add(id string, remote Remote) {
+	if _, ok := r.remotes[id]; !ok {
+		r.ids = append(r.ids, id)
+	}
+	r.remotes[id] = remote
+}
+
+// IDs returns the ids of the remotes, starting
+// with the default remote.
+func (r *Remotes) IDs() []string {
+	return r.ids
+}
+
+// ID returns the id, or the id of the default
+// remote if the id is empty.
+func (r *Remotes) ID(id string) string {
+	if len(id) == 0 && len(r.ids) != 0 {
+		return r.ids[0]
+	}
+	return id
+}
+
+// Get returns the remote with the id, or the default remote
+// if the id is empty. It returns nil if the id is unknown.
+func (r *Remotes) Get(id string) Remote {
+	return r.remotes[r.ID(id)]
+}
+
+// helper function to split a comma
+// separated list, ignoring empty items.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) != 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
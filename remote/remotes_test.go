@@ -0,0 +1,48 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/CiscoCloud/drone/remote/gogs"
+	"github.com/CiscoCloud/drone/shared/envconfig"
+	"github.com/franela/goblin"
+)
+
+func TestRemotes(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Remotes", func() {
+
+		g.It("Should load the single remote", func() {
+			remotes := LoadRemotes(envconfig.Env{
+				"REMOTE_DRIVER": "gogs",
+				"REMOTE_CONFIG": "https://try.gogs.io",
+			})
+			g.Assert(remotes.IDs()).Equal([]string{"gogs"})
+			g.Assert(remotes.Get("").(*gogs.Gogs).URL).Equal("https://try.gogs.io")
+		})
+
+		g.It("Should load the listed remotes", func() {
+			remotes := LoadRemotes(envconfig.Env{
+				"REMOTES":                     "gogs, internal-gogs",
+				"REMOTE_GOGS_CONFIG":          "https://try.gogs.io",
+				"REMOTE_INTERNAL_GOGS_DRIVER": "gogs",
+				"REMOTE_INTERNAL_GOGS_CONFIG": "https://gogs.example.com?open=true",
+			})
+			g.Assert(remotes.IDs()).Equal([]string{"gogs", "internal-gogs"})
+			g.Assert(remotes.Get("gogs").(*gogs.Gogs).URL).Equal("https://try.gogs.io")
+			g.Assert(remotes.Get("internal-gogs").(*gogs.Gogs).URL).Equal("https://gogs.example.com")
+			g.Assert(remotes.Get("internal-gogs").(*gogs.Gogs).Open).IsTrue()
+		})
+
+		g.It("Should default to the first remote", func() {
+			remotes := NewRemotes()
+			remotes.Add("github", &gogs.Gogs{URL: "https://github.com"})
+			remotes.Add("gitlab", &gogs.Gogs{URL: "https://gitlab.com"})
+			g.Assert(remotes.ID("")).Equal("github")
+			g.Assert(remotes.ID("gitlab")).Equal("gitlab")
+			g.Assert(remotes.Get("").(*gogs.Gogs).URL).Equal("https://github.com")
+			g.Assert(remotes.Get("bitbucket") == nil).IsTrue()
+		})
+	})
+}
@@ -0,0 +1,220 @@
+package stash
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	get  = "GET"
+	put  = "PUT"
+	post = "POST"
+	del  = "DELETE"
+)
+
+const (
+	pathWhoami = "%s/plugins/servlet/applinks/whoami"
+	pathUser   = "%s/rest/api/1.0/users/%s"
+	pathRepo   = "%s/rest/api/1.0/projects/%s/repos/%s"
+	pathRepos  = "%s/rest/api/1.0/repos?%s"
+	pathHook   = "%s/rest/api/1.0/projects/%s/repos/%s/webhooks/%d"
+	pathHooks  = "%s/rest/api/1.0/projects/%s/repos/%s/webhooks"
+	pathSource = "%s/projects/%s/repos/%s/raw/%s?at=%s"
+	pathStatus = "%s/rest/build-status/1.0/commits/%s"
+)
+
+type Client struct {
+	*http.Client
+	base string
+}
+
+// NewClient returns a client for the Bitbucket Server at the base
+// url that sends requests with the given http client, which is
+// expected to authorize the requests.
+func NewClient(base string, client *http.Client) *Client {
+	return &Client{client, strings.TrimSuffix(base, "/")}
+}
+
+// NewClientToken returns a client that authorizes requests using
+// a personal access token.
+func NewClientToken(base, token string, transport http.RoundTripper) *Client {
+	return NewClient(base, &http.Client{
+		Transport: &tokenTransport{token, transport},
+	})
+}
+
+// FindCurrent returns the user the client is authorized as.
+func (c *Client) FindCurrent() (*User, error) {
+	uri := fmt.Sprintf(pathWhoami, c.base)
+	raw, err := c.raw(uri)
+	if err != nil {
+		return nil, err
+	}
+	return c.FindUser(strings.TrimSpace(string(raw)))
+}
+
+func (c *Client) FindUser(login string) (*User, error) {
+	out := new(User)
+	uri := fmt.Sprintf(pathUser, c.base, url.QueryEscape(login))
+	err := c.do(uri, get, nil, out)
+	return out, err
+}
+
+func (c *Client) FindRepo(owner, name string) (*Repo, error) {
+	out := new(Repo)
+	uri := fmt.Sprintf(pathRepo, c.base, owner, name)
+	err := c.do(uri, get, nil, out)
+	return out, err
+}
+
+func (c *Client) ListRepos(opts url.Values) (*RepoResp, error) {
+	out := new(RepoResp)
+	uri := fmt.Sprintf(pathRepos, c.base, opts.Encode())
+	err := c.do(uri, get, nil, out)
+	return out, err
+}
+
+// ListReposAll returns all the repositories the user has the
+// given permission for, such as REPO_READ.
+func (c *Client) ListReposAll(permission string) ([]*Repo, error) {
+	var start = 0
+	var repos []*Repo
+
+	for {
+		opts := url.Values{}
+		opts.Set("permission", permission)
+		opts.Set("start", fmt.Sprint(start))
+		opts.Set("limit", "100")
+
+		resp, err := c.ListRepos(opts)
+		if err != nil {
+			return repos, err
+		}
+		repos = append(repos, resp.Values...)
+		if resp.IsLastPage {
+			break
+		}
+		start = resp.NextPageStart
+	}
+	return repos, nil
+}
+
+func (c *Client) ListHooks(owner, name string) (*HookResp, error) {
+	out := new(HookResp)
+	uri := fmt.Sprintf(pathHooks, c.base, owner, name)
+	err := c.do(uri, get, nil, out)
+	return out, err
+}
+
+func (c *Client) CreateHook(owner, name string, hook *Hook) error {
+	uri := fmt.Sprintf(pathHooks, c.base, owner, name)
+	return c.do(uri, post, hook, nil)
+}
+
+func (c *Client) DeleteHook(owner, name string, id int64) error {
+	uri := fmt.Sprintf(pathHook, c.base, owner, name, id)
+	return c.do(uri, del, nil, nil)
+}
+
+// FindSource returns the raw contents of the file at the
+// revision.
+func (c *Client) FindSource(owner, name, revision, path string) ([]byte, error) {
+	uri := fmt.Sprintf(pathSource, c.base, owner, name, path, url.QueryEscape(revision))
+	return c.raw(uri)
+}
+
+func (c *Client) CreateStatus(revision string, status *BuildStatus) error {
+	uri := fmt.Sprintf(pathStatus, c.base, revision)
+	return c.do(uri, post, status, nil)
+}
+
+// raw sends a get request and returns the response body as-is.
+func (c *Client) raw(rawurl string) ([]byte, error) {
+	resp, err := c.Get(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, Error{Status: resp.StatusCode}
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (c *Client) do(rawurl, method string, in, out interface{}) error {
+
+	uri, err := url.Parse(rawurl)
+	if err != nil {
+		return err
+	}
+
+	// if we are posting or putting data, we need to
+	// write it to the body of the request.
+	var buf io.ReadWriter
+	if in != nil {
+		buf = new(bytes.Buffer)
+		err := json.NewEncoder(buf).Encode(in)
+		if err != nil {
+			return err
+		}
+	}
+
+	// creates a new http request to bitbucket server.
+	req, err := http.NewRequest(method, uri.String(), buf)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// if an error is encountered, parse and return the
+	// error response.
+	if resp.StatusCode > http.StatusPartialContent {
+		err := Error{}
+		json.NewDecoder(resp.Body).Decode(&err)
+		err.Status = resp.StatusCode
+		return err
+	}
+
+	// if a json response is expected, parse and return
+	// the json response.
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+
+	return nil
+}
+
+// tokenTransport is an http.RoundTripper that authorizes requests
+// using a personal access token. Bitbucket Server only accepts access
+// tokens as bearer tokens, so account passwords are rejected.
+type tokenTransport struct {
+	token     string
+	transport http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	clone := new(http.Request)
+	*clone = *req
+	clone.Header = make(http.Header)
+	for k, v := range req.Header {
+		clone.Header[k] = v
+	}
+	clone.Header.Set("Authorization", "Bearer "+t.token)
+	return t.transport.RoundTrip(clone)
+}
@@ -0,0 +1,179 @@
+package stash
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/CiscoCloud/drone/model"
+)
+
+// repository permissions
+const (
+	permRead  = "REPO_READ"
+	permWrite = "REPO_WRITE"
+	permAdmin = "REPO_ADMIN"
+)
+
+// hook event keys
+const (
+	eventPush        = "repo:refs_changed"
+	eventPullOpened  = "pr:opened"
+	eventPullUpdated = "pr:from_ref_updated"
+)
+
+// convertRepo is a helper function used to convert a Bitbucket
+// Server repository structure to the common Drone repository
+// structure.
+func convertRepo(from *Repo) *model.Repo {
+	repo := model.Repo{
+		Owner:     from.Project.Key,
+		Name:      from.Slug,
+		FullName:  fmt.Sprintf("%s/%s", from.Project.Key, from.Slug),
+		IsPrivate: !from.Public,
+		Kind:      model.RepoGit,
+		Branch:    "master",
+	}
+	if len(from.Links.Self) != 0 {
+		repo.Link = from.Links.Self[0].Href
+	}
+
+	// the http clone url includes the name of the user that
+	// requested the repository, which is removed since the
+	// credentials are provided by the netrc file.
+	for _, link := range from.Links.Clone {
+		if link.Name != "http" && link.Name != "https" {
+			continue
+		}
+		clone, err := url.Parse(link.Href)
+		if err != nil {
+			continue
+		}
+		clone.User = nil
+		repo.Clone = clone.String()
+		break
+	}
+	return &repo
+}
+
+// convertRepoLite is a helper function used to convert a Bitbucket
+// Server repository structure to the simplified Drone repository
+// structure.
+func convertRepoLite(base string, from *Repo) *model.RepoLite {
+	return &model.RepoLite{
+		Owner:    from.Project.Key,
+		Name:     from.Slug,
+		FullName: fmt.Sprintf("%s/%s", from.Project.Key, from.Slug),
+		Avatar:   fmt.Sprintf("%s/projects/%s/avatar.png", base, from.Project.Key),
+	}
+}
+
+// hasPerm is a helper function that returns true if the user
+// has the permission for the repository.
+func hasPerm(client *Client, repo *Repo, permission string) (bool, error) {
+	opts := url.Values{}
+	opts.Set("name", repo.Name)
+	opts.Set("projectname", repo.Project.Name)
+	opts.Set("permission", permission)
+	opts.Set("limit", "100")
+
+	resp, err := client.ListRepos(opts)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range resp.Values {
+		if r.ID == repo.ID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// deleteHooks is a helper function that deletes all the hooks of
+// the repository whose url starts with link.
+func deleteHooks(client *Client, owner, name, link string) error {
+	hooks, err := client.ListHooks(owner, name)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks.Values {
+		if !strings.HasPrefix(hook.Url, link) {
+			continue
+		}
+		err = client.DeleteHook(owner, name, hook.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildFromPush is a helper function that extracts the Build data
+// from a push hook. It returns nil if the push deleted the branch
+// or tag.
+func buildFromPush(base string, hook *PushHook) *model.Build {
+	if len(hook.Changes) == 0 {
+		return nil
+	}
+	change := hook.Changes[0]
+	if change.Type == "DELETE" {
+		return nil
+	}
+
+	build := &model.Build{
+		Event:     model.EventPush,
+		Commit:    change.ToHash,
+		Ref:       change.Ref.ID,
+		Branch:    change.Ref.DisplayID,
+		Link:      fmt.Sprintf("%s/projects/%s/repos/%s/commits/%s", base, hook.Repo.Project.Key, hook.Repo.Slug, change.ToHash),
+		Author:    hook.Actor.Login,
+		Email:     hook.Actor.Email,
+		Avatar:    fmt.Sprintf("%s/users/%s/avatar.png", base, hook.Actor.Slug),
+		Timestamp: time.Now().UTC().Unix(),
+	}
+	if change.Ref.Type == "TAG" {
+		build.Event = model.EventTag
+		build.Branch = change.Ref.ID
+	}
+	return build
+}
+
+// buildFromPullRequest is a helper function that extracts the Build
+// data from a pull request hook.
+func buildFromPullRequest(hook *PullRequestHook) *model.Build {
+	pr := hook.PullRequest
+	build := &model.Build{
+		Event:     model.EventPull,
+		Commit:    pr.FromRef.LatestCommit,
+		Ref:       fmt.Sprintf("refs/pull-requests/%d/from", pr.ID),
+		Branch:    pr.FromRef.DisplayID,
+		Title:     pr.Title,
+		Message:   pr.Title,
+		Author:    pr.Author.User.Login,
+		Email:     pr.Author.User.Email,
+		Timestamp: time.Now().UTC().Unix(),
+	}
+	if len(pr.Links.Self) != 0 {
+		build.Link = pr.Links.Self[0].Href
+	}
+	return build
+}
+
+// parsePush is a helper function that parses a push hook
+// from a reader.
+func parsePush(r io.Reader) (*PushHook, error) {
+	hook := new(PushHook)
+	err := json.NewDecoder(r).Decode(hook)
+	return hook, err
+}
+
+// parsePullRequest is a helper function that parses a pull
+// request hook from a reader.
+func parsePullRequest(r io.Reader) (*PullRequestHook, error) {
+	hook := new(PullRequestHook)
+	err := json.NewDecoder(r).Decode(hook)
+	return hook, err
+}
@@ -0,0 +1,417 @@
+package stash
+
+import (
+	"crypto/rsa"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/shared/envconfig"
+	"github.com/CiscoCloud/drone/shared/httputil"
+	"github.com/CiscoCloud/drone/shared/oauth1"
+	"github.com/CiscoCloud/drone/shared/statuskey"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	requestTokenURL = "%s/plugins/servlet/oauth/request-token"
+	authorizeURL    = "%s/plugins/servlet/oauth/authorize"
+	accessTokenURL  = "%s/plugins/servlet/oauth/access-token"
+)
+
+type Stash struct {
+	URL         string
+	ConsumerKey string
+	PrivateKey  *rsa.PrivateKey
+	GitUsername string
+	GitPassword string
+	Open        bool
+	SkipVerify  bool
+}
+
+func Load(env envconfig.Env) *Stash {
+	config := env.String("REMOTE_CONFIG", "")
+
+	// parse the remote DSN configuration string
+	url_, err := url.Parse(config)
+	if err != nil {
+		log.Fatalf("unable to parse remote dsn. %s", err)
+	}
+	params := url_.Query()
+	url_.RawQuery = ""
+
+	// create the Stash remote using parameters from
+	// the parsed DSN configuration string.
+	stash := Stash{}
+	stash.URL = url_.String()
+	stash.ConsumerKey = params.Get("consumer_key")
+	stash.GitUsername = params.Get("git_username")
+	stash.GitPassword = params.Get("git_password")
+	stash.Open, _ = strconv.ParseBool(params.Get("open"))
+	stash.SkipVerify, _ = strconv.ParseBool(params.Get("skip_verify"))
+
+	// users authenticate with oauth if the consumer key and its
+	// private key are configured, else with personal access tokens.
+	if len(stash.ConsumerKey) != 0 {
+		raw, err := ioutil.ReadFile(params.Get("private_key"))
+		if err != nil {
+			log.Fatalf("unable to read the stash private key. %s", err)
+		}
+		stash.PrivateKey, err = oauth1.ParsePrivateKey(raw)
+		if err != nil {
+			log.Fatalf("unable to parse the stash private key. %s", err)
+		}
+	}
+
+	return &stash
+}
+
+// Login authenticates the session and returns the
+// remote user details.
+func (s *Stash) Login(res http.ResponseWriter, req *http.Request) (*model.User, bool, error) {
+	if s.PrivateKey == nil {
+		return s.loginToken(res, req)
+	}
+
+	consumer := s.consumer()
+	consumer.CallbackURL = fmt.Sprintf("%s/authorize", httputil.GetURL(req))
+
+	// get the OAuth token and verifier
+	var token = req.FormValue("oauth_token")
+	var verifier = req.FormValue("oauth_verifier")
+	if len(token) == 0 || len(verifier) == 0 {
+		requestToken, err := consumer.RequestToken()
+		if err != nil {
+			return nil, false, err
+		}
+		http.Redirect(res, req, consumer.AuthCodeURL(requestToken), http.StatusSeeOther)
+		return nil, false, nil
+	}
+
+	accessToken, err := consumer.AccessToken(token, verifier)
+	if err != nil {
+		return nil, false, fmt.Errorf("Error exchanging token. %s", err)
+	}
+
+	client := NewClient(s.URL, consumer.Client(accessToken))
+	current, err := client.FindCurrent()
+	if err != nil {
+		return nil, false, err
+	}
+
+	user := s.toUser(current)
+	user.Token = accessToken.Token
+	user.Secret = accessToken.Secret
+	return user, s.Open, nil
+}
+
+// loginToken authenticates the session using the username and
+// the personal access token posted in the password field of the
+// login form. The account password is never accepted, so it is
+// not stored or written to the build netrc.
+func (s *Stash) loginToken(res http.ResponseWriter, req *http.Request) (*model.User, bool, error) {
+	var (
+		username = req.FormValue("username")
+		token    = req.FormValue("password")
+	)
+
+	// if the username or token doesn't exist we re-direct
+	// the user to the login screen.
+	if len(username) == 0 || len(token) == 0 {
+		http.Redirect(res, req, "/login/form", http.StatusSeeOther)
+		return nil, false, nil
+	}
+
+	client := NewClientToken(s.URL, token, s.transport())
+	current, err := client.FindCurrent()
+	if err != nil {
+		return nil, false, err
+	}
+	if !strings.EqualFold(current.Login, username) {
+		return nil, false, fmt.Errorf("Access token does not belong to %s", username)
+	}
+
+	user := s.toUser(current)
+	user.Token = token
+	return user, s.Open, nil
+}
+
+// Auth authenticates the session and returns the remote user
+// login for the given token and secret
+func (s *Stash) Auth(token, secret string) (string, error) {
+	if s.PrivateKey == nil {
+		return "", fmt.Errorf("Method not supported")
+	}
+	client := NewClient(s.URL, s.consumer().Client(&oauth1.Token{Token: token, Secret: secret}))
+	user, err := client.FindCurrent()
+	if err != nil {
+		return "", err
+	}
+	return user.Login, nil
+}
+
+// Repo fetches the named repository from the remote system.
+func (s *Stash) Repo(u *model.User, owner, name string) (*model.Repo, error) {
+	repo, err := s.newClient(u).FindRepo(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return convertRepo(repo), nil
+}
+
+// Repos fetches a list of repos from the remote system.
+func (s *Stash) Repos(u *model.User) ([]*model.RepoLite, error) {
+	var repos []*model.RepoLite
+
+	all, err := s.newClient(u).ListReposAll(permRead)
+	if err != nil {
+		return repos, err
+	}
+	for _, repo := range all {
+		repos = append(repos, convertRepoLite(s.URL, repo))
+	}
+	return repos, nil
+}
+
+// Perm fetches the named repository permissions from
+// the remote system for the specified user.
+func (s *Stash) Perm(u *model.User, owner, name string) (*model.Perm, error) {
+	client := s.newClient(u)
+
+	// if the user can fetch the repository they have at
+	// least read access to the repository.
+	repo, err := client.FindRepo(owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	perm := &model.Perm{Pull: true}
+	perm.Push, err = hasPerm(client, repo, permWrite)
+	if err != nil {
+		return nil, err
+	}
+	perm.Admin, err = hasPerm(client, repo, permAdmin)
+	if err != nil {
+		return nil, err
+	}
+	return perm, nil
+}
+
+// Script fetches the build script (.drone.yml) from the remote
+// repository and returns in string format.
+func (s *Stash) Script(u *model.User, r *model.Repo, b *model.Build) ([]byte, []byte, error) {
+	client := s.newClient(u)
+
+	// fetches the .drone.yml for the specified revision. This file
+	// is required, and will error if not found
+	config, err := client.FindSource(r.Owner, r.Name, b.Commit, ".drone.yml")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// fetches the .drone.sec for the specified revision. This file
+	// is completely optional, therefore we will not return a not
+	// found error
+	sec, _ := client.FindSource(r.Owner, r.Name, b.Commit, ".drone.sec")
+
+	return config, sec, nil
+}
+
+// Status sends the commit status to the remote system.
+// An example would be the GitHub pull request status.
+func (s *Stash) Status(u *model.User, r *model.Repo, b *model.Build, link string) error {
+	status := BuildStatus{
+		State: getStatus(b.Status),
+		Key:   statuskey.Key(r, nil),
+		Name:  StatusName,
+		Url:   link,
+		Desc:  getDesc(b.Status),
+	}
+	return s.newClient(u).CreateStatus(b.Commit, &status)
+}
+
+// JobStatus sends the status of a single build job to the remote
+// system, under a build key that is unique to the job.
+func (s *Stash) JobStatus(u *model.User, r *model.Repo, b *model.Build, j *model.Job, link string) error {
+	status := BuildStatus{
+		State: getStatus(j.Status),
+		Key:   statuskey.Key(r, j),
+		Name:  StatusName + ": " + j.Label(),
+		Url:   link,
+		Desc:  getDesc(j.Status),
+	}
+	return s.newClient(u).CreateStatus(b.Commit, &status)
+}
+
+// Netrc returns a .netrc file that can be used to clone
+// private repositories from a remote system.
+func (s *Stash) Netrc(u *model.User, r *model.Repo) (*model.Netrc, error) {
+	url_, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	netrc := &model.Netrc{
+		Machine:  url_.Host,
+		Login:    s.GitUsername,
+		Password: s.GitPassword,
+	}
+
+	// oauth tokens cannot be used to clone repositories, so
+	// users that log in with oauth require the git account.
+	// Otherwise repositories are cloned with the personal
+	// access token of the user.
+	if len(netrc.Login) == 0 {
+		if s.PrivateKey != nil {
+			return nil, fmt.Errorf("Cloning requires the git_username and git_password options")
+		}
+		netrc.Login = u.Login
+		netrc.Password = u.Token
+	}
+	return netrc, nil
+}
+
+// Activate activates a repository by creating the post-commit hook.
+func (s *Stash) Activate(u *model.User, r *model.Repo, k *model.Key, link string) error {
+	client := s.newClient(u)
+
+	// remove any existing hooks before creating
+	// a new hook, in case the url changed.
+	err := deleteHooks(client, r.Owner, r.Name, link)
+	if err != nil {
+		return err
+	}
+
+	hook := Hook{
+		Name:   "drone",
+		Url:    link,
+		Events: []string{eventPush, eventPullOpened, eventPullUpdated},
+		Active: true,
+	}
+	return client.CreateHook(r.Owner, r.Name, &hook)
+}
+
+// Deactivate removes a repository by removing all the post-commit hooks
+// which are equal to link.
+func (s *Stash) Deactivate(u *model.User, r *model.Repo, link string) error {
+	return deleteHooks(s.newClient(u), r.Owner, r.Name, link)
+}
+
+// Hook parses the post-commit hook from the Request body
+// and returns the required data in a standard format.
+func (s *Stash) Hook(r *http.Request) (*model.Repo, *model.Build, error) {
+	switch r.Header.Get("X-Event-Key") {
+	case eventPush:
+		hook, err := parsePush(r.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+		build := buildFromPush(s.URL, hook)
+		if build == nil {
+			return nil, nil, nil
+		}
+		return convertRepo(&hook.Repo), build, nil
+
+	case eventPullOpened, eventPullUpdated:
+		hook, err := parsePullRequest(r.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+		return convertRepo(&hook.PullRequest.ToRef.Repo), buildFromPullRequest(hook), nil
+	}
+	return nil, nil, nil
+}
+
+func (s *Stash) String() string {
+	return "stash"
+}
+
+// newClient returns a client that authorizes requests as the user,
+// using the oauth token or the personal access token.
+func (s *Stash) newClient(u *model.User) *Client {
+	if s.PrivateKey == nil {
+		return NewClientToken(s.URL, u.Token, s.transport())
+	}
+	token := &oauth1.Token{Token: u.Token, Secret: u.Secret}
+	return NewClient(s.URL, s.consumer().Client(token))
+}
+
+func (s *Stash) consumer() *oauth1.Consumer {
+	return &oauth1.Consumer{
+		ConsumerKey:     s.ConsumerKey,
+		PrivateKey:      s.PrivateKey,
+		RequestTokenURL: fmt.Sprintf(requestTokenURL, s.URL),
+		AuthorizeURL:    fmt.Sprintf(authorizeURL, s.URL),
+		AccessTokenURL:  fmt.Sprintf(accessTokenURL, s.URL),
+		Transport:       s.transport(),
+	}
+}
+
+func (s *Stash) transport() http.RoundTripper {
+	if !s.SkipVerify {
+		return http.DefaultTransport
+	}
+	return &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+}
+
+func (s *Stash) toUser(from *User) *model.User {
+	return &model.User{
+		Login:  from.Login,
+		Email:  from.Email,
+		Avatar: fmt.Sprintf("%s/users/%s/avatar.png", s.URL, from.Slug),
+	}
+}
+
+const StatusName = "Drone"
+
+const (
+	StatusPending = "INPROGRESS"
+	StatusSuccess = "SUCCESSFUL"
+	StatusFailure = "FAILED"
+)
+
+const (
+	DescPending = "this build is pending"
+	DescSuccess = "the build was successful"
+	DescFailure = "the build failed"
+	DescError   = "oops, something went wrong"
+	DescKilled  = "the build was killed"
+)
+
+// getStatus is a helper function that converts a Drone
+// status to a Bitbucket Server build status.
+func getStatus(status string) string {
+	switch status {
+	case model.StatusPending, model.StatusRunning:
+		return StatusPending
+	case model.StatusSuccess:
+		return StatusSuccess
+	default:
+		return StatusFailure
+	}
+}
+
+// getDesc is a helper function that generates a description
+// message for the build based on the status.
+func getDesc(status string) string {
+	switch status {
+	case model.StatusPending, model.StatusRunning:
+		return DescPending
+	case model.StatusSuccess:
+		return DescSuccess
+	case model.StatusFailure:
+		return DescFailure
+	case model.StatusError:
+		return DescError
+	default:
+		return DescKilled
+	}
+}
@@ -0,0 +1,279 @@
+package stash
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/remote/stash/testdata"
+	"github.com/franela/goblin"
+)
+
+func Test_Stash(t *testing.T) {
+	// setup a dummy bitbucket server
+	var server = testdata.NewServer()
+	defer server.Close()
+
+	stash := Load(map[string]string{"REMOTE_CONFIG": server.URL + "?open=true"})
+
+	var user = model.User{
+		Login: "gordon",
+		Token: "token",
+	}
+
+	var repo = model.Repo{
+		Owner:    "GO",
+		Name:     "hello-world",
+		FullName: "GO/hello-world",
+	}
+
+	var build = model.Build{
+		Number: 1,
+		Commit: "9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5",
+		Status: model.StatusSuccess,
+	}
+
+	g := goblin.Goblin(t)
+	g.Describe("Stash Plugin", func() {
+
+		g.It("Should load the configuration", func() {
+			g.Assert(stash.URL).Equal(server.URL)
+			g.Assert(stash.Open).IsTrue()
+			g.Assert(stash.PrivateKey == nil).IsTrue()
+			g.Assert(stash.String()).Equal("stash")
+		})
+
+		g.Describe("Login", func() {
+			g.It("Should redirect to the login form", func() {
+				req, _ := http.NewRequest("GET", "http://drone.golang.org/authorize", nil)
+				res := httptest.NewRecorder()
+				u, _, err := stash.Login(res, req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(u == nil).IsTrue()
+				g.Assert(res.Header().Get("Location")).Equal("/login/form")
+			})
+
+			g.It("Should login with a personal access token", func() {
+				form := url.Values{"username": {"gordon"}, "password": {"token"}}
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/authorize", bytes.NewBufferString(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				u, open, err := stash.Login(httptest.NewRecorder(), req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(open).IsTrue()
+				g.Assert(u.Login).Equal("gordon")
+				g.Assert(u.Email).Equal("gordon@golang.org")
+				g.Assert(u.Token).Equal("token")
+				g.Assert(u.Avatar).Equal(server.URL + "/users/gordon/avatar.png")
+			})
+
+			g.It("Should fail with invalid credentials", func() {
+				form := url.Values{"username": {"gordon"}, "password": {"invalid"}}
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/authorize", bytes.NewBufferString(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				_, _, err := stash.Login(httptest.NewRecorder(), req)
+				g.Assert(err != nil).IsTrue()
+			})
+
+			g.It("Should fail if the token belongs to another user", func() {
+				form := url.Values{"username": {"octocat"}, "password": {"token"}}
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/authorize", bytes.NewBufferString(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				_, _, err := stash.Login(httptest.NewRecorder(), req)
+				g.Assert(err != nil).IsTrue()
+			})
+
+			g.It("Should login with oauth", func() {
+				key, _ := rsa.GenerateKey(rand.Reader, 1024)
+				oauth := &Stash{URL: server.URL, ConsumerKey: "drone", PrivateKey: key}
+
+				req, _ := http.NewRequest("GET", "http://drone.golang.org/authorize", nil)
+				res := httptest.NewRecorder()
+				u, _, err := oauth.Login(res, req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(u == nil).IsTrue()
+				g.Assert(res.Header().Get("Location")).Equal(server.URL + "/plugins/servlet/oauth/authorize?oauth_token=request_token")
+
+				req, _ = http.NewRequest("GET", "http://drone.golang.org/authorize?oauth_token=request_token&oauth_verifier=verified", nil)
+				u, _, err = oauth.Login(httptest.NewRecorder(), req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(u.Login).Equal("gordon")
+				g.Assert(u.Token).Equal("access_token")
+				g.Assert(u.Secret).Equal("access_secret")
+
+				login, err := oauth.Auth("access_token", "access_secret")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(login).Equal("gordon")
+			})
+		})
+
+		g.Describe("Repo", func() {
+			g.It("Should return a valid repo", func() {
+				r, err := stash.Repo(&user, "GO", "hello-world")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(r.Owner).Equal("GO")
+				g.Assert(r.Name).Equal("hello-world")
+				g.Assert(r.FullName).Equal("GO/hello-world")
+				g.Assert(r.IsPrivate).IsTrue()
+				g.Assert(r.Clone).Equal("https://stash.golang.org/scm/go/hello-world.git")
+				g.Assert(r.Link).Equal("https://stash.golang.org/projects/GO/repos/hello-world/browse")
+			})
+
+			g.It("Should return an error, when the repo does not exist", func() {
+				_, err := stash.Repo(&user, "GO", "not-found")
+				g.Assert(err != nil).IsTrue()
+				g.Assert(err.Error()).Equal("Repository GO/not-found does not exist.")
+			})
+
+			g.It("Should return all pages of repos", func() {
+				repos, err := stash.Repos(&user)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(len(repos)).Equal(2)
+				g.Assert(repos[0].FullName).Equal("GO/hello-world")
+				g.Assert(repos[1].FullName).Equal("GO/goodbye-world")
+				g.Assert(repos[0].Avatar).Equal(server.URL + "/projects/GO/avatar.png")
+			})
+
+			g.It("Should return the repo permissions", func() {
+				perm, err := stash.Perm(&user, "GO", "hello-world")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(perm.Pull).IsTrue()
+				g.Assert(perm.Push).IsTrue()
+				g.Assert(perm.Admin).IsFalse()
+			})
+		})
+
+		g.Describe("Script", func() {
+			g.It("Should return the .drone.yml at the commit", func() {
+				raw, sec, err := stash.Script(&user, &repo, &build)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(string(raw)).Equal("build:\n  image: golang\n")
+				g.Assert(len(sec)).Equal(0)
+			})
+
+			g.It("Should return an error, when the commit does not exist", func() {
+				other := model.Build{Commit: "0000000000000000000000000000000000000000"}
+				_, _, err := stash.Script(&user, &repo, &other)
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.Describe("Status", func() {
+			g.It("Should send the build status", func() {
+				err := stash.Status(&user, &repo, &build, "http://drone.golang.org/GO/hello-world/1")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should send the job status", func() {
+				job := model.Job{Number: 2, Status: model.StatusKilled}
+				err := stash.JobStatus(&user, &repo, &build, &job, "http://drone.golang.org/repos/GO/hello-world/builds/1/2")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should map the build status", func() {
+				g.Assert(getStatus(model.StatusPending)).Equal(StatusPending)
+				g.Assert(getStatus(model.StatusRunning)).Equal(StatusPending)
+				g.Assert(getStatus(model.StatusSuccess)).Equal(StatusSuccess)
+				g.Assert(getStatus(model.StatusFailure)).Equal(StatusFailure)
+				g.Assert(getStatus(model.StatusError)).Equal(StatusFailure)
+				g.Assert(getStatus(model.StatusKilled)).Equal(StatusFailure)
+			})
+		})
+
+		g.Describe("Netrc", func() {
+			g.It("Should return the user access token", func() {
+				netrc, err := stash.Netrc(&user, &repo)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(netrc.Login).Equal("gordon")
+				g.Assert(netrc.Password).Equal("token")
+			})
+
+			g.It("Should return the git credentials", func() {
+				other := *stash
+				other.GitUsername = "drone"
+				other.GitPassword = "secret"
+				netrc, err := other.Netrc(&user, &repo)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(netrc.Login).Equal("drone")
+				g.Assert(netrc.Password).Equal("secret")
+			})
+
+			g.It("Should require the git credentials with oauth", func() {
+				key, _ := rsa.GenerateKey(rand.Reader, 1024)
+				other := Stash{URL: server.URL, PrivateKey: key}
+				_, err := other.Netrc(&user, &repo)
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.Describe("Hooks", func() {
+			g.It("Should activate the repo", func() {
+				err := stash.Activate(&user, &repo, &model.Key{}, "http://drone.golang.org/hook?access_token=secret")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should deactivate the repo", func() {
+				err := stash.Deactivate(&user, &repo, "http://drone.golang.org")
+				g.Assert(err == nil).IsTrue()
+			})
+
+			g.It("Should parse the push hook", func() {
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.PushHook))
+				req.Header.Set("X-Event-Key", "repo:refs_changed")
+				r, b, err := stash.Hook(req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(r.FullName).Equal("GO/hello-world")
+				g.Assert(b.Event).Equal(model.EventPush)
+				g.Assert(b.Commit).Equal("9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5")
+				g.Assert(b.Ref).Equal("refs/heads/master")
+				g.Assert(b.Branch).Equal("master")
+				g.Assert(b.Author).Equal("gordon")
+				g.Assert(b.Email).Equal("gordon@golang.org")
+				g.Assert(b.Link).Equal(server.URL + "/projects/GO/repos/hello-world/commits/9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5")
+			})
+
+			g.It("Should parse the tag hook", func() {
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.TagHook))
+				req.Header.Set("X-Event-Key", "repo:refs_changed")
+				_, b, err := stash.Hook(req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(b.Event).Equal(model.EventTag)
+				g.Assert(b.Ref).Equal("refs/tags/v1.0.0")
+			})
+
+			g.It("Should ignore deleted branches", func() {
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.DeleteHook))
+				req.Header.Set("X-Event-Key", "repo:refs_changed")
+				_, b, err := stash.Hook(req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(b == nil).IsTrue()
+			})
+
+			g.It("Should parse the pull request hook", func() {
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString(testdata.PullRequestHook))
+				req.Header.Set("X-Event-Key", "pr:opened")
+				r, b, err := stash.Hook(req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(r.FullName).Equal("GO/hello-world")
+				g.Assert(b.Event).Equal(model.EventPull)
+				g.Assert(b.Commit).Equal("9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5")
+				g.Assert(b.Ref).Equal("refs/pull-requests/12/from")
+				g.Assert(b.Branch).Equal("feature/readme")
+				g.Assert(b.Title).Equal("Add a README")
+				g.Assert(b.Link).Equal("https://stash.golang.org/projects/GO/repos/hello-world/pull-requests/12")
+			})
+
+			g.It("Should ignore other events", func() {
+				req, _ := http.NewRequest("POST", "http://drone.golang.org/hook", bytes.NewBufferString("{}"))
+				req.Header.Set("X-Event-Key", "diagnostics:ping")
+				_, b, err := stash.Hook(req)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(b == nil).IsTrue()
+			})
+		})
+	})
+}
@@ -0,0 +1,205 @@
+package testdata
+
+// sample webhook list response
+var hooksPayload = []byte(`
+{
+  "size": 2,
+  "limit": 25,
+  "isLastPage": true,
+  "start": 0,
+  "values": [
+    {
+      "id": 1,
+      "name": "drone",
+      "url": "http://drone.golang.org/hook?access_token=secret",
+      "events": ["repo:refs_changed", "pr:opened", "pr:from_ref_updated"],
+      "active": true
+    },
+    {
+      "id": 2,
+      "name": "chat",
+      "url": "http://chat.golang.org/hook",
+      "events": ["repo:refs_changed"],
+      "active": true
+    }
+  ]
+}
+`)
+
+// sample webhook response
+var hookPayload = []byte(`
+{
+  "id": 3,
+  "name": "drone",
+  "url": "http://drone.golang.org/hook?access_token=secret",
+  "events": ["repo:refs_changed", "pr:opened", "pr:from_ref_updated"],
+  "active": true
+}
+`)
+
+// PushHook is a sample push hook payload.
+var PushHook = `
+{
+  "eventKey": "repo:refs_changed",
+  "date": "2017-09-19T09:45:32+1000",
+  "actor": {
+    "name": "gordon",
+    "emailAddress": "gordon@golang.org",
+    "id": 101,
+    "displayName": "Gordon the Gopher",
+    "active": true,
+    "slug": "gordon",
+    "type": "NORMAL"
+  },
+  "repository": {
+    "slug": "hello-world",
+    "id": 1,
+    "name": "Hello World",
+    "scmId": "git",
+    "state": "AVAILABLE",
+    "forkable": true,
+    "project": {
+      "key": "GO",
+      "id": 1,
+      "name": "Gophers",
+      "public": false,
+      "type": "NORMAL"
+    },
+    "public": false
+  },
+  "changes": [
+    {
+      "ref": {
+        "id": "refs/heads/master",
+        "displayId": "master",
+        "type": "BRANCH"
+      },
+      "refId": "refs/heads/master",
+      "fromHash": "ecddabb624f6f5ba43816f5926e580a5f680a932",
+      "toHash": "9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5",
+      "type": "UPDATE"
+    }
+  ]
+}
+`
+
+// TagHook is a sample push hook payload for a tag.
+var TagHook = `
+{
+  "eventKey": "repo:refs_changed",
+  "actor": {
+    "name": "gordon",
+    "emailAddress": "gordon@golang.org",
+    "slug": "gordon"
+  },
+  "repository": {
+    "slug": "hello-world",
+    "id": 1,
+    "name": "Hello World",
+    "project": { "key": "GO", "id": 1, "name": "Gophers" }
+  },
+  "changes": [
+    {
+      "ref": {
+        "id": "refs/tags/v1.0.0",
+        "displayId": "v1.0.0",
+        "type": "TAG"
+      },
+      "refId": "refs/tags/v1.0.0",
+      "fromHash": "0000000000000000000000000000000000000000",
+      "toHash": "9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5",
+      "type": "ADD"
+    }
+  ]
+}
+`
+
+// DeleteHook is a sample push hook payload that deletes a branch.
+var DeleteHook = `
+{
+  "eventKey": "repo:refs_changed",
+  "actor": { "name": "gordon", "slug": "gordon" },
+  "repository": {
+    "slug": "hello-world",
+    "id": 1,
+    "name": "Hello World",
+    "project": { "key": "GO", "id": 1, "name": "Gophers" }
+  },
+  "changes": [
+    {
+      "ref": {
+        "id": "refs/heads/feature",
+        "displayId": "feature",
+        "type": "BRANCH"
+      },
+      "refId": "refs/heads/feature",
+      "fromHash": "9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5",
+      "toHash": "0000000000000000000000000000000000000000",
+      "type": "DELETE"
+    }
+  ]
+}
+`
+
+// PullRequestHook is a sample pull request opened hook payload.
+var PullRequestHook = `
+{
+  "eventKey": "pr:opened",
+  "date": "2017-09-19T09:58:11+1000",
+  "actor": {
+    "name": "gordon",
+    "emailAddress": "gordon@golang.org",
+    "slug": "gordon"
+  },
+  "pullRequest": {
+    "id": 12,
+    "version": 0,
+    "title": "Add a README",
+    "description": "please merge",
+    "state": "OPEN",
+    "open": true,
+    "closed": false,
+    "fromRef": {
+      "id": "refs/heads/feature/readme",
+      "displayId": "feature/readme",
+      "latestCommit": "9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5",
+      "repository": {
+        "slug": "hello-world",
+        "id": 1,
+        "name": "Hello World",
+        "project": { "key": "GO", "id": 1, "name": "Gophers" },
+        "public": false
+      }
+    },
+    "toRef": {
+      "id": "refs/heads/master",
+      "displayId": "master",
+      "latestCommit": "ecddabb624f6f5ba43816f5926e580a5f680a932",
+      "repository": {
+        "slug": "hello-world",
+        "id": 1,
+        "name": "Hello World",
+        "project": { "key": "GO", "id": 1, "name": "Gophers" },
+        "public": false
+      }
+    },
+    "locked": false,
+    "author": {
+      "user": {
+        "name": "gordon",
+        "emailAddress": "gordon@golang.org",
+        "slug": "gordon"
+      },
+      "role": "AUTHOR",
+      "approved": false
+    },
+    "links": {
+      "self": [
+        {
+          "href": "https://stash.golang.org/projects/GO/repos/hello-world/pull-requests/12"
+        }
+      ]
+    }
+  }
+}
+`
@@ -0,0 +1,117 @@
+package testdata
+
+// sample repository response
+var repoPayload = []byte(`
+{
+  "slug": "hello-world",
+  "id": 1,
+  "name": "Hello World",
+  "scmId": "git",
+  "state": "AVAILABLE",
+  "forkable": true,
+  "project": {
+    "key": "GO",
+    "id": 1,
+    "name": "Gophers",
+    "public": false,
+    "type": "NORMAL"
+  },
+  "public": false,
+  "links": {
+    "clone": [
+      {
+        "href": "ssh://git@stash.golang.org:7999/go/hello-world.git",
+        "name": "ssh"
+      },
+      {
+        "href": "https://gordon@stash.golang.org/scm/go/hello-world.git",
+        "name": "http"
+      }
+    ],
+    "self": [
+      {
+        "href": "https://stash.golang.org/projects/GO/repos/hello-world/browse"
+      }
+    ]
+  }
+}
+`)
+
+// sample repository list response, first page
+var reposPayload = []byte(`
+{
+  "size": 1,
+  "limit": 1,
+  "isLastPage": false,
+  "start": 0,
+  "nextPageStart": 1,
+  "values": [
+    {
+      "slug": "hello-world",
+      "id": 1,
+      "name": "Hello World",
+      "project": { "key": "GO", "id": 1, "name": "Gophers" }
+    }
+  ]
+}
+`)
+
+// sample repository list response, last page
+var reposPage2Payload = []byte(`
+{
+  "size": 1,
+  "limit": 1,
+  "isLastPage": true,
+  "start": 1,
+  "values": [
+    {
+      "slug": "goodbye-world",
+      "id": 2,
+      "name": "Goodbye World",
+      "project": { "key": "GO", "id": 1, "name": "Gophers" }
+    }
+  ]
+}
+`)
+
+// sample repository list response for write permission
+var reposWritePayload = []byte(`
+{
+  "size": 1,
+  "limit": 100,
+  "isLastPage": true,
+  "start": 0,
+  "values": [
+    {
+      "slug": "hello-world",
+      "id": 1,
+      "name": "Hello World",
+      "project": { "key": "GO", "id": 1, "name": "Gophers" }
+    }
+  ]
+}
+`)
+
+// sample empty repository list response
+var reposEmptyPayload = []byte(`
+{
+  "size": 0,
+  "limit": 100,
+  "isLastPage": true,
+  "start": 0,
+  "values": []
+}
+`)
+
+// sample build status error response
+var statusErrorPayload = []byte(`
+{
+  "errors": [
+    {
+      "context": "key",
+      "message": "The key must not be empty.",
+      "exceptionName": null
+    }
+  ]
+}
+`)
@@ -0,0 +1,121 @@
+package testdata
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// setup a mock server for testing purposes.
+func NewServer() *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	// handle requests and serve mock data
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// oauth requests only need to be signed, the
+		// signature itself is tested by the oauth1 package.
+		switch r.URL.Path {
+		case "/plugins/servlet/oauth/request-token":
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "OAuth ") {
+				break
+			}
+			w.Write([]byte("oauth_token=request_token&oauth_token_secret=request_secret"))
+			return
+		case "/plugins/servlet/oauth/access-token":
+			if !strings.Contains(r.Header.Get("Authorization"), `oauth_token="request_token"`) {
+				break
+			}
+			w.Write([]byte("oauth_token=access_token&oauth_token_secret=access_secret"))
+			return
+		}
+
+		// all other requests are authorized with the personal
+		// access token, or with the oauth access token.
+		if !strings.Contains(r.Header.Get("Authorization"), `oauth_token="access_token"`) &&
+			r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(401)
+			w.Write(unauthorizedPayload)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/plugins/servlet/applinks/whoami":
+			w.Write([]byte("gordon"))
+			return
+		case "/rest/api/1.0/users/gordon":
+			w.Write(userPayload)
+			return
+		case "/rest/api/1.0/projects/GO/repos/hello-world":
+			w.Write(repoPayload)
+			return
+		case "/rest/api/1.0/repos":
+			switch r.FormValue("permission") {
+			case "REPO_READ":
+				if r.FormValue("start") == "0" {
+					w.Write(reposPayload)
+				} else {
+					w.Write(reposPage2Payload)
+				}
+			case "REPO_WRITE":
+				w.Write(reposWritePayload)
+			default:
+				w.Write(reposEmptyPayload)
+			}
+			return
+		case "/projects/GO/repos/hello-world/raw/.drone.yml":
+			if r.FormValue("at") != "9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5" {
+				break
+			}
+			w.Write([]byte("build:\n  image: golang\n"))
+			return
+		case "/rest/build-status/1.0/commits/9a3c5b85a9a3e71cc2cb8e8c8bb5d4e0e2e1c4b5":
+			in := map[string]string{}
+			json.NewDecoder(r.Body).Decode(&in)
+			switch in["state"] {
+			case "INPROGRESS", "SUCCESSFUL", "FAILED":
+			default:
+				w.WriteHeader(400)
+				w.Write(statusErrorPayload)
+				return
+			}
+			if in["key"] == "" || in["url"] == "" {
+				w.WriteHeader(400)
+				w.Write(statusErrorPayload)
+				return
+			}
+			w.WriteHeader(204)
+			return
+		case "/rest/api/1.0/projects/GO/repos/hello-world/webhooks":
+			switch r.Method {
+			case "GET":
+				w.Write(hooksPayload)
+				return
+			case "POST":
+				in := map[string]interface{}{}
+				json.NewDecoder(r.Body).Decode(&in)
+				if in["url"] == nil || in["events"] == nil {
+					w.WriteHeader(400)
+					return
+				}
+				w.WriteHeader(201)
+				w.Write(hookPayload)
+				return
+			}
+		case "/rest/api/1.0/projects/GO/repos/hello-world/webhooks/1":
+			if r.Method == "DELETE" {
+				w.WriteHeader(204)
+				return
+			}
+		}
+
+		// else return a 404
+		w.WriteHeader(404)
+		w.Write(notFoundPayload)
+	})
+
+	// return the server to the client which
+	// will need to know the base URL path
+	return server
+}
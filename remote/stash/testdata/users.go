@@ -0,0 +1,40 @@
+package testdata
+
+// sample user response
+var userPayload = []byte(`
+{
+  "name": "gordon",
+  "emailAddress": "gordon@golang.org",
+  "id": 101,
+  "displayName": "Gordon the Gopher",
+  "active": true,
+  "slug": "gordon",
+  "type": "NORMAL"
+}
+`)
+
+// sample unauthorized error response
+var unauthorizedPayload = []byte(`
+{
+  "errors": [
+    {
+      "context": null,
+      "message": "Authentication failed. Please check your credentials and try again.",
+      "exceptionName": "com.atlassian.bitbucket.auth.IncorrectPasswordAuthenticationException"
+    }
+  ]
+}
+`)
+
+// sample not found error response
+var notFoundPayload = []byte(`
+{
+  "errors": [
+    {
+      "context": null,
+      "message": "Repository GO/not-found does not exist.",
+      "exceptionName": "com.atlassian.bitbucket.repository.NoSuchRepositoryException"
+    }
+  ]
+}
+`)
@@ -0,0 +1,121 @@
+package stash
+
+import "net/http"
+
+type User struct {
+	ID    int64  `json:"id"`
+	Login string `json:"name"`
+	Name  string `json:"displayName"`
+	Email string `json:"emailAddress"`
+	Slug  string `json:"slug"`
+}
+
+type Project struct {
+	ID     int64  `json:"id"`
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Public bool   `json:"public"`
+}
+
+type Repo struct {
+	ID      int64   `json:"id"`
+	Slug    string  `json:"slug"`
+	Name    string  `json:"name"`
+	Scm     string  `json:"scmId"`
+	Public  bool    `json:"public"`
+	Project Project `json:"project"`
+	Links   Links   `json:"links"`
+}
+
+type RepoResp struct {
+	Size          int     `json:"size"`
+	Limit         int     `json:"limit"`
+	Start         int     `json:"start"`
+	IsLastPage    bool    `json:"isLastPage"`
+	NextPageStart int     `json:"nextPageStart"`
+	Values        []*Repo `json:"values"`
+}
+
+type Links struct {
+	Clone []Link `json:"clone"`
+	Self  []Link `json:"self"`
+}
+
+type Link struct {
+	Href string `json:"href"`
+	Name string `json:"name"`
+}
+
+type Hook struct {
+	ID     int64    `json:"id,omitempty"`
+	Name   string   `json:"name"`
+	Url    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+}
+
+type HookResp struct {
+	Size       int     `json:"size"`
+	IsLastPage bool    `json:"isLastPage"`
+	Values     []*Hook `json:"values"`
+}
+
+type BuildStatus struct {
+	State string `json:"state"`
+	Key   string `json:"key"`
+	Name  string `json:"name,omitempty"`
+	Url   string `json:"url"`
+	Desc  string `json:"description,omitempty"`
+}
+
+type Ref struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId"`
+	Type         string `json:"type"`
+	LatestCommit string `json:"latestCommit"`
+	Repo         Repo   `json:"repository"`
+}
+
+type PushHook struct {
+	EventKey string `json:"eventKey"`
+	Actor    User   `json:"actor"`
+	Repo     Repo   `json:"repository"`
+	Changes  []struct {
+		Ref      Ref    `json:"ref"`
+		RefID    string `json:"refId"`
+		FromHash string `json:"fromHash"`
+		ToHash   string `json:"toHash"`
+		Type     string `json:"type"`
+	} `json:"changes"`
+}
+
+type PullRequestHook struct {
+	EventKey    string `json:"eventKey"`
+	Actor       User   `json:"actor"`
+	PullRequest struct {
+		ID      int64  `json:"id"`
+		Title   string `json:"title"`
+		Desc    string `json:"description"`
+		State   string `json:"state"`
+		FromRef Ref    `json:"fromRef"`
+		ToRef   Ref    `json:"toRef"`
+		Author  struct {
+			User User `json:"user"`
+		} `json:"author"`
+		Links Links `json:"links"`
+	} `json:"pullRequest"`
+}
+
+type Error struct {
+	Status int
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (e Error) Error() string {
+	if len(e.Errors) == 0 {
+		return http.StatusText(e.Status)
+	}
+	return e.Errors[0].Message
+}
@@ -1,9 +1,13 @@
 package context
 
 import (
+	"time"
+
 	"github.com/CiscoCloud/drone/engine"
 	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/shared/httputil"
 	"github.com/CiscoCloud/drone/store"
+	"github.com/CiscoCloud/drone/yaml/matrix"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,17 +18,76 @@ func SetStore(s store.Store) gin.HandlerFunc {
 	}
 }
 
-func SetRemote(remote remote.Remote) gin.HandlerFunc {
+// SetRemotes sets the remotes hosted by the server, and selects the
+// remote of the request with the remote url parameter, or with the
+// remote cookie set when the user logs in. The user or repository of
+// the request selects the remote later in the middleware chain.
+func SetRemotes(remotes *remote.Remotes) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Set("remote", remote)
+		c.Set("remotes", remotes)
+		c.Set("request_remote_id", "")
+
+		id := c.Request.URL.Query().Get("remote")
+		if len(id) != 0 && SelectRemote(c, id) {
+			c.Set("request_remote_id", RemoteID(c))
+		} else if !SelectRemote(c, httputil.GetCookie(c.Request, "user_remote")) {
+			SelectRemote(c, "")
+		}
+
+		// the user logged in with a web browser belongs to the
+		// remote of the cookie, even when the request selects
+		// another remote to view its public repositories.
+		cookie := httputil.GetCookie(c.Request, "user_remote")
+		if len(cookie) != 0 && remotes.Get(cookie) != nil {
+			c.Set("user_remote_id", remotes.ID(cookie))
+		} else {
+			c.Set("user_remote_id", RemoteID(c))
+		}
 		c.Next()
 	}
 }
 
+// SelectRemote selects the remote with the id for the request, or
+// the default remote if the id is empty. It returns false if the
+// remote does not exist.
+func SelectRemote(c *gin.Context, id string) bool {
+	remotes := Remotes(c)
+	remote_ := remotes.Get(id)
+	if remote_ == nil {
+		return false
+	}
+	c.Set("remote", remote_)
+	c.Set("remote_id", remotes.ID(id))
+	return true
+}
+
 func Remote(c *gin.Context) remote.Remote {
 	return c.MustGet("remote").(remote.Remote)
 }
 
+// RemoteID returns the id of the remote selected for the request.
+func RemoteID(c *gin.Context) string {
+	return c.MustGet("remote_id").(string)
+}
+
+// RequestRemoteID returns the id of the remote selected with the
+// remote url parameter, or an empty string if the request does not
+// select a remote.
+func RequestRemoteID(c *gin.Context) string {
+	return c.MustGet("request_remote_id").(string)
+}
+
+// UserRemoteID returns the id of the remote of the user sending the
+// request, which is the remote the user logged in with, or the remote
+// selected for the request for API clients.
+func UserRemoteID(c *gin.Context) string {
+	return c.MustGet("user_remote_id").(string)
+}
+
+func Remotes(c *gin.Context) *remote.Remotes {
+	return c.MustGet("remotes").(*remote.Remotes)
+}
+
 func SetEngine(engine engine.Engine) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Set("engine", engine)
@@ -35,3 +98,37 @@ func SetEngine(engine engine.Engine) gin.HandlerFunc {
 func Engine(c *gin.Context) engine.Engine {
 	return c.MustGet("engine").(engine.Engine)
 }
+
+func SetMatrix(limits matrix.Limits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("matrix", limits)
+		c.Next()
+	}
+}
+
+// Matrix returns the maximum size of the build matrix,
+// or the default limits if none are configured.
+func Matrix(c *gin.Context) matrix.Limits {
+	v, ok := c.Get("matrix")
+	if !ok {
+		return matrix.DefaultLimits
+	}
+	return v.(matrix.Limits)
+}
+
+func SetHookRetention(retention time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("hook_retention", retention)
+		c.Next()
+	}
+}
+
+// HookRetention returns how long hook deliveries are stored,
+// or zero if hook deliveries are not stored.
+func HookRetention(c *gin.Context) time.Duration {
+	v, ok := c.Get("hook_retention")
+	if !ok {
+		return 0
+	}
+	return v.(time.Duration)
+}
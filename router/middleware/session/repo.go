@@ -5,6 +5,7 @@ import (
 
 	"github.com/CiscoCloud/drone/model"
 	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/router/middleware/context"
 	"github.com/CiscoCloud/drone/shared/token"
 	"github.com/CiscoCloud/drone/store"
 
@@ -43,18 +44,28 @@ func SetRepo() gin.HandlerFunc {
 			name  = c.Param("name")
 		)
 
+		// the repository belongs to the remote of the remote url
+		// parameter, so that users can view the public repositories
+		// of other remotes, or else to the remote of the user.
+		id := context.RequestRemoteID(c)
+		if len(id) == 0 {
+			id = context.RemoteID(c)
+		}
+
 		user := User(c)
-		repo, err := store.GetRepoOwnerName(c, owner, name)
+		repo, err := store.GetRepoOwnerName(c, owner, name, id)
 		if err == nil {
 			c.Set("repo", repo)
+			context.SelectRemote(c, repo.Remote)
 			c.Next()
 			return
 		}
 
 		// if the user is not nil, check the remote system
 		// to see if the repository actually exists. If yes,
-		// we can prompt the user to add.
-		if user != nil {
+		// we can prompt the user to add. Repositories are only
+		// activated in the remote of the user.
+		if user != nil && context.Remotes(c).ID(user.Remote) == id {
 			remote := remote.FromContext(c)
 			repo, err = remote.Repo(user, owner, name)
 			if err != nil {
@@ -143,6 +154,15 @@ func SetPerm() gin.HandlerFunc {
 			perm.Push = true
 			perm.Admin = true
 
+		// if the user and the repository are from different
+		// remotes, the user has no permissions in the remote
+		// system, and has pull-rights only if the repository
+		// is public.
+		case !sameRemote(c, user, repo):
+			perm.Pull = !repo.IsPrivate
+			perm.Push = false
+			perm.Admin = false
+
 		// otherwise if the user is authenticated we should
 		// check the remote system to get the users permissiosn.
 		default:
@@ -177,6 +197,13 @@ func SetPerm() gin.HandlerFunc {
 	}
 }
 
+// helper function returns true if the user and
+// the repository are from the same remote.
+func sameRemote(c *gin.Context, user *model.User, repo *model.Repo) bool {
+	remotes := context.Remotes(c)
+	return remotes.ID(user.Remote) == remotes.ID(repo.Remote)
+}
+
 func MustPull(c *gin.Context) {
 	user := User(c)
 	repo := Repo(c)
@@ -0,0 +1,94 @@
+package session
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/remote"
+	"github.com/CiscoCloud/drone/router/middleware/context"
+	"github.com/CiscoCloud/drone/shared/token"
+	"github.com/CiscoCloud/drone/store/datastore"
+	"github.com/franela/goblin"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRepo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	s := datastore.New("sqlite3", ":memory:")
+
+	user := &model.User{Login: "octocat", Token: "token", Hash: "hash", Remote: "github"}
+	s.Users().Create(user)
+	s.Repos().Create(&model.Repo{UserID: 1, Owner: "octocat", Name: "hello-world", FullName: "octocat/hello-world", Remote: "github"})
+	s.Repos().Create(&model.Repo{UserID: 2, Owner: "octocat", Name: "hello-world", FullName: "octocat/hello-world", Remote: "gitlab"})
+	s.Repos().Create(&model.Repo{UserID: 2, Owner: "octocat", Name: "secret", FullName: "octocat/secret", Remote: "gitlab", IsPrivate: true})
+
+	remotes := remote.NewRemotes()
+	remotes.Add("github", &fakeRemote{})
+	remotes.Add("gitlab", &fakeRemote{})
+
+	e := gin.New()
+	e.SetHTMLTemplate(template.Must(template.New("404.html").Parse("not found")))
+	e.Use(context.SetStore(s))
+	e.Use(context.SetRemotes(remotes))
+	e.Use(SetUser())
+	e.GET("/repos/:owner/:name", SetRepo(), SetPerm(), MustPull, func(c *gin.Context) {
+		login := ""
+		if user := User(c); user != nil {
+			login = user.Login
+		}
+		c.String(200, "%s %s %v", login, Repo(c).Remote, Perm(c).Push)
+	})
+
+	sess, _ := token.New(token.SessToken, user.Login).Sign(user.Hash)
+
+	// helper function to request the repository with
+	// the session of the user.
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", url, nil)
+		r.AddCookie(&http.Cookie{Name: "user_sess", Value: sess})
+		r.AddCookie(&http.Cookie{Name: "user_remote", Value: "github"})
+		e.ServeHTTP(w, r)
+		return w
+	}
+
+	g := goblin.Goblin(t)
+	g.Describe("Repo middleware", func() {
+
+		g.It("Should find the repository in the remote of the user", func() {
+			w := get("/repos/octocat/hello-world")
+			g.Assert(w.Code).Equal(200)
+			g.Assert(w.Body.String()).Equal("octocat github true")
+		})
+
+		g.It("Should find the public repository of another remote", func() {
+			w := get("/repos/octocat/hello-world?remote=gitlab")
+			g.Assert(w.Code).Equal(200)
+			g.Assert(w.Body.String()).Equal("octocat gitlab false")
+		})
+
+		g.It("Should not find the private repository of another remote", func() {
+			w := get("/repos/octocat/secret?remote=gitlab")
+			g.Assert(w.Code).Equal(404)
+		})
+	})
+}
+
+// fakeRemote is a remote that grants the user
+// push permission to every repository.
+type fakeRemote struct {
+	remote.Remote
+}
+
+func (r *fakeRemote) Perm(u *model.User, owner, name string) (*model.Perm, error) {
+	return &model.Perm{Pull: true, Push: true}, nil
+}
+
+func (r *fakeRemote) Repo(u *model.User, owner, name string) (*model.Repo, error) {
+	return nil, fmt.Errorf("repository %s/%s not found", owner, name)
+}
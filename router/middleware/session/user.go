@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/CiscoCloud/drone/model"
+	"github.com/CiscoCloud/drone/router/middleware/context"
 	"github.com/CiscoCloud/drone/shared/token"
 	"github.com/CiscoCloud/drone/store"
 
@@ -40,12 +41,16 @@ func SetUser() gin.HandlerFunc {
 
 		t, err := token.ParseRequest(c.Request, func(t *token.Token) (string, error) {
 			var err error
-			user, err = store.GetUserLogin(c, t.Text)
+			user, err = store.GetUserLogin(c, t.Text, context.UserRemoteID(c))
 			return user.Hash, err
 		})
 		if err == nil {
 			c.Set("user", user)
 
+			// the remote of the user is used to access
+			// the remote system on behalf of the user.
+			context.SelectRemote(c, user.Remote)
+
 			// if this is a session token (ie not the API token)
 			// this means the user is accessing with a web browser,
 			// so we should implement CSRF protection measures.
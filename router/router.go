@@ -83,10 +83,19 @@ func Load(middleware ...gin.HandlerFunc) http.Handler {
 	{
 		nodes.Use(session.MustAdmin())
 		nodes.GET("", controller.GetNodes)
+		nodes.GET("/stats", controller.GetNodeStats)
 		nodes.POST("", controller.PostNode)
 		nodes.DELETE("/:node", controller.DeleteNode)
 	}
 
+	deliveries := e.Group("/api/deliveries")
+	{
+		deliveries.Use(session.MustAdmin())
+		deliveries.GET("", controller.GetDeliveries)
+		deliveries.GET("/:delivery", controller.GetDelivery)
+		deliveries.POST("/:delivery", controller.PostDelivery)
+	}
+
 	repos := e.Group("/api/repos/:owner/:name")
 	{
 		repos.POST("", controller.PostRepo)
@@ -101,6 +110,8 @@ func Load(middleware ...gin.HandlerFunc) http.Handler {
 			repo.GET("/key", controller.GetRepoKey)
 			repo.GET("/builds", controller.GetBuilds)
 			repo.GET("/builds/:number", controller.GetBuild)
+			repo.GET("/builds/:number/config", controller.GetBuildConfig)
+			repo.GET("/builds/:number/config/diff/:other", controller.GetBuildConfigDiff)
 			repo.GET("/logs/:number/:job", controller.GetBuildLogs)
 
 			// requires authenticated user
@@ -109,8 +120,13 @@ func Load(middleware ...gin.HandlerFunc) http.Handler {
 			// requires push permissions
 			repo.PATCH("", session.MustPush, controller.PatchRepo)
 			repo.DELETE("", session.MustPush, controller.DeleteRepo)
+			repo.POST("/hook", session.MustPush, controller.PostRepoHook)
 
 			repo.POST("/builds/:number", session.MustPush, controller.PostBuild)
+			repo.POST("/preview", session.MustPush, controller.PostHookPreview)
+			repo.GET("/deliveries", session.MustPush, controller.GetDeliveries)
+			repo.GET("/deliveries/:delivery", session.MustPush, controller.GetDelivery)
+			repo.POST("/deliveries/:delivery", session.MustPush, controller.PostDelivery)
 			repo.DELETE("/builds/:number/:job", session.MustPush, controller.DeleteBuild)
 		}
 	}
@@ -122,7 +138,9 @@ func Load(middleware ...gin.HandlerFunc) http.Handler {
 	}
 
 	e.POST("/hook", controller.PostHook)
+	e.POST("/hook/preview", controller.PostHookPayloadPreview)
 	e.POST("/api/hook", controller.PostHook)
+	e.POST("/api/hook/preview", controller.PostHookPayloadPreview)
 
 	stream := e.Group("/api/stream")
 	{
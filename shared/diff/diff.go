@@ -0,0 +1,61 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Lines returns a line by line diff of a and b. Lines that are only
+// in a are prefixed with "-", lines that are only in b are prefixed
+// with "+", and lines that are in both are prefixed with a space.
+func Lines(a, b string) string {
+	x := split(a)
+	y := split(b)
+
+	// lcs[i][j] is the length of the longest common
+	// subsequence of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			switch {
+			case x[i] == y[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			buf.WriteString(" " + x[i] + "\n")
+			i++
+			j++
+		case j == len(y) || (i < len(x) && lcs[i+1][j] >= lcs[i][j+1]):
+			buf.WriteString("-" + x[i] + "\n")
+			i++
+		default:
+			buf.WriteString("+" + y[j] + "\n")
+			j++
+		}
+	}
+	return buf.String()
+}
+
+// helper function to split the text into lines,
+// ignoring the trailing newline.
+func split(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if len(s) == 0 {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,30 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestLines(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Line diff", func() {
+
+		g.It("Should diff changed lines", func() {
+			a := "build:\n  image: golang:1.4\n  commands:\n    - go test\n"
+			b := "build:\n  image: golang:1.5\n  commands:\n    - go test\n"
+			g.Assert(Lines(a, b)).Equal(" build:\n-  image: golang:1.4\n+  image: golang:1.5\n   commands:\n     - go test\n")
+		})
+
+		g.It("Should diff added and removed lines", func() {
+			g.Assert(Lines("a\nb\n", "b\nc\n")).Equal("-a\n b\n+c\n")
+			g.Assert(Lines("", "a\n")).Equal("+a\n")
+			g.Assert(Lines("a\n", "")).Equal("-a\n")
+		})
+
+		g.It("Should not diff identical text", func() {
+			g.Assert(Lines("a\nb", "a\nb\n")).Equal(" a\n b\n")
+		})
+	})
+}
@@ -0,0 +1,70 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/samalba/dockerclient"
+)
+
+// ErrVolumes is returned when the docker client does not
+// support the volumes API.
+var ErrVolumes = errors.New("Docker client does not support volumes")
+
+// Volume is a docker volume.
+type Volume struct {
+	Name   string
+	Driver string
+}
+
+// DanglingVolumes returns the volumes that are not referenced
+// by any container. The volumes API is not implemented by the
+// docker client library, so the request is made directly.
+func DanglingVolumes(client dockerclient.Client) ([]*Volume, error) {
+	params := url.Values{}
+	params.Set("filters", `{"dangling":["true"]}`)
+
+	out := struct {
+		Volumes []*Volume
+	}{}
+	err := volumeRequest(client, "GET", "/volumes?"+params.Encode(), &out)
+	return out.Volumes, err
+}
+
+// RemoveVolume removes the named volume.
+func RemoveVolume(client dockerclient.Client, name string) error {
+	return volumeRequest(client, "DELETE", "/volumes/"+url.QueryEscape(name), nil)
+}
+
+// helper function to make a request to the docker volumes
+// API and unmarshal the JSON response body to out, if not nil.
+func volumeRequest(client dockerclient.Client, method, path string, out interface{}) error {
+	docker, ok := client.(*dockerclient.DockerClient)
+	if !ok {
+		return ErrVolumes
+	}
+
+	req, err := http.NewRequest(method, docker.URL.String()+path, nil)
+	if err != nil {
+		return err
+	}
+	res, err := docker.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("Error %s. %s", res.Status, msg)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -6,6 +6,9 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
 )
 
 type Env map[string]string
@@ -45,6 +48,23 @@ func (env Env) Int(name string, value int) int {
 	return value
 }
 
+// Duration returns the duration value of the environment variable named by
+// the key, parsed using the time.ParseDuration format (ie 30s, 5m). If the
+// variable is not present, or is not a valid duration, the default value is
+// returned.
+func (env Env) Duration(name string, value time.Duration) time.Duration {
+	got, ok := env[name]
+	if !ok {
+		return value
+	}
+	d, err := time.ParseDuration(got)
+	if err != nil {
+		log.Warnf("invalid duration %s=%s, using the default %s. %s", name, got, value, err)
+		return value
+	}
+	return d
+}
+
 // Load reads the environment file and reads variables in "key=value" format.
 // Then it read the system environment variables. It returns the combined
 // results in a key value map.
@@ -0,0 +1,29 @@
+package envconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+func TestEnvconfig(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("Duration", func() {
+
+		g.It("Should parse the duration", func() {
+			env := Env{"TIMEOUT": "30s"}
+			g.Assert(env.Duration("TIMEOUT", time.Minute)).Equal(30 * time.Second)
+		})
+
+		g.It("Should return the default, when not present", func() {
+			env := Env{}
+			g.Assert(env.Duration("TIMEOUT", time.Minute)).Equal(time.Minute)
+		})
+
+		g.It("Should return the default, when not a valid duration", func() {
+			env := Env{"TIMEOUT": "5"}
+			g.Assert(env.Duration("TIMEOUT", time.Minute)).Equal(time.Minute)
+		})
+	})
+}
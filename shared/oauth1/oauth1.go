@@ -0,0 +1,279 @@
+// Package oauth1 supports making OAuth1-authenticated HTTP requests
+// signed with RSA-SHA1, as required by Atlassian application links.
+//
+// Example usage:
+//
+//	var consumer = &oauth1.Consumer{
+//		ConsumerKey:     YOUR_CONSUMER_KEY,
+//		PrivateKey:      YOUR_PRIVATE_KEY,
+//		RequestTokenURL: "https://stash.example.com/plugins/servlet/oauth/request-token",
+//		AuthorizeURL:    "https://stash.example.com/plugins/servlet/oauth/authorize",
+//		AccessTokenURL:  "https://stash.example.com/plugins/servlet/oauth/access-token",
+//		CallbackURL:     "http://you.example.org/handler",
+//	}
+//
+//	// A landing page redirects to the OAuth provider to authorize
+//	// the request token.
+//	func landing(w http.ResponseWriter, r *http.Request) {
+//		token, _ := consumer.RequestToken()
+//		http.Redirect(w, r, consumer.AuthCodeURL(token), http.StatusFound)
+//	}
+//
+//	// The user will be redirected back to this handler, that takes the
+//	// token and verifier query parameters and exchanges them for an
+//	// access token.
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		token, _ := consumer.AccessToken(r.FormValue("oauth_token"), r.FormValue("oauth_verifier"))
+//		c := consumer.Client(token)
+//		c.Get(...)
+//	}
+//
+package oauth1
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Token is an OAuth1 request or access token.
+type Token struct {
+	Token  string
+	Secret string
+}
+
+// Consumer is an OAuth1 consumer that signs requests with its
+// RSA private key.
+type Consumer struct {
+	ConsumerKey     string
+	PrivateKey      *rsa.PrivateKey
+	RequestTokenURL string
+	AuthorizeURL    string
+	AccessTokenURL  string
+	CallbackURL     string
+
+	// Transport is the transport used to send the signed requests.
+	// If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+}
+
+// RequestToken obtains a new, unauthorized request token.
+func (c *Consumer) RequestToken() (*Token, error) {
+	params := map[string]string{"oauth_callback": c.CallbackURL}
+	return c.token(c.RequestTokenURL, "", params)
+}
+
+// AuthCodeURL returns the URL that the user is redirected to in
+// order to authorize the request token.
+func (c *Consumer) AuthCodeURL(token *Token) string {
+	uri, err := url.Parse(c.AuthorizeURL)
+	if err != nil {
+		return c.AuthorizeURL
+	}
+	q := uri.Query()
+	q.Set("oauth_token", token.Token)
+	uri.RawQuery = q.Encode()
+	return uri.String()
+}
+
+// AccessToken exchanges an authorized request token and its
+// verifier for an access token.
+func (c *Consumer) AccessToken(token, verifier string) (*Token, error) {
+	params := map[string]string{"oauth_verifier": verifier}
+	return c.token(c.AccessTokenURL, token, params)
+}
+
+// Client returns an HTTP client that signs requests with the
+// access token.
+func (c *Consumer) Client(token *Token) *http.Client {
+	return &http.Client{Transport: &Transport{Consumer: c, Token: token}}
+}
+
+func (c *Consumer) token(rawurl, token string, params map[string]string) (*Token, error) {
+	req, err := http.NewRequest("POST", rawurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = c.Sign(req, token, params)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("Error requesting token. <%d> %s", res.StatusCode, bytes.TrimSpace(body))
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+	out := &Token{
+		Token:  values.Get("oauth_token"),
+		Secret: values.Get("oauth_token_secret"),
+	}
+	if len(out.Token) == 0 {
+		return nil, errors.New("Error requesting token. No token in response")
+	}
+	return out, nil
+}
+
+func (c *Consumer) transport() http.RoundTripper {
+	if c.Transport != nil {
+		return c.Transport
+	}
+	return http.DefaultTransport
+}
+
+// Sign adds the OAuth1 authorization header to the request, signed
+// with the consumer private key. The request body is not signed,
+// therefore form encoded bodies are not supported.
+func (c *Consumer) Sign(req *http.Request, token string, params map[string]string) error {
+	oauth := map[string]string{
+		"oauth_consumer_key":     c.ConsumerKey,
+		"oauth_nonce":            nonce(),
+		"oauth_signature_method": "RSA-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(time.Now().Unix(), 10),
+		"oauth_version":          "1.0",
+	}
+	if len(token) != 0 {
+		oauth["oauth_token"] = token
+	}
+	for k, v := range params {
+		oauth[k] = v
+	}
+
+	hashed := sha1.Sum([]byte(baseString(req, oauth)))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, c.PrivateKey, crypto.SHA1, hashed[:])
+	if err != nil {
+		return err
+	}
+	oauth["oauth_signature"] = base64.StdEncoding.EncodeToString(sig)
+
+	var keys []string
+	for k := range oauth {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, escape(k), escape(oauth[k])))
+	}
+	req.Header.Set("Authorization", "OAuth "+strings.Join(parts, ", "))
+	return nil
+}
+
+// Transport is an http.RoundTripper that signs requests with
+// the consumer and access token.
+type Transport struct {
+	Consumer *Consumer
+	Token    *Token
+}
+
+// RoundTrip signs and executes a single HTTP transaction.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// clone the request, since a RoundTripper should
+	// not modify the request it is given.
+	clone := new(http.Request)
+	*clone = *req
+	clone.Header = make(http.Header)
+	for k, v := range req.Header {
+		clone.Header[k] = v
+	}
+	err := t.Consumer.Sign(clone, t.Token.Token, nil)
+	if err != nil {
+		return nil, err
+	}
+	return t.Consumer.transport().RoundTrip(clone)
+}
+
+// baseString returns the signature base string of the request,
+// which includes the query parameters and the oauth parameters.
+func baseString(req *http.Request, oauth map[string]string) string {
+	var params []string
+	for k, vs := range req.URL.Query() {
+		for _, v := range vs {
+			params = append(params, escape(k)+"="+escape(v))
+		}
+	}
+	for k, v := range oauth {
+		params = append(params, escape(k)+"="+escape(v))
+	}
+	sort.Strings(params)
+
+	uri := *req.URL
+	uri.RawQuery = ""
+	uri.Fragment = ""
+	uri.Scheme = strings.ToLower(uri.Scheme)
+	uri.Host = strings.ToLower(uri.Host)
+
+	return strings.Join([]string{
+		strings.ToUpper(req.Method),
+		escape(uri.String()),
+		escape(strings.Join(params, "&")),
+	}, "&")
+}
+
+// escape percent encodes the string as defined by RFC 3986,
+// which differs from url.QueryEscape for spaces and tildes.
+func escape(s string) string {
+	var buf bytes.Buffer
+	for _, b := range []byte(s) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '.', b == '_', b == '~':
+			buf.WriteByte(b)
+		default:
+			fmt.Fprintf(&buf, "%%%02X", b)
+		}
+	}
+	return buf.String()
+}
+
+func nonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ParsePrivateKey parses a PEM encoded RSA private key in either
+// the PKCS1 or the PKCS8 format.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("Invalid private key. No PEM data found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsakey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("Invalid private key. Must be an RSA key")
+	}
+	return rsakey, nil
+}
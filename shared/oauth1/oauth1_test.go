@@ -0,0 +1,133 @@
+package oauth1
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestOAuth1(t *testing.T) {
+
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+
+	// verify checks the signature of a signed request with
+	// the public key of the consumer.
+	verify := func(req *http.Request) bool {
+		header := req.Header.Get("Authorization")
+		if !strings.HasPrefix(header, "OAuth ") {
+			return false
+		}
+		oauth := map[string]string{}
+		for _, part := range strings.Split(strings.TrimPrefix(header, "OAuth "), ", ") {
+			kv := strings.SplitN(part, "=", 2)
+			k, _ := url.QueryUnescape(kv[0])
+			v, _ := url.QueryUnescape(strings.Trim(kv[1], `"`))
+			oauth[k] = v
+		}
+		sig, _ := base64.StdEncoding.DecodeString(oauth["oauth_signature"])
+		delete(oauth, "oauth_signature")
+
+		// the base string uses the url of the request as sent
+		// by the client, not as received by the server.
+		clone := *req
+		clone.URL, _ = url.Parse("http://" + req.Host + req.URL.RequestURI())
+		hashed := sha1.Sum([]byte(baseString(&clone, oauth)))
+		return rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA1, hashed[:], sig) == nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/request-token", func(w http.ResponseWriter, r *http.Request) {
+		if !verify(r) || !strings.Contains(r.Header.Get("Authorization"), "oauth_callback=") {
+			w.WriteHeader(401)
+			return
+		}
+		w.Write([]byte("oauth_token=request&oauth_token_secret=secret"))
+	})
+	mux.HandleFunc("/access-token", func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !verify(r) || !strings.Contains(auth, `oauth_token="request"`) || !strings.Contains(auth, `oauth_verifier="verified"`) {
+			w.WriteHeader(401)
+			return
+		}
+		w.Write([]byte("oauth_token=access&oauth_token_secret=secret"))
+	})
+	mux.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		if !verify(r) || !strings.Contains(r.Header.Get("Authorization"), `oauth_token="access"`) {
+			w.WriteHeader(401)
+			return
+		}
+		w.Write([]byte("{}"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	consumer := &Consumer{
+		ConsumerKey:     "drone",
+		PrivateKey:      key,
+		RequestTokenURL: server.URL + "/request-token",
+		AuthorizeURL:    server.URL + "/authorize",
+		AccessTokenURL:  server.URL + "/access-token",
+		CallbackURL:     "http://drone.example.com/authorize",
+	}
+
+	g := goblin.Goblin(t)
+	g.Describe("OAuth1", func() {
+
+		g.It("Should get a request token", func() {
+			token, err := consumer.RequestToken()
+			g.Assert(err == nil).IsTrue()
+			g.Assert(token.Token).Equal("request")
+			g.Assert(token.Secret).Equal("secret")
+		})
+
+		g.It("Should build the authorize url", func() {
+			uri := consumer.AuthCodeURL(&Token{Token: "request"})
+			g.Assert(uri).Equal(server.URL + "/authorize?oauth_token=request")
+		})
+
+		g.It("Should exchange the request token", func() {
+			token, err := consumer.AccessToken("request", "verified")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(token.Token).Equal("access")
+		})
+
+		g.It("Should fail to exchange an invalid token", func() {
+			_, err := consumer.AccessToken("invalid", "verified")
+			g.Assert(err != nil).IsTrue()
+		})
+
+		g.It("Should sign requests with query parameters", func() {
+			client := consumer.Client(&Token{Token: "access"})
+			res, err := client.Get(server.URL + "/api?limit=100&filter=a+b%7E")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(res.StatusCode).Equal(200)
+		})
+
+		g.It("Should escape as defined by RFC 3986", func() {
+			g.Assert(escape("Ladies + Gentlemen")).Equal("Ladies%20%2B%20Gentlemen")
+			g.Assert(escape("a-b.c_d~e")).Equal("a-b.c_d~e")
+			g.Assert(escape("ü")).Equal("%C3%BC")
+		})
+
+		g.It("Should parse the private key", func() {
+			pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+			parsed, err := ParsePrivateKey(pkcs1)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(parsed.N.Cmp(key.N)).Equal(0)
+
+			_, err = ParsePrivateKey([]byte("not a key"))
+			g.Assert(err != nil).IsTrue()
+		})
+	})
+}
@@ -1,36 +1,177 @@
 package server
 
 import (
+	"crypto/tls"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/CiscoCloud/drone/shared/envconfig"
 )
 
+// shutdownGrace is the time to wait for active connections
+// to complete once the listener is closed.
+const shutdownGrace = 5 * time.Second
+
 type Server struct {
-	Addr string
-	Cert string
-	Key  string
+	Addr    string
+	Cert    string
+	Key     string
+	Timeout time.Duration
+
+	srv      *http.Server
+	listener net.Listener
+
+	mu      sync.Mutex
+	closing bool
+	conns   map[net.Conn]http.ConnState
 }
 
 func Load(env envconfig.Env) *Server {
 	return &Server{
-		Addr: env.String("SERVER_ADDR", ":8000"),
-		Cert: env.String("SERVER_CERT", ""),
-		Key:  env.String("SERVER_KEY", ""),
+		Addr:    env.String("SERVER_ADDR", ":8000"),
+		Cert:    env.String("SERVER_CERT", ""),
+		Key:     env.String("SERVER_KEY", ""),
+		Timeout: env.Duration("SERVER_SHUTDOWN_TIMEOUT", 5*time.Minute),
 	}
 }
 
+// Run starts the server and blocks until the process receives an
+// interrupt or termination signal. The listener remains open when Run
+// returns so that event streams stay connected while running builds
+// are drained. Call Shutdown to close the listener.
 func (s *Server) Run(handler http.Handler) {
 	log.Infof("starting server %s", s.Addr)
 
-	if len(s.Cert) != 0 {
-		log.Fatal(
-			http.ListenAndServeTLS(s.Addr, s.Cert, s.Key, handler),
-		)
-	} else {
-		log.Fatal(
-			http.ListenAndServe(s.Addr, handler),
-		)
+	listener, err := s.listen()
+	if err != nil {
+		log.Fatal(err)
+	}
+	s.listener = listener
+	s.conns = map[net.Conn]http.ConnState{}
+	s.srv = &http.Server{Handler: handler, ConnState: s.track}
+	go func() {
+		err := s.srv.Serve(listener)
+		if !s.isClosing() {
+			log.Fatal(err)
+		}
+	}()
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-sigc
+	signal.Stop(sigc)
+
+	log.Infof("received signal %s. shutting down server %s", sig, s.Addr)
+}
+
+// Shutdown closes the listener and waits a short period for open
+// connections to complete before closing them.
+func (s *Server) Shutdown() {
+	if s.srv == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.closing = true
+	s.mu.Unlock()
+
+	s.srv.SetKeepAlivesEnabled(false)
+	err := s.listener.Close()
+	if err != nil {
+		log.Errorf("error shutting down server %s. %s", s.Addr, err)
+	}
+
+	// idle connections are closed immediately, and active
+	// connections once the request completes.
+	s.closeConns(http.StateIdle, http.StateNew)
+
+	deadline := time.Now().Add(shutdownGrace)
+	for s.countConns() != 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	s.closeConns()
+}
+
+// listen creates the tcp listener, using tls if
+// the certificate and key are configured.
+func (s *Server) listen() (net.Listener, error) {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil || len(s.Cert) == 0 {
+		return listener, err
+	}
+	cert, err := tls.LoadX509KeyPair(s.Cert, s.Key)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	config := &tls.Config{
+		NextProtos:   []string{"http/1.1"},
+		Certificates: []tls.Certificate{cert},
+	}
+	return tls.NewListener(listener, config), nil
+}
+
+// track records the state of each connection, closing
+// connections that become idle while shutting down.
+func (s *Server) track(conn net.Conn, state http.ConnState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		delete(s.conns, conn)
+	case http.StateIdle:
+		if s.closing {
+			conn.Close()
+			delete(s.conns, conn)
+			return
+		}
+		s.conns[conn] = state
+	default:
+		s.conns[conn] = state
+	}
+}
+
+// closeConns closes the connections in any of the states,
+// or all connections if no state is given.
+func (s *Server) closeConns(states ...http.ConnState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn, state := range s.conns {
+		if len(states) != 0 && !hasState(states, state) {
+			continue
+		}
+		conn.Close()
+		delete(s.conns, conn)
+	}
+}
+
+func (s *Server) countConns() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns)
+}
+
+func (s *Server) isClosing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closing
+}
+
+// helper function returns true if the
+// state is in the list of states.
+func hasState(states []http.ConnState, state http.ConnState) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
 	}
+	return false
 }
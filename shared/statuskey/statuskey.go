@@ -0,0 +1,20 @@
+package statuskey
+
+import (
+	"crypto/sha1"
+	"fmt"
+
+	"github.com/CiscoCloud/drone/model"
+)
+
+// Key generates the build status key for the repository, or for
+// the job if not nil. Bitbucket limits keys to 40 characters, so
+// the repository is identified by a hash of its full name.
+func Key(r *model.Repo, j *model.Job) string {
+	sum := sha1.Sum([]byte(r.FullName))
+	key := fmt.Sprintf("drone-%x", sum[:4])
+	if j != nil {
+		key = fmt.Sprintf("%s-%d", key, j.Number)
+	}
+	return key
+}
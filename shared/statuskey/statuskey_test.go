@@ -0,0 +1,31 @@
+package statuskey
+
+import (
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/franela/goblin"
+)
+
+func TestKey(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Status key", func() {
+
+		repo := model.Repo{FullName: "octocat/hello-world"}
+		other := model.Repo{FullName: "octocat/other"}
+		job1 := model.Job{Number: 1}
+		job2 := model.Job{Number: 2}
+
+		g.It("Should generate keys per repo and job", func() {
+			g.Assert(Key(&repo, nil) == Key(&other, nil)).IsFalse()
+			g.Assert(Key(&repo, &job1) == Key(&repo, &job2)).IsFalse()
+			g.Assert(Key(&repo, &job1) == Key(&other, &job1)).IsFalse()
+			g.Assert(Key(&repo, &job1) == Key(&repo, &job1)).IsTrue()
+		})
+
+		g.It("Should limit keys to 40 characters", func() {
+			g.Assert(len(Key(&repo, &model.Job{Number: 1000})) <= 40).IsTrue()
+		})
+	})
+}
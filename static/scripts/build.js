@@ -7,6 +7,7 @@ function JobViewModel(repo, build, job, status) {
 	self.stream = function() {
 		$( "#output" ).html("");
 		$("#restart").hide();
+		$("#restart-failed").hide();
 		$("#cancel").show();
 
 		var buf = new Drone.Buffer();
@@ -32,17 +33,43 @@ function JobViewModel(repo, build, job, status) {
 		});
 	};
 
+	// failed returns the status elements of the jobs that
+	// are re-started when re-starting only failed jobs.
+	var failed = function() {
+		return $(".job-list .status.failure, .job-list .status.error, .job-list .status.killed, .job-list .status.skipped");
+	};
+
 	if (status !== "running" && status !== "pending") {
 		Logs(repo, build, job);
 		$("#restart").show();
+		$("#restart-failed").toggle(failed().length !== 0);
 	}
 
 	if (status === "running") {
 		self.stream();
 	}
 
+	$("#restart-failed").click(function() {
+		$("#restart").hide();
+		$("#restart-failed").hide();
+		if (failed().filter(".job-list .active .status").length !== 0) {
+			$("#output").html("");
+		}
+		failed().attr("class", "status pending").text("pending");
+
+		$.ajax({
+			url: "/api/repos/"+repo+"/builds/"+build+"?failed=true",
+			type: "POST",
+			success: function( data ) { },
+			error: function( data ) {
+				console.log(data);
+			}
+		});
+	})
+
 	$("#restart").click(function() {
 		$("#restart").hide();
+		$("#restart-failed").hide();
 		$("#output").html("");
 		$(".status").attr("class", "status pending").text("pending");
 
@@ -81,6 +108,14 @@ function JobViewModel(repo, build, job, status) {
 		var before = self.status;
 		self.status = data.jobs[job-1].status;
 
+		// show the concurrency group the build is waiting on
+		if (data.waiting_on) {
+			$(".waiting em").text(data.waiting_on);
+			$(".waiting").show();
+		} else {
+			$(".waiting").hide();
+		}
+
 		// update the status for each job in the view
 		for (var i=0;i<data.jobs.length;i++) {
 			var job_ = data.jobs[i];
@@ -97,12 +132,21 @@ function JobViewModel(repo, build, job, status) {
 				el.find(".msg-running").show();
 				el.find(".msg-finished").hide();
 				el.find(".msg-exited").hide();
+				el.find(".msg-skipped").hide();
+				break;
+			case "skipped":
+				el.find(".msg-pending").hide();
+				el.find(".msg-running").hide();
+				el.find(".msg-finished").hide();
+				el.find(".msg-exited").hide();
+				el.find(".msg-skipped").show();
 				break;
 			case "pending":
 				el.find(".msg-pending").show();
 				el.find(".msg-running").hide();
 				el.find(".msg-finished").hide();
 				el.find(".msg-exited").hide();
+				el.find(".msg-skipped").hide();
 				break;
 			default:
 				el.find(".msg-finished").find("span").attr("data-livestamp", job_.finished_at);
@@ -112,6 +156,7 @@ function JobViewModel(repo, build, job, status) {
 				el.find(".msg-running").hide();
 				el.find(".msg-finished").show();
 				el.find(".msg-exited").show();
+				el.find(".msg-skipped").hide();
 				break;
 			}
 		}
@@ -128,6 +173,7 @@ function JobViewModel(repo, build, job, status) {
 		// the restart button and hide the tail button.
 		if (after !== "pending" && after !== "running") {
 			$("#restart").show();
+			$("#restart-failed").toggle(failed().length !== 0);
 			$("#cancel").hide();
 			$("#tail").hide();
 		}
@@ -31,6 +31,8 @@ function NodeViewModel() {
 							$("<h3>").text(data.address)
 						).append(
 							$("<p>").attr("class", "card-text").text(data.architecture)
+						).append(
+							nodeInfo(data)
 						).append(
 							$("<div>").attr("class", "btn-group").append(
 								$("<button>").attr("class","btn btn-danger").text("Delete")
@@ -48,6 +50,11 @@ function NodeViewModel() {
 	});
 
 
+	// formats the node memory and disk sizes.
+	$(".node-row .size").each(function() {
+		$(this).text(humanSize($(this).data("size")));
+	});
+
 	$(".node-row").on('click', '.btn-group .btn-danger', function(){
 		// gets the unique identifier for the click row, which is
 		// the user login id.
@@ -72,3 +79,34 @@ function NodeViewModel() {
 		});
 	});
 }
+
+// nodeInfo returns a list of the resources reported
+// by the node docker daemon.
+function nodeInfo(node) {
+	var el = $("<ul>").attr("class", "info list-unstyled card-text");
+	if (!node.updated_at) {
+		return el;
+	}
+	el.append($("<li>").text("Docker "+node.version+" ("+node.storage_driver+")"));
+	el.append($("<li>").text(node.cpus+" CPUs, "+humanSize(node.memory)+" memory"));
+	el.append($("<li>").text(node.containers+" running containers"));
+	if (node.disk_free) {
+		el.append($("<li>").text(humanSize(node.disk_free)+" free disk"));
+	}
+	if (node.collected_at) {
+		el.append($("<li>").text(humanSize(node.reclaimed)+" reclaimed by garbage collection"));
+	}
+	return el;
+}
+
+// humanSize formats the size in bytes as a human
+// readable string, for example 2.1 GB.
+function humanSize(size) {
+	var units = ["B", "kB", "MB", "GB", "TB"];
+	var i = 0;
+	while (size >= 1000 && i < units.length - 1) {
+		size = size / 1000;
+		i++;
+	}
+	return (i === 0 ? size : size.toFixed(1)) + " " + units[i];
+}
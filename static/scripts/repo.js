@@ -113,6 +113,18 @@ function RepoConfigViewModel(repo) {
 		})
 	})
 
+	$("#job_status").change(function(e) {
+		patchRepo(repo, {
+			job_status: e.target.checked,
+		})
+	})
+
+	$("#comment").change(function(e) {
+		patchRepo(repo, {
+			comment: e.target.checked,
+		})
+	})
+
 	$("#trusted").change(function(e) {
 		patchRepo(repo, {
 			trusted:  e.target.checked,
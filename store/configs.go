@@ -0,0 +1,29 @@
+package store
+
+import (
+	"github.com/CiscoCloud/drone/model"
+	"golang.org/x/net/context"
+)
+
+type ConfigStore interface {
+	// Get gets the configuration snapshot of the build.
+	Get(*model.Build) (*model.Config, error)
+
+	// Create creates a new configuration snapshot.
+	Create(*model.Config) error
+
+	// Update updates a configuration snapshot.
+	Update(*model.Config) error
+}
+
+func GetConfig(c context.Context, build *model.Build) (*model.Config, error) {
+	return FromContext(c).Configs().Get(build)
+}
+
+func CreateConfig(c context.Context, config *model.Config) error {
+	return FromContext(c).Configs().Create(config)
+}
+
+func UpdateConfig(c context.Context, config *model.Config) error {
+	return FromContext(c).Configs().Update(config)
+}
@@ -0,0 +1,35 @@
+package datastore
+
+import (
+	"database/sql"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/russross/meddler"
+)
+
+type configstore struct {
+	*sql.DB
+}
+
+func (db *configstore) Get(build *model.Build) (*model.Config, error) {
+	var config = new(model.Config)
+	var err = meddler.QueryRow(db, config, rebind(configQuery), build.ID)
+	return config, err
+}
+
+func (db *configstore) Create(config *model.Config) error {
+	return meddler.Insert(db, configTable, config)
+}
+
+func (db *configstore) Update(config *model.Config) error {
+	return meddler.Update(db, configTable, config)
+}
+
+const configTable = "configs"
+
+const configQuery = `
+SELECT *
+FROM configs
+WHERE config_build_id=?
+LIMIT 1
+`
@@ -0,0 +1,63 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/franela/goblin"
+)
+
+func Test_configstore(t *testing.T) {
+	db := openTest()
+	defer db.Close()
+
+	s := From(db)
+	g := goblin.Goblin(t)
+	g.Describe("Configs", func() {
+
+		// before each test be sure to purge the package
+		// table data from the database.
+		g.BeforeEach(func() {
+			db.Exec("DELETE FROM configs")
+		})
+
+		g.It("Should create a config", func() {
+			config := model.Config{
+				BuildID: 1,
+				Data:    "build:\n  image: golang\n",
+				Secret:  "secret",
+				Axes:    []map[string]string{{"GO_VERSION": "1.5"}},
+			}
+			err := s.Configs().Create(&config)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(config.ID != 0).IsTrue()
+
+			got, err := s.Configs().Get(&model.Build{ID: 1})
+			g.Assert(err == nil).IsTrue()
+			g.Assert(got.Data).Equal(config.Data)
+			g.Assert(got.Secret).Equal(config.Secret)
+			g.Assert(got.Axes).Equal(config.Axes)
+		})
+
+		g.It("Should update a config", func() {
+			config := model.Config{
+				BuildID: 1,
+				Data:    "build:\n  image: golang\n",
+			}
+			err1 := s.Configs().Create(&config)
+			config.Data = "build:\n  image: golang:1.5\n"
+			err2 := s.Configs().Update(&config)
+			g.Assert(err1 == nil).IsTrue()
+			g.Assert(err2 == nil).IsTrue()
+
+			got, err := s.Configs().Get(&model.Build{ID: 1})
+			g.Assert(err == nil).IsTrue()
+			g.Assert(got.Data).Equal(config.Data)
+		})
+
+		g.It("Should error if the config does not exist", func() {
+			_, err := s.Configs().Get(&model.Build{ID: 1})
+			g.Assert(err == nil).IsFalse()
+		})
+	})
+}
@@ -0,0 +1,61 @@
+package datastore
+
+import (
+	"database/sql"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/russross/meddler"
+)
+
+type deliverystore struct {
+	*sql.DB
+}
+
+func (db *deliverystore) Get(id int64) (*model.Delivery, error) {
+	var delivery = new(model.Delivery)
+	var err = meddler.Load(db, deliveryTable, delivery, id)
+	return delivery, err
+}
+
+func (db *deliverystore) GetList() ([]*model.Delivery, error) {
+	var deliveries = []*model.Delivery{}
+	var err = meddler.QueryAll(db, &deliveries, rebind(deliveryListQuery))
+	return deliveries, err
+}
+
+func (db *deliverystore) GetRepoList(repo *model.Repo) ([]*model.Delivery, error) {
+	var deliveries = []*model.Delivery{}
+	var err = meddler.QueryAll(db, &deliveries, rebind(deliveryRepoListQuery), repo.ID)
+	return deliveries, err
+}
+
+func (db *deliverystore) Create(delivery *model.Delivery) error {
+	return meddler.Insert(db, deliveryTable, delivery)
+}
+
+func (db *deliverystore) DeleteBefore(before int64) error {
+	var _, err = db.Exec(rebind(deliveryDeleteStmt), before)
+	return err
+}
+
+const deliveryTable = "deliveries"
+
+const deliveryListQuery = `
+SELECT *
+FROM deliveries
+ORDER BY delivery_id DESC
+LIMIT 100
+`
+
+const deliveryRepoListQuery = `
+SELECT *
+FROM deliveries
+WHERE delivery_repo_id = ?
+ORDER BY delivery_id DESC
+LIMIT 100
+`
+
+const deliveryDeleteStmt = `
+DELETE FROM deliveries
+WHERE delivery_created < ?
+`
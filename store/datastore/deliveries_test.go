@@ -0,0 +1,81 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/CiscoCloud/drone/model"
+	"github.com/franela/goblin"
+)
+
+func Test_deliverystore(t *testing.T) {
+	db := openTest()
+	defer db.Close()
+
+	s := From(db)
+	g := goblin.Goblin(t)
+	g.Describe("Deliveries", func() {
+
+		// before each test be sure to purge the package
+		// table data from the database.
+		g.BeforeEach(func() {
+			db.Exec("DELETE FROM deliveries")
+		})
+
+		g.It("Should create a delivery", func() {
+			delivery := model.Delivery{
+				RepoID:   1,
+				Remote:   "github",
+				Method:   "POST",
+				URL:      "/hook?access_token=%5Bredacted%5D",
+				Header:   map[string][]string{"X-Github-Event": {"push"}},
+				Payload:  `{"ref":"refs/heads/master"}`,
+				Status:   200,
+				Decision: "created build 1",
+				Build:    1,
+				Created:  1398065343,
+			}
+			err := s.Deliveries().Create(&delivery)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(delivery.ID != 0).IsTrue()
+
+			got, err := s.Deliveries().Get(delivery.ID)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(got.RepoID).Equal(delivery.RepoID)
+			g.Assert(got.Header).Equal(delivery.Header)
+			g.Assert(got.Payload).Equal(delivery.Payload)
+			g.Assert(got.Decision).Equal(delivery.Decision)
+		})
+
+		g.It("Should get the most recent deliveries", func() {
+			d1 := model.Delivery{RepoID: 1, Created: 1}
+			d2 := model.Delivery{RepoID: 2, Created: 2}
+			d3 := model.Delivery{RepoID: 1, Created: 3}
+			s.Deliveries().Create(&d1)
+			s.Deliveries().Create(&d2)
+			s.Deliveries().Create(&d3)
+
+			all, err := s.Deliveries().GetList()
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(all)).Equal(3)
+			g.Assert(all[0].ID).Equal(d3.ID)
+
+			repo, err := s.Deliveries().GetRepoList(&model.Repo{ID: 1})
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(repo)).Equal(2)
+			g.Assert(repo[1].ID).Equal(d1.ID)
+		})
+
+		g.It("Should delete expired deliveries", func() {
+			d1 := model.Delivery{RepoID: 1, Created: 1}
+			d2 := model.Delivery{RepoID: 1, Created: 3}
+			s.Deliveries().Create(&d1)
+			s.Deliveries().Create(&d2)
+
+			err := s.Deliveries().DeleteBefore(2)
+			g.Assert(err == nil).IsTrue()
+			all, _ := s.Deliveries().GetList()
+			g.Assert(len(all)).Equal(1)
+			g.Assert(all[0].ID).Equal(d2.ID)
+		})
+	})
+}
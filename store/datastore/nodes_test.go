@@ -41,6 +41,9 @@ func Test_nodestore(t *testing.T) {
 			g.Assert(node.ID != 0).IsTrue()
 
 			node.Addr = "unix:///var/run/docker.sock"
+			node.Version = "1.9.1"
+			node.Memory = 8000000000
+			node.DiskFree = 10500000000
 
 			err1 := s.Nodes().Update(&node)
 			getnode, err2 := s.Nodes().Get(node.ID)
@@ -49,6 +52,9 @@ func Test_nodestore(t *testing.T) {
 			g.Assert(node.ID).Equal(getnode.ID)
 			g.Assert(node.Addr).Equal(getnode.Addr)
 			g.Assert(node.Arch).Equal(getnode.Arch)
+			g.Assert(node.Version).Equal(getnode.Version)
+			g.Assert(node.Memory).Equal(getnode.Memory)
+			g.Assert(node.DiskFree).Equal(getnode.DiskFree)
 		})
 
 		g.It("Should get a node", func() {
@@ -18,13 +18,13 @@ func (db *repostore) Get(id int64) (*model.Repo, error) {
 	return repo, err
 }
 
-func (db *repostore) GetName(name string) (*model.Repo, error) {
+func (db *repostore) GetName(name, remote string) (*model.Repo, error) {
 	var repo = new(model.Repo)
-	var err = meddler.QueryRow(db, repo, rebind(repoNameQuery), name)
+	var err = meddler.QueryRow(db, repo, rebind(repoNameQuery), name, remote)
 	return repo, err
 }
 
-func (db *repostore) GetListOf(listof []*model.RepoLite) ([]*model.Repo, error) {
+func (db *repostore) GetListOf(listof []*model.RepoLite, remote string) ([]*model.Repo, error) {
 	var (
 		repos []*model.Repo
 		args  []interface{}
@@ -36,7 +36,14 @@ func (db *repostore) GetListOf(listof []*model.RepoLite) ([]*model.Repo, error)
 	default:
 		stmt, args = toList(listof)
 	}
-	err := meddler.QueryAll(db, &repos, fmt.Sprintf(repoListOfQuery, stmt), args...)
+	args = append(args, remote)
+	err := meddler.QueryAll(db, &repos, fmt.Sprintf(repoListOfQuery, stmt, len(args)), args...)
+	return repos, err
+}
+
+func (db *repostore) GetPollList() ([]*model.Repo, error) {
+	var repos []*model.Repo
+	var err = meddler.QueryAll(db, &repos, rebind(repoPollQuery), true)
 	return repos, err
 }
 
@@ -59,12 +66,18 @@ func (db *repostore) Delete(repo *model.Repo) error {
 	return err
 }
 
+func (db *repostore) SetDefaultRemote(remote string) error {
+	var _, err = db.Exec(rebind(repoRemoteStmt), remote)
+	return err
+}
+
 const repoTable = "repos"
 
 const repoNameQuery = `
 SELECT *
 FROM repos
 WHERE repo_full_name = ?
+  AND repo_remote = ?
 LIMIT 1;
 `
 
@@ -83,9 +96,17 @@ const repoListOfQuery = `
 SELECT *
 FROM repos
 WHERE repo_full_name IN (%s)
+  AND repo_remote = $%d
 ORDER BY repo_name
 `
 
+const repoPollQuery = `
+SELECT *
+FROM repos
+WHERE repo_poll = ?
+ORDER BY repo_full_name
+`
+
 const repoCountQuery = `
 SELECT COUNT(*) FROM repos
 `
@@ -94,3 +115,10 @@ const repoDeleteStmt = `
 DELETE FROM repos
 WHERE repo_id = ?
 `
+
+const repoRemoteStmt = `
+UPDATE repos
+SET repo_remote = ?
+WHERE repo_remote = ''
+   OR repo_remote IS NULL
+`
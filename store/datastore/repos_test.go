@@ -79,7 +79,7 @@ func Test_repostore(t *testing.T) {
 				Name:     "drone",
 			}
 			s.Repos().Create(&repo)
-			getrepo, err := s.Repos().GetName(repo.FullName)
+			getrepo, err := s.Repos().GetName(repo.FullName, repo.Remote)
 			g.Assert(err == nil).IsTrue()
 			g.Assert(repo.ID).Equal(getrepo.ID)
 			g.Assert(repo.UserID).Equal(getrepo.UserID)
@@ -87,6 +87,38 @@ func Test_repostore(t *testing.T) {
 			g.Assert(repo.Name).Equal(getrepo.Name)
 		})
 
+		g.It("Should Get a Repo by Name and Remote", func() {
+			repo1 := model.Repo{
+				UserID:   1,
+				FullName: "bradrydzewski/drone",
+				Owner:    "bradrydzewski",
+				Name:     "drone",
+				Remote:   "github",
+			}
+			repo2 := model.Repo{
+				UserID:   2,
+				FullName: "bradrydzewski/drone",
+				Owner:    "bradrydzewski",
+				Name:     "drone",
+				Remote:   "gitlab",
+			}
+			err1 := s.Repos().Create(&repo1)
+			err2 := s.Repos().Create(&repo2)
+			g.Assert(err1 == nil).IsTrue()
+			g.Assert(err2 == nil).IsTrue()
+
+			getrepo, err := s.Repos().GetName(repo1.FullName, "gitlab")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(getrepo.ID).Equal(repo2.ID)
+
+			repos, err := s.Repos().GetListOf([]*model.RepoLite{
+				{FullName: "bradrydzewski/drone"},
+			}, "github")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(repos)).Equal(1)
+			g.Assert(repos[0].ID).Equal(repo1.ID)
+		})
+
 		g.It("Should Get a Repo List", func() {
 			repo1 := &model.Repo{
 				UserID:   1,
@@ -113,7 +145,7 @@ func Test_repostore(t *testing.T) {
 			repos, err := s.Repos().GetListOf([]*model.RepoLite{
 				{FullName: "bradrydzewski/drone"},
 				{FullName: "drone/drone"},
-			})
+			}, "")
 			g.Assert(err == nil).IsTrue()
 			g.Assert(len(repos)).Equal(2)
 			g.Assert(repos[0].ID).Equal(repo1.ID)
@@ -141,6 +173,30 @@ func Test_repostore(t *testing.T) {
 			g.Assert(count).Equal(2)
 		})
 
+		g.It("Should Get a Repo Poll List", func() {
+			repo1 := &model.Repo{
+				UserID:   1,
+				Owner:    "bradrydzewski",
+				Name:     "drone",
+				FullName: "bradrydzewski/drone",
+				Poll:     true,
+			}
+			repo2 := &model.Repo{
+				UserID:   2,
+				Owner:    "drone",
+				Name:     "drone",
+				FullName: "drone/drone",
+			}
+			s.Repos().Create(repo1)
+			s.Repos().Create(repo2)
+
+			repos, err := s.Repos().GetPollList()
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(repos)).Equal(1)
+			g.Assert(repos[0].FullName).Equal("bradrydzewski/drone")
+			g.Assert(repos[0].Poll).IsTrue()
+		})
+
 		g.It("Should Delete a Repo", func() {
 			repo := model.Repo{
 				UserID:   1,
@@ -41,6 +41,8 @@ func New(driver, config string) store.Store {
 		&buildstore{db},
 		&jobstore{db},
 		&logstore{db},
+		&configstore{db},
+		&deliverystore{db},
 	)
 }
 
@@ -55,6 +57,8 @@ func From(db *sql.DB) store.Store {
 		&buildstore{db},
 		&jobstore{db},
 		&logstore{db},
+		&configstore{db},
+		&deliverystore{db},
 	)
 }
 
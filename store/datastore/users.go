@@ -18,9 +18,9 @@ func (db *userstore) Get(id int64) (*model.User, error) {
 	return usr, err
 }
 
-func (db *userstore) GetLogin(login string) (*model.User, error) {
+func (db *userstore) GetLogin(login, remote string) (*model.User, error) {
 	var usr = new(model.User)
-	var err = meddler.QueryRow(db, usr, rebind(userLoginQuery), login)
+	var err = meddler.QueryRow(db, usr, rebind(userLoginQuery), login, remote)
 	return usr, err
 }
 
@@ -30,7 +30,7 @@ func (db *userstore) GetList() ([]*model.User, error) {
 	return users, err
 }
 
-func (db *userstore) GetFeed(listof []*model.RepoLite) ([]*model.Feed, error) {
+func (db *userstore) GetFeed(listof []*model.RepoLite, remote string) ([]*model.Feed, error) {
 	var (
 		feed []*model.Feed
 		args []interface{}
@@ -42,7 +42,8 @@ func (db *userstore) GetFeed(listof []*model.RepoLite) ([]*model.Feed, error) {
 	default:
 		stmt, args = toList(listof)
 	}
-	err := meddler.QueryAll(db, &feed, fmt.Sprintf(userFeedQuery, stmt), args...)
+	args = append(args, remote)
+	err := meddler.QueryAll(db, &feed, fmt.Sprintf(userFeedQuery, stmt, len(args)), args...)
 	return feed, err
 }
 
@@ -65,12 +66,18 @@ func (db *userstore) Delete(user *model.User) error {
 	return err
 }
 
+func (db *userstore) SetDefaultRemote(remote string) error {
+	var _, err = db.Exec(rebind(userRemoteStmt), remote)
+	return err
+}
+
 const userTable = "users"
 
 const userLoginQuery = `
 SELECT *
 FROM users
 WHERE user_login=?
+  AND user_remote=?
 LIMIT 1
 `
 
@@ -90,6 +97,13 @@ DELETE FROM users
 WHERE user_id=?
 `
 
+const userRemoteStmt = `
+UPDATE users
+SET user_remote=?
+WHERE user_remote=''
+   OR user_remote IS NULL
+`
+
 const userFeedQuery = `
 SELECT
  repo_owner
@@ -116,6 +130,7 @@ FROM
 ,repos r
 WHERE b.build_repo_id = r.repo_id
   AND r.repo_full_name IN (%s)
+  AND r.repo_remote = $%d
 ORDER BY b.build_id DESC
 LIMIT 25
 `
@@ -81,12 +81,49 @@ func Test_userstore(t *testing.T) {
 				Token: "e42080dddf012c718e476da161d21ad5",
 			}
 			s.Users().Create(&user)
-			getuser, err := s.Users().GetLogin(user.Login)
+			getuser, err := s.Users().GetLogin(user.Login, user.Remote)
 			g.Assert(err == nil).IsTrue()
 			g.Assert(user.ID).Equal(getuser.ID)
 			g.Assert(user.Login).Equal(getuser.Login)
 		})
 
+		g.It("Should Get a User By Login and Remote", func() {
+			user1 := model.User{
+				Login:  "joe",
+				Email:  "foo@bar.com",
+				Remote: "github",
+			}
+			user2 := model.User{
+				Login:  "joe",
+				Email:  "foo@bar.com",
+				Remote: "gitlab",
+			}
+			err1 := s.Users().Create(&user1)
+			err2 := s.Users().Create(&user2)
+			g.Assert(err1 == nil).IsTrue()
+			g.Assert(err2 == nil).IsTrue()
+
+			getuser, err := s.Users().GetLogin("joe", "gitlab")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(getuser.ID).Equal(user2.ID)
+			_, err = s.Users().GetLogin("joe", "bitbucket")
+			g.Assert(err == nil).IsFalse()
+		})
+
+		g.It("Should Set the Default Remote", func() {
+			user1 := model.User{Login: "joe"}
+			user2 := model.User{Login: "jane", Remote: "gitlab"}
+			s.Users().Create(&user1)
+			s.Users().Create(&user2)
+
+			err := s.Users().SetDefaultRemote("github")
+			g.Assert(err == nil).IsTrue()
+			getuser1, _ := s.Users().Get(user1.ID)
+			getuser2, _ := s.Users().Get(user2.ID)
+			g.Assert(getuser1.Remote).Equal("github")
+			g.Assert(getuser2.Remote).Equal("gitlab")
+		})
+
 		g.It("Should Enforce Unique User Login", func() {
 			user1 := model.User{
 				Login: "joe",
@@ -211,7 +248,7 @@ func Test_userstore(t *testing.T) {
 			builds, err := s.Users().GetFeed([]*model.RepoLite{
 				{FullName: "bradrydzewski/drone"},
 				{FullName: "drone/drone"},
-			})
+			}, "")
 			g.Assert(err == nil).IsTrue()
 			g.Assert(len(builds)).Equal(3)
 			g.Assert(builds[0].FullName).Equal(repo2.FullName)
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"github.com/CiscoCloud/drone/model"
+	"golang.org/x/net/context"
+)
+
+type DeliveryStore interface {
+	// Get gets a hook delivery by unique ID.
+	Get(int64) (*model.Delivery, error)
+
+	// GetList gets a list of the most recent hook deliveries.
+	GetList() ([]*model.Delivery, error)
+
+	// GetRepoList gets a list of the most recent hook
+	// deliveries for the repository.
+	GetRepoList(*model.Repo) ([]*model.Delivery, error)
+
+	// Create creates a new hook delivery.
+	Create(*model.Delivery) error
+
+	// DeleteBefore deletes the hook deliveries
+	// received before the unix timestamp.
+	DeleteBefore(int64) error
+}
+
+func GetDelivery(c context.Context, id int64) (*model.Delivery, error) {
+	return FromContext(c).Deliveries().Get(id)
+}
+
+func GetDeliveryList(c context.Context) ([]*model.Delivery, error) {
+	return FromContext(c).Deliveries().GetList()
+}
+
+func GetDeliveryRepoList(c context.Context, repo *model.Repo) ([]*model.Delivery, error) {
+	return FromContext(c).Deliveries().GetRepoList(repo)
+}
+
+func CreateDelivery(c context.Context, delivery *model.Delivery) error {
+	return FromContext(c).Deliveries().Create(delivery)
+}
+
+func DeleteDeliveryBefore(c context.Context, before int64) error {
+	return FromContext(c).Deliveries().DeleteBefore(before)
+}
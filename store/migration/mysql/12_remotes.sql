@@ -0,0 +1,12 @@
+-- +migrate Up
+
+ALTER TABLE users ADD COLUMN user_remote VARCHAR(255);
+ALTER TABLE repos ADD COLUMN repo_remote VARCHAR(255);
+
+UPDATE users SET user_remote = '';
+UPDATE repos SET repo_remote = '';
+
+-- +migrate Down
+
+ALTER TABLE users DROP COLUMN user_remote;
+ALTER TABLE repos DROP COLUMN repo_remote;
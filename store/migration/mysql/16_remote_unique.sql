@@ -0,0 +1,15 @@
+-- +migrate Up
+
+ALTER TABLE users DROP INDEX user_login;
+ALTER TABLE repos DROP INDEX repo_full_name;
+
+ALTER TABLE users ADD UNIQUE INDEX ux_user_login_remote (user_login, user_remote);
+ALTER TABLE repos ADD UNIQUE INDEX ux_repo_full_name_remote (repo_full_name, repo_remote);
+
+-- +migrate Down
+
+ALTER TABLE users DROP INDEX ux_user_login_remote;
+ALTER TABLE repos DROP INDEX ux_repo_full_name_remote;
+
+ALTER TABLE users ADD UNIQUE INDEX user_login (user_login);
+ALTER TABLE repos ADD UNIQUE INDEX repo_full_name (repo_full_name);
@@ -0,0 +1,9 @@
+-- +migrate Up
+
+ALTER TABLE nodes ADD COLUMN node_machine VARCHAR(500);
+
+UPDATE nodes SET node_machine = '';
+
+-- +migrate Down
+
+ALTER TABLE nodes DROP COLUMN node_machine;
@@ -0,0 +1,11 @@
+-- +migrate Up
+
+ALTER TABLE nodes ADD COLUMN node_reclaimed BIGINT;
+ALTER TABLE nodes ADD COLUMN node_collected BIGINT;
+
+UPDATE nodes SET node_reclaimed = 0, node_collected = 0;
+
+-- +migrate Down
+
+ALTER TABLE nodes DROP COLUMN node_reclaimed;
+ALTER TABLE nodes DROP COLUMN node_collected;
@@ -0,0 +1,9 @@
+-- +migrate Up
+
+ALTER TABLE builds ADD COLUMN build_waiting VARCHAR(500);
+
+UPDATE builds SET build_waiting = '';
+
+-- +migrate Down
+
+ALTER TABLE builds DROP COLUMN build_waiting;
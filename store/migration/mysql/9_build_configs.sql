@@ -0,0 +1,15 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS configs (
+ config_id       INTEGER PRIMARY KEY AUTO_INCREMENT
+,config_build_id INTEGER
+,config_data     MEDIUMTEXT
+,config_secret   MEDIUMTEXT
+,config_axes     MEDIUMTEXT
+
+,UNIQUE(config_build_id)
+);
+
+-- +migrate Down
+
+DROP TABLE configs;
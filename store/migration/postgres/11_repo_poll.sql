@@ -0,0 +1,9 @@
+-- +migrate Up
+
+ALTER TABLE repos ADD COLUMN repo_poll BOOLEAN;
+
+UPDATE repos SET repo_poll = false;
+
+-- +migrate Down
+
+ALTER TABLE repos DROP COLUMN repo_poll;
@@ -0,0 +1,9 @@
+-- +migrate Up
+
+ALTER TABLE repos ADD COLUMN repo_hook_secret VARCHAR(500);
+
+UPDATE repos SET repo_hook_secret = '';
+
+-- +migrate Down
+
+ALTER TABLE repos DROP COLUMN repo_hook_secret;
@@ -0,0 +1,12 @@
+-- +migrate Up
+
+ALTER TABLE repos ADD COLUMN repo_comment BOOLEAN;
+ALTER TABLE repos ADD COLUMN repo_comment_log_lines INTEGER;
+
+UPDATE repos SET repo_comment = false;
+UPDATE repos SET repo_comment_log_lines = 0;
+
+-- +migrate Down
+
+ALTER TABLE repos DROP COLUMN repo_comment;
+ALTER TABLE repos DROP COLUMN repo_comment_log_lines;
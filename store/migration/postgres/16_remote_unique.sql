@@ -0,0 +1,15 @@
+-- +migrate Up
+
+ALTER TABLE users DROP CONSTRAINT users_user_login_key;
+ALTER TABLE repos DROP CONSTRAINT repos_repo_full_name_key;
+
+ALTER TABLE users ADD CONSTRAINT users_user_login_remote_key UNIQUE (user_login, user_remote);
+ALTER TABLE repos ADD CONSTRAINT repos_repo_full_name_remote_key UNIQUE (repo_full_name, repo_remote);
+
+-- +migrate Down
+
+ALTER TABLE users DROP CONSTRAINT users_user_login_remote_key;
+ALTER TABLE repos DROP CONSTRAINT repos_repo_full_name_remote_key;
+
+ALTER TABLE users ADD CONSTRAINT users_user_login_key UNIQUE (user_login);
+ALTER TABLE repos ADD CONSTRAINT repos_repo_full_name_key UNIQUE (repo_full_name);
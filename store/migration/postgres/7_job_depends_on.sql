@@ -0,0 +1,11 @@
+-- +migrate Up
+
+ALTER TABLE jobs ADD COLUMN job_name       VARCHAR(255);
+ALTER TABLE jobs ADD COLUMN job_depends_on VARCHAR(2000);
+
+UPDATE jobs SET job_name = '', job_depends_on = '[]';
+
+-- +migrate Down
+
+ALTER TABLE jobs DROP COLUMN job_name;
+ALTER TABLE jobs DROP COLUMN job_depends_on;
@@ -0,0 +1,9 @@
+-- +migrate Up
+
+ALTER TABLE jobs ADD COLUMN job_allow_failure BOOLEAN;
+
+UPDATE jobs SET job_allow_failure = false;
+
+-- +migrate Down
+
+ALTER TABLE jobs DROP COLUMN job_allow_failure;
@@ -0,0 +1,24 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS deliveries (
+ delivery_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,delivery_repo_id  INTEGER
+,delivery_remote   TEXT
+,delivery_method   TEXT
+,delivery_url      TEXT
+,delivery_header   TEXT
+,delivery_payload  TEXT
+,delivery_status   INTEGER
+,delivery_decision TEXT
+,delivery_error    TEXT
+,delivery_build    INTEGER
+,delivery_replay   INTEGER
+,delivery_created  INTEGER
+);
+
+CREATE INDEX ix_delivery_repo ON deliveries (delivery_repo_id);
+CREATE INDEX ix_delivery_created ON deliveries (delivery_created);
+
+-- +migrate Down
+
+DROP TABLE deliveries;
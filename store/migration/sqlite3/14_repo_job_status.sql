@@ -0,0 +1,9 @@
+-- +migrate Up
+
+ALTER TABLE repos ADD COLUMN repo_job_status BOOLEAN;
+
+UPDATE repos SET repo_job_status = 0;
+
+-- +migrate Down
+
+ALTER TABLE repos DROP COLUMN repo_job_status;
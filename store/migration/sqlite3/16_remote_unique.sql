@@ -0,0 +1,261 @@
+-- +migrate Up
+
+CREATE TABLE users_new (
+ user_id     INTEGER PRIMARY KEY AUTOINCREMENT
+,user_login  TEXT
+,user_token  TEXT
+,user_secret TEXT
+,user_expiry INTEGER
+,user_email  TEXT
+,user_avatar TEXT
+,user_active BOOLEAN
+,user_admin  BOOLEAN
+,user_hash   TEXT
+,user_remote TEXT
+
+,UNIQUE(user_login, user_remote)
+);
+
+INSERT INTO users_new (
+ user_id
+,user_login
+,user_token
+,user_secret
+,user_expiry
+,user_email
+,user_avatar
+,user_active
+,user_admin
+,user_hash
+,user_remote
+) SELECT
+ user_id
+,user_login
+,user_token
+,user_secret
+,user_expiry
+,user_email
+,user_avatar
+,user_active
+,user_admin
+,user_hash
+,user_remote
+FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+
+CREATE TABLE repos_new (
+ repo_id                INTEGER PRIMARY KEY AUTOINCREMENT
+,repo_user_id           INTEGER
+,repo_owner             TEXT
+,repo_name              TEXT
+,repo_full_name         TEXT
+,repo_avatar            TEXT
+,repo_link              TEXT
+,repo_clone             TEXT
+,repo_branch            TEXT
+,repo_timeout           INTEGER
+,repo_private           BOOLEAN
+,repo_trusted           BOOLEAN
+,repo_allow_pr          BOOLEAN
+,repo_allow_push        BOOLEAN
+,repo_allow_deploys     BOOLEAN
+,repo_allow_tags        BOOLEAN
+,repo_hash              TEXT
+,repo_scm               TEXT
+,repo_poll              BOOLEAN
+,repo_remote            TEXT
+,repo_hook_secret       TEXT
+,repo_job_status        BOOLEAN
+,repo_comment           BOOLEAN
+,repo_comment_log_lines INTEGER
+
+,UNIQUE(repo_full_name, repo_remote)
+);
+
+INSERT INTO repos_new (
+ repo_id
+,repo_user_id
+,repo_owner
+,repo_name
+,repo_full_name
+,repo_avatar
+,repo_link
+,repo_clone
+,repo_branch
+,repo_timeout
+,repo_private
+,repo_trusted
+,repo_allow_pr
+,repo_allow_push
+,repo_allow_deploys
+,repo_allow_tags
+,repo_hash
+,repo_scm
+,repo_poll
+,repo_remote
+,repo_hook_secret
+,repo_job_status
+,repo_comment
+,repo_comment_log_lines
+) SELECT
+ repo_id
+,repo_user_id
+,repo_owner
+,repo_name
+,repo_full_name
+,repo_avatar
+,repo_link
+,repo_clone
+,repo_branch
+,repo_timeout
+,repo_private
+,repo_trusted
+,repo_allow_pr
+,repo_allow_push
+,repo_allow_deploys
+,repo_allow_tags
+,repo_hash
+,repo_scm
+,repo_poll
+,repo_remote
+,repo_hook_secret
+,repo_job_status
+,repo_comment
+,repo_comment_log_lines
+FROM repos;
+
+DROP TABLE repos;
+ALTER TABLE repos_new RENAME TO repos;
+
+-- +migrate Down
+
+CREATE TABLE users_new (
+ user_id     INTEGER PRIMARY KEY AUTOINCREMENT
+,user_login  TEXT
+,user_token  TEXT
+,user_secret TEXT
+,user_expiry INTEGER
+,user_email  TEXT
+,user_avatar TEXT
+,user_active BOOLEAN
+,user_admin  BOOLEAN
+,user_hash   TEXT
+,user_remote TEXT
+
+,UNIQUE(user_login)
+);
+
+INSERT INTO users_new (
+ user_id
+,user_login
+,user_token
+,user_secret
+,user_expiry
+,user_email
+,user_avatar
+,user_active
+,user_admin
+,user_hash
+,user_remote
+) SELECT
+ user_id
+,user_login
+,user_token
+,user_secret
+,user_expiry
+,user_email
+,user_avatar
+,user_active
+,user_admin
+,user_hash
+,user_remote
+FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+
+CREATE TABLE repos_new (
+ repo_id                INTEGER PRIMARY KEY AUTOINCREMENT
+,repo_user_id           INTEGER
+,repo_owner             TEXT
+,repo_name              TEXT
+,repo_full_name         TEXT
+,repo_avatar            TEXT
+,repo_link              TEXT
+,repo_clone             TEXT
+,repo_branch            TEXT
+,repo_timeout           INTEGER
+,repo_private           BOOLEAN
+,repo_trusted           BOOLEAN
+,repo_allow_pr          BOOLEAN
+,repo_allow_push        BOOLEAN
+,repo_allow_deploys     BOOLEAN
+,repo_allow_tags        BOOLEAN
+,repo_hash              TEXT
+,repo_scm               TEXT
+,repo_poll              BOOLEAN
+,repo_remote            TEXT
+,repo_hook_secret       TEXT
+,repo_job_status        BOOLEAN
+,repo_comment           BOOLEAN
+,repo_comment_log_lines INTEGER
+
+,UNIQUE(repo_full_name)
+);
+
+INSERT INTO repos_new (
+ repo_id
+,repo_user_id
+,repo_owner
+,repo_name
+,repo_full_name
+,repo_avatar
+,repo_link
+,repo_clone
+,repo_branch
+,repo_timeout
+,repo_private
+,repo_trusted
+,repo_allow_pr
+,repo_allow_push
+,repo_allow_deploys
+,repo_allow_tags
+,repo_hash
+,repo_scm
+,repo_poll
+,repo_remote
+,repo_hook_secret
+,repo_job_status
+,repo_comment
+,repo_comment_log_lines
+) SELECT
+ repo_id
+,repo_user_id
+,repo_owner
+,repo_name
+,repo_full_name
+,repo_avatar
+,repo_link
+,repo_clone
+,repo_branch
+,repo_timeout
+,repo_private
+,repo_trusted
+,repo_allow_pr
+,repo_allow_push
+,repo_allow_deploys
+,repo_allow_tags
+,repo_hash
+,repo_scm
+,repo_poll
+,repo_remote
+,repo_hook_secret
+,repo_job_status
+,repo_comment
+,repo_comment_log_lines
+FROM repos;
+
+DROP TABLE repos;
+ALTER TABLE repos_new RENAME TO repos;
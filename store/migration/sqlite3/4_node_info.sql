@@ -0,0 +1,21 @@
+-- +migrate Up
+
+ALTER TABLE nodes ADD COLUMN node_version    TEXT;
+ALTER TABLE nodes ADD COLUMN node_driver     TEXT;
+ALTER TABLE nodes ADD COLUMN node_cpus       INTEGER;
+ALTER TABLE nodes ADD COLUMN node_memory     INTEGER;
+ALTER TABLE nodes ADD COLUMN node_containers INTEGER;
+ALTER TABLE nodes ADD COLUMN node_disk_free  INTEGER;
+ALTER TABLE nodes ADD COLUMN node_updated    INTEGER;
+
+UPDATE nodes SET node_version = '', node_driver = '', node_cpus = 0, node_memory = 0, node_containers = 0, node_disk_free = 0, node_updated = 0;
+
+-- +migrate Down
+
+ALTER TABLE nodes DROP COLUMN node_version;
+ALTER TABLE nodes DROP COLUMN node_driver;
+ALTER TABLE nodes DROP COLUMN node_cpus;
+ALTER TABLE nodes DROP COLUMN node_memory;
+ALTER TABLE nodes DROP COLUMN node_containers;
+ALTER TABLE nodes DROP COLUMN node_disk_free;
+ALTER TABLE nodes DROP COLUMN node_updated;
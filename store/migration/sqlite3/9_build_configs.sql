@@ -0,0 +1,15 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS configs (
+ config_id       INTEGER PRIMARY KEY AUTOINCREMENT
+,config_build_id INTEGER
+,config_data     TEXT
+,config_secret   TEXT
+,config_axes     TEXT
+
+,UNIQUE(config_build_id)
+);
+
+-- +migrate Down
+
+DROP TABLE configs;
@@ -9,11 +9,14 @@ type RepoStore interface {
 	// Get gets a repo by unique ID.
 	Get(int64) (*model.Repo, error)
 
-	// GetLogin gets a repo by its full name.
-	GetName(string) (*model.Repo, error)
+	// GetName gets a repo by its full name, unique for the remote.
+	GetName(string, string) (*model.Repo, error)
 
-	// GetListOf gets the list of enumerated repos in the system.
-	GetListOf([]*model.RepoLite) ([]*model.Repo, error)
+	// GetListOf gets the list of enumerated repos of the remote.
+	GetListOf([]*model.RepoLite, string) ([]*model.Repo, error)
+
+	// GetPollList gets the list of repos that are polled for changes.
+	GetPollList() ([]*model.Repo, error)
 
 	// Count gets a count of all repos in the system.
 	Count() (int, error)
@@ -26,22 +29,30 @@ type RepoStore interface {
 
 	// Delete deletes a user repository.
 	Delete(*model.Repo) error
+
+	// SetDefaultRemote sets the remote of the repos
+	// activated without a remote.
+	SetDefaultRemote(string) error
 }
 
 func GetRepo(c context.Context, id int64) (*model.Repo, error) {
 	return FromContext(c).Repos().Get(id)
 }
 
-func GetRepoName(c context.Context, name string) (*model.Repo, error) {
-	return FromContext(c).Repos().GetName(name)
+func GetRepoName(c context.Context, name, remote string) (*model.Repo, error) {
+	return FromContext(c).Repos().GetName(name, remote)
+}
+
+func GetRepoOwnerName(c context.Context, owner, name, remote string) (*model.Repo, error) {
+	return FromContext(c).Repos().GetName(owner+"/"+name, remote)
 }
 
-func GetRepoOwnerName(c context.Context, owner, name string) (*model.Repo, error) {
-	return FromContext(c).Repos().GetName(owner + "/" + name)
+func GetRepoListOf(c context.Context, listof []*model.RepoLite, remote string) ([]*model.Repo, error) {
+	return FromContext(c).Repos().GetListOf(listof, remote)
 }
 
-func GetRepoListOf(c context.Context, listof []*model.RepoLite) ([]*model.Repo, error) {
-	return FromContext(c).Repos().GetListOf(listof)
+func GetRepoPollList(c context.Context) ([]*model.Repo, error) {
+	return FromContext(c).Repos().GetPollList()
 }
 
 func CountRepos(c context.Context) (int, error) {
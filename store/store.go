@@ -8,27 +8,33 @@ type Store interface {
 	Builds() BuildStore
 	Jobs() JobStore
 	Logs() LogStore
+	Configs() ConfigStore
+	Deliveries() DeliveryStore
 }
 
 type store struct {
-	name   string
-	nodes  NodeStore
-	users  UserStore
-	repos  RepoStore
-	keys   KeyStore
-	builds BuildStore
-	jobs   JobStore
-	logs   LogStore
+	name       string
+	nodes      NodeStore
+	users      UserStore
+	repos      RepoStore
+	keys       KeyStore
+	builds     BuildStore
+	jobs       JobStore
+	logs       LogStore
+	configs    ConfigStore
+	deliveries DeliveryStore
 }
 
-func (s *store) Nodes() NodeStore   { return s.nodes }
-func (s *store) Users() UserStore   { return s.users }
-func (s *store) Repos() RepoStore   { return s.repos }
-func (s *store) Keys() KeyStore     { return s.keys }
-func (s *store) Builds() BuildStore { return s.builds }
-func (s *store) Jobs() JobStore     { return s.jobs }
-func (s *store) Logs() LogStore     { return s.logs }
-func (s *store) String() string     { return s.name }
+func (s *store) Nodes() NodeStore          { return s.nodes }
+func (s *store) Users() UserStore          { return s.users }
+func (s *store) Repos() RepoStore          { return s.repos }
+func (s *store) Keys() KeyStore            { return s.keys }
+func (s *store) Builds() BuildStore        { return s.builds }
+func (s *store) Jobs() JobStore            { return s.jobs }
+func (s *store) Logs() LogStore            { return s.logs }
+func (s *store) Configs() ConfigStore      { return s.configs }
+func (s *store) Deliveries() DeliveryStore { return s.deliveries }
+func (s *store) String() string            { return s.name }
 
 func New(
 	name string,
@@ -39,6 +45,8 @@ func New(
 	builds BuildStore,
 	jobs JobStore,
 	logs LogStore,
+	configs ConfigStore,
+	deliveries DeliveryStore,
 ) Store {
 	return &store{
 		name,
@@ -49,5 +57,7 @@ func New(
 		builds,
 		jobs,
 		logs,
+		configs,
+		deliveries,
 	}
 }
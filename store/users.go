@@ -9,14 +9,14 @@ type UserStore interface {
 	// Get gets a user by unique ID.
 	Get(int64) (*model.User, error)
 
-	// GetLogin gets a user by unique Login name.
-	GetLogin(string) (*model.User, error)
+	// GetLogin gets a user by Login name, unique for the remote.
+	GetLogin(string, string) (*model.User, error)
 
 	// GetList gets a list of all users in the system.
 	GetList() ([]*model.User, error)
 
-	// GetFeed gets a user activity feed.
-	GetFeed([]*model.RepoLite) ([]*model.Feed, error)
+	// GetFeed gets a user activity feed of the repos of the remote.
+	GetFeed([]*model.RepoLite, string) ([]*model.Feed, error)
 
 	// Count gets a count of all users in the system.
 	Count() (int, error)
@@ -29,22 +29,26 @@ type UserStore interface {
 
 	// Delete deletes a user account.
 	Delete(*model.User) error
+
+	// SetDefaultRemote sets the remote of the users
+	// registered without a remote.
+	SetDefaultRemote(string) error
 }
 
 func GetUser(c context.Context, id int64) (*model.User, error) {
 	return FromContext(c).Users().Get(id)
 }
 
-func GetUserLogin(c context.Context, login string) (*model.User, error) {
-	return FromContext(c).Users().GetLogin(login)
+func GetUserLogin(c context.Context, login, remote string) (*model.User, error) {
+	return FromContext(c).Users().GetLogin(login, remote)
 }
 
 func GetUserList(c context.Context) ([]*model.User, error) {
 	return FromContext(c).Users().GetList()
 }
 
-func GetUserFeed(c context.Context, listof []*model.RepoLite) ([]*model.Feed, error) {
-	return FromContext(c).Users().GetFeed(listof)
+func GetUserFeed(c context.Context, listof []*model.RepoLite, remote string) ([]*model.Feed, error) {
+	return FromContext(c).Users().GetFeed(listof, remote)
 }
 
 func CountUsers(c context.Context) (int, error) {
@@ -0,0 +1,101 @@
+package yaml
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Job is a named job declared in the jobs section of the yaml
+// file. A job starts once the jobs it depends on have completed
+// successfully.
+type Job struct {
+	Name        string
+	DependsOn   []string          `yaml:"depends_on"`
+	Environment map[string]string `yaml:"environment"`
+}
+
+// ParseJobs parses the jobs section of the yaml file and returns
+// the list of jobs in the order they are declared. It returns an
+// error if a job depends on an unknown job or the dependencies
+// contain a cycle.
+func ParseJobs(raw string) ([]*Job, error) {
+	data := struct {
+		Jobs yaml.MapSlice
+	}{}
+	err := yaml.Unmarshal([]byte(raw), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*Job
+	for _, item := range data.Jobs {
+		out, err := yaml.Marshal(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		job := &Job{}
+		err = yaml.Unmarshal(out, job)
+		if err != nil {
+			return nil, err
+		}
+		job.Name = fmt.Sprint(item.Key)
+		jobs = append(jobs, job)
+	}
+
+	err = checkJobs(jobs)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// helper function to verify the job dependencies exist
+// and do not contain a cycle.
+func checkJobs(jobs []*Job) error {
+	index := map[string]*Job{}
+	for _, job := range jobs {
+		if _, ok := index[job.Name]; ok {
+			return fmt.Errorf("Duplicate job %s", job.Name)
+		}
+		index[job.Name] = job
+	}
+	for _, job := range jobs {
+		for _, dep := range job.DependsOn {
+			if _, ok := index[dep]; !ok {
+				return fmt.Errorf("Job %s depends on unknown job %s", job.Name, dep)
+			}
+		}
+	}
+
+	// depth first search for cycles, where visiting
+	// records the jobs on the current path.
+	visiting := map[string]bool{}
+	visited := map[string]bool{}
+	var visit func(*Job) error
+	visit = func(job *Job) error {
+		if visited[job.Name] {
+			return nil
+		}
+		if visiting[job.Name] {
+			return fmt.Errorf("Job %s has a circular dependency", job.Name)
+		}
+		visiting[job.Name] = true
+		for _, dep := range job.DependsOn {
+			err := visit(index[dep])
+			if err != nil {
+				return err
+			}
+		}
+		visiting[job.Name] = false
+		visited[job.Name] = true
+		return nil
+	}
+	for _, job := range jobs {
+		err := visit(job)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
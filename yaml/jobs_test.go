@@ -0,0 +1,62 @@
+package yaml
+
+import (
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestJobs(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Parse jobs", func() {
+
+		g.It("Should parse jobs in order", func() {
+			jobs, err := ParseJobs(fakeJobs)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(jobs)).Equal(3)
+			g.Assert(jobs[0].Name).Equal("build")
+			g.Assert(jobs[1].Name).Equal("test")
+			g.Assert(jobs[1].DependsOn).Equal([]string{"build"})
+			g.Assert(jobs[1].Environment["SUITE"]).Equal("unit")
+			g.Assert(jobs[2].Name).Equal("deploy")
+		})
+
+		g.It("Should return nil if no jobs", func() {
+			jobs, err := ParseJobs("debug: true\n")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(jobs == nil).IsTrue()
+		})
+
+		g.It("Should error on unknown dependencies", func() {
+			_, err := ParseJobs("jobs:\n  test:\n    depends_on: [ build ]\n")
+			g.Assert(err == nil).IsFalse()
+		})
+
+		g.It("Should error on circular dependencies", func() {
+			_, err := ParseJobs(fakeCycle)
+			g.Assert(err == nil).IsFalse()
+		})
+	})
+}
+
+var fakeJobs = `
+jobs:
+  build:
+  test:
+    depends_on: [ build ]
+    environment:
+      SUITE: unit
+  deploy:
+    depends_on: [ build, test ]
+`
+
+var fakeCycle = `
+jobs:
+  build:
+    depends_on: [ deploy ]
+  test:
+    depends_on: [ build ]
+  deploy:
+    depends_on: [ test ]
+`
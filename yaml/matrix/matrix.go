@@ -1,15 +1,39 @@
 package matrix
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/CiscoCloud/drone/shared/envconfig"
 	"gopkg.in/yaml.v2"
 )
 
-const (
-	limitTags = 10
-	limitAxis = 25
-)
+// limitPerm is the upper limit of permutations that are
+// calculated before excluded permutations are removed.
+const limitPerm = 10000
+
+// Limits represents the maximum size of the build matrix.
+type Limits struct {
+	// Tags is the maximum number of matrix variables.
+	Tags int
+
+	// Axis is the maximum number of permutations, including
+	// the included and excluding the excluded permutations.
+	Axis int
+}
+
+// DefaultLimits is the default maximum size of the build matrix.
+var DefaultLimits = Limits{Tags: 10, Axis: 25}
+
+// Load returns the maximum size of the build matrix
+// configured in the environment.
+func Load(env envconfig.Env) Limits {
+	return Limits{
+		Tags: env.Int("MATRIX_MAX_VARIABLES", DefaultLimits.Tags),
+		Axis: env.Int("MATRIX_MAX_PERMUTATIONS", DefaultLimits.Axis),
+	}
+}
 
 // Matrix represents the build matrix.
 type Matrix map[string][]string
@@ -28,40 +52,88 @@ func (a Axis) String() string {
 	return strings.Join(envs, " ")
 }
 
-// Parse parses the Matrix section of the yaml file and
-// returns a list of axis.
-func Parse(raw string) ([]Axis, error) {
-	matrix, err := parseMatrix(raw)
+// Match returns true if the axis matches any of the partial axes
+// in the list. A partial axis matches if every entry is equal to
+// the entry of the same name in the axis.
+func (a Axis) Match(list []Axis) bool {
+	for _, partial := range list {
+		match := true
+		for k, v := range partial {
+			if a[k] != v {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses the Matrix section of the yaml file and returns a
+// list of axis. It returns an error if the matrix exceeds the limits.
+func Parse(raw string, limits Limits) ([]Axis, error) {
+	s, err := parseSection(raw)
 	if err != nil {
 		return nil, err
 	}
 
 	// if not a matrix build return an array
 	// with just the single axis.
-	if len(matrix) == 0 {
+	if len(s.matrix) == 0 && len(s.include) == 0 {
 		return nil, nil
 	}
 
-	return Calc(matrix), nil
+	if len(s.matrix) > limits.Tags {
+		return nil, fmt.Errorf("Matrix has %d variables, exceeding the limit of %d", len(s.matrix), limits.Tags)
+	}
+
+	axes, err := Calc(s.matrix, s.exclude, limits.Axis)
+	if err != nil {
+		return nil, err
+	}
+	axes = append(axes, s.include...)
+	if len(axes) > limits.Axis {
+		return nil, fmt.Errorf("Matrix has %d permutations, exceeding the limit of %d", len(axes), limits.Axis)
+	}
+	return axes, nil
+}
+
+// ParseAllowFailures parses the allow_failures entry of the Matrix
+// section of the yaml file and returns a list of partial axes. Jobs
+// for matching axes do not affect the overall build status.
+func ParseAllowFailures(raw string) ([]Axis, error) {
+	s, err := parseSection(raw)
+	if err != nil {
+		return nil, err
+	}
+	return s.allowFailures, nil
 }
 
-// Calc calculates the permutations for th build matrix.
+// Calc calculates the permutations for the build matrix, skipping
+// the permutations that match the excluded axes.
 //
-// Note that this method will cap the number of permutations
-// to 25 to prevent an overly expensive calculation.
-func Calc(matrix Matrix) []Axis {
+// Note that this method returns an error if the number of
+// permutations exceeds the limit to prevent an overly
+// expensive calculation.
+func Calc(matrix Matrix, exclude []Axis, limit int) ([]Axis, error) {
 	// calculate number of permutations and
 	// extract the list of tags
 	// (ie go_version, redis_version, etc)
-	var perm int
+	perm := 1
 	var tags []string
 	for k, v := range matrix {
 		perm *= len(v)
-		if perm == 0 {
-			perm = len(v)
+		if perm > limitPerm {
+			return nil, fmt.Errorf("Matrix has more than %d permutations", limitPerm)
 		}
 		tags = append(tags, k)
 	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	sort.Strings(tags)
 
 	// structure to hold the transformed
 	// result set
@@ -70,40 +142,88 @@ func Calc(matrix Matrix) []Axis {
 	// for each axis calculate the uniqe
 	// set of values that should be used.
 	for p := 0; p < perm; p++ {
-		axis := map[string]string{}
+		axis := Axis{}
 		decr := perm
-		for i, tag := range tags {
+		for _, tag := range tags {
 			elems := matrix[tag]
 			decr = decr / len(elems)
 			elem := p / decr % len(elems)
 			axis[tag] = elems[elem]
-
-			// enforce a maximum number of tags
-			// in the build matrix.
-			if i > limitTags {
-				break
-			}
 		}
-
-		// append to the list of axis.
-		axisList = append(axisList, axis)
+		if axis.Match(exclude) {
+			continue
+		}
 
 		// enforce a maximum number of axis
 		// that should be calculated.
-		if p > limitAxis {
-			break
+		if len(axisList) == limit {
+			return nil, fmt.Errorf("Matrix has more than %d permutations", limit)
 		}
+		axisList = append(axisList, axis)
 	}
 
-	return axisList
+	return axisList, nil
+}
+
+// section represents the Matrix section of the yaml file,
+// which contains the matrix and the special entries.
+type section struct {
+	matrix        Matrix
+	allowFailures []Axis
+	include       []Axis
+	exclude       []Axis
+}
+
+// entry represents a single entry in the Matrix section of the
+// yaml file, which is either a list of values or a list of axes.
+type entry struct {
+	values []string
+	axes   []Axis
 }
 
-// helper function to parse the Matrix data from
-// the raw yaml file.
-func parseMatrix(raw string) (Matrix, error) {
+func (e *entry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	err := unmarshal(&e.values)
+	if err == nil {
+		return nil
+	}
+	e.values = nil
+	return unmarshal(&e.axes)
+}
+
+// helper function to parse the Matrix section from
+// the raw yaml file, separating the special entries
+// from the matrix.
+func parseSection(raw string) (*section, error) {
 	data := struct {
-		Matrix map[string][]string
+		Matrix map[string]*entry
 	}{}
 	err := yaml.Unmarshal([]byte(raw), &data)
-	return data.Matrix, err
+	if err != nil {
+		return nil, err
+	}
+
+	s := &section{matrix: Matrix{}}
+	for k, e := range data.Matrix {
+		switch k {
+		case "allow_failures", "include", "exclude":
+			if e == nil || len(e.values) != 0 {
+				return nil, fmt.Errorf("Matrix %s must be a list of axes", k)
+			}
+		default:
+			if e == nil || len(e.values) == 0 {
+				return nil, fmt.Errorf("Matrix %s must be a list of values", k)
+			}
+			s.matrix[k] = e.values
+		}
+
+		switch k {
+		case "allow_failures":
+			s.allowFailures = e.axes
+		case "include":
+			s.include = e.axes
+		case "exclude":
+			s.exclude = e.axes
+		}
+	}
+	return s, nil
 }
@@ -11,7 +11,7 @@ func Test_Matrix(t *testing.T) {
 	g := goblin.Goblin(t)
 	g.Describe("Calculate matrix", func() {
 
-		axis, _ := Parse(fakeMatrix)
+		axis, _ := Parse(fakeMatrix, DefaultLimits)
 
 		g.It("Should calculate permutations", func() {
 			g.Assert(len(axis)).Equal(24)
@@ -26,10 +26,90 @@ func Test_Matrix(t *testing.T) {
 		})
 
 		g.It("Should return nil if no matrix", func() {
-			axis, err := Parse("")
+			axis, err := Parse("", DefaultLimits)
 			g.Assert(err == nil).IsTrue()
 			g.Assert(axis == nil).IsTrue()
 		})
+
+		g.It("Should ignore allowed failures", func() {
+			axis, err := Parse(fakeMatrix+fakeAllowFailures, DefaultLimits)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(axis)).Equal(24)
+		})
+	})
+
+	g.Describe("Matrix include and exclude", func() {
+
+		g.It("Should exclude permutations", func() {
+			axis, err := Parse(fakeMatrix+fakeExclude, DefaultLimits)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(axis)).Equal(20)
+			for _, perm := range axis {
+				g.Assert(perm["go_version"] == "go1" && perm["django_version"] == "1.7").IsFalse()
+			}
+		})
+
+		g.It("Should include permutations", func() {
+			axis, err := Parse(fakeMatrix+fakeInclude, DefaultLimits)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(axis)).Equal(25)
+			g.Assert(axis[24]["go_version"]).Equal("tip")
+		})
+
+		g.It("Should include permutations without a matrix", func() {
+			axis, err := Parse("matrix:"+fakeInclude, DefaultLimits)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(axis)).Equal(1)
+		})
+	})
+
+	g.Describe("Matrix limits", func() {
+
+		g.It("Should error when exceeding the permutation limit", func() {
+			_, err := Parse(fakeMatrix, Limits{Tags: 10, Axis: 23})
+			g.Assert(err == nil).IsFalse()
+			_, err = Parse(fakeMatrix+fakeInclude, Limits{Tags: 10, Axis: 24})
+			g.Assert(err == nil).IsFalse()
+		})
+
+		g.It("Should error when exceeding the variable limit", func() {
+			_, err := Parse(fakeMatrix, Limits{Tags: 3, Axis: 25})
+			g.Assert(err == nil).IsFalse()
+		})
+
+		g.It("Should apply the limit after exclusions", func() {
+			axis, err := Parse(fakeMatrix+fakeExclude, Limits{Tags: 10, Axis: 20})
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(axis)).Equal(20)
+		})
+
+		g.It("Should error on empty variables", func() {
+			_, err := Parse("matrix:\n  go_version: []\n", DefaultLimits)
+			g.Assert(err == nil).IsFalse()
+		})
+	})
+
+	g.Describe("Allowed failures", func() {
+
+		g.It("Should parse allowed failures", func() {
+			allowed, err := ParseAllowFailures(fakeMatrix + fakeAllowFailures)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(allowed)).Equal(2)
+			g.Assert(allowed[1]["django_version"]).Equal("1.7.2")
+		})
+
+		g.It("Should match partial axes", func() {
+			allowed, _ := ParseAllowFailures(fakeMatrix + fakeAllowFailures)
+			g.Assert(Axis{"go_version": "go1", "redis_version": "2.8"}.Match(allowed)).IsTrue()
+			g.Assert(Axis{"go_version": "go1", "redis_version": "2.6"}.Match(allowed)).IsFalse()
+			g.Assert(Axis{"go_version": "go1.2", "django_version": "1.7.2"}.Match(allowed)).IsTrue()
+			g.Assert(Axis{"go_version": "go1"}.Match(nil)).IsFalse()
+		})
+
+		g.It("Should error on invalid allowed failures", func() {
+			_, err := ParseAllowFailures("matrix:\n  allow_failures:\n    - go1\n")
+			g.Assert(err == nil).IsFalse()
+		})
 	})
 }
 
@@ -49,3 +129,22 @@ matrix:
     - 2.6
     - 2.8
 `
+
+var fakeAllowFailures = `
+  allow_failures:
+    - go_version: go1
+      redis_version: 2.8
+    - django_version: 1.7.2
+`
+
+var fakeExclude = `
+  exclude:
+    - go_version: go1
+      django_version: 1.7
+`
+
+var fakeInclude = `
+  include:
+    - go_version: tip
+      redis_version: 3.0
+`
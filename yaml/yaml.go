@@ -4,9 +4,24 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+// Concurrency modes that determine what happens to a build
+// when another build in the same concurrency group is running.
+const (
+	ConcurrencyWait   = "wait"
+	ConcurrencyCancel = "cancel"
+)
+
 type Config struct {
-	Debug    bool     `yaml:"debug"`
-	Branches []string `yaml:"branches"`
+	Debug       bool        `yaml:"debug"`
+	Branches    []string    `yaml:"branches"`
+	Concurrency Concurrency `yaml:"concurrency"`
+}
+
+// Concurrency defines a named group of builds that must not run
+// at the same time, such as deployments to the same environment.
+type Concurrency struct {
+	Group string `yaml:"group"`
+	Mode  string `yaml:"mode"`
 }
 
 func Parse(raw string) (*Config, error) {